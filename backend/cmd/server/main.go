@@ -1,74 +1,306 @@
 package main
 
 import (
+	"context"
 	"database/sql"
-	"fmt"
+	"errors"
+	"flag"
 	"log"
+	"log/slog"
+	"os"
+	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
-	"github.com/joho/godotenv"
 	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/api/handlers"
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/api/middlewares"
 	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/api/routes"
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/cache"
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/config"
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/data"
 	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/db"
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/health"
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/jobs"
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/logging"
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/metrics"
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/notifications"
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/outbox"
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/ratelimit"
 	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/services"
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/tracing"
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/utils"
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/webhooks"
+)
+
+const (
+	tracingFlushTimeout = 5 * time.Second
+	redisPingTimeout    = 5 * time.Second
 )
 
 type Config struct {
 	DB *sql.DB
+	// Read replica of DB, nil when none is configured.
+	Replica  *sql.DB
+	Settings *config.Config
 }
 
 func (app *Config) Start() {
-	fmt.Println("We have started the server successfully.")
+	slog.Info("Starting the server", "port", app.Settings.Server.Port)
+}
+
+// Log the error the server can not start with and exit.
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
 }
 
 func main() {
-	err := godotenv.Load("../../../.env")
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		os.Exit(runMigrate(os.Args[2:]))
+	}
+
+	settings, err := config.Load(os.Args[1:])
+
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
 
 	if err != nil {
-		log.Panicf("Error loading .env file: %s", err)
+		log.Fatal(err)
+	}
+
+	// Logging everything through the one structured logger.
+	_, err = logging.Setup(settings.LoggingSettings())
+
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Tracing the requests and jobs down to the SQL statements they run.
+	shutdown_tracing, err := tracing.Setup(context.Background(), settings.TracingSettings())
+
+	if err != nil {
+		fatal("Error in setting up tracing", err)
+	}
+
+	db_conn, err := db.InitDB(settings.Database.DSN, settings.DBOptions())
+
+	if err != nil {
+		fatal("Error in initialising db", err)
+	}
+
+	app := Config{DB: db_conn, Settings: settings}
+
+	if settings.Database.ReplicaDSN != "" {
+		app.Replica, err = db.InitDB(settings.Database.ReplicaDSN, settings.DBOptions())
+
+		if err != nil {
+			fatal("Error in initialising the replica db", err)
+		}
 	}
 
-	db_conn, err := db.InitDB()
+	// Caching the catalogue reads, falling back to the db when the cache is down.
+	catalogue_cache, err := cache.New(app.Settings.CacheSettings())
 
 	if err != nil {
-		log.Panicf("Error in initialising db: %s", err)
+		fatal("Error in configuring the cache", err)
+	}
+
+	if redis_cache, ok := catalogue_cache.(*cache.Redis); ok {
+		ping_ctx, cancel_ping := context.WithTimeout(context.Background(), redisPingTimeout)
+
+		if err = redis_cache.Ping(ping_ctx); err != nil {
+			slog.Warn("The cache is not reachable, reading the catalogue from the db", "address", app.Settings.Cache.RedisAddr, "error", err)
+		}
+		cancel_ping()
 	}
 
-	err = db.RunMigrations(db_conn)
+	// Counting the login attempts, for all the instances when kept in Redis.
+	rate_limits, err := ratelimit.New(app.Settings.RateLimitSettings())
 
 	if err != nil {
-		log.Panicf("Error in running migrations: %s", err)
+		fatal("Error in configuring the rate limits", err)
 	}
 
-	app := Config{DB: db_conn}
+	if pinger, ok := rate_limits.(interface{ Ping(context.Context) error }); ok {
+		ping_ctx, cancel_ping := context.WithTimeout(context.Background(), redisPingTimeout)
 
-	// Closing the db connection.
-	defer app.DB.Close()
+		if err = pinger.Ping(ping_ctx); err != nil {
+			slog.Warn("The rate limit store is not reachable", "address", app.Settings.RateLimit.RedisAddr, "on_store_error", app.Settings.RateLimit.OnStoreError, "error", err)
+		}
+		cancel_ping()
+	}
 
 	app.Start()
 
-	router := gin.Default()
+	if app.Settings.Logging.Level != "debug" {
+		gin.SetMode(gin.ReleaseMode)
+	}
+
+	router := gin.New()
+
+	// The client ip the logins are limited by is only taken from X-Forwarded-For when the
+	// request comes through a trusted proxy.
+	err = router.SetTrustedProxies(app.Settings.Server.TrustedProxies)
+
+	if err != nil {
+		fatal("Error in setting the trusted proxies", err)
+	}
+
+	// Answering the errors handlers and middlewares report with c.Error, outside every other
+	// middleware so their errors are answered too.
+	router.Use(middlewares.ErrorMiddleware())
 
 	// CORS Middleware
-	config := cors.DefaultConfig()
-	config.AllowOrigins = []string{"http://*"}
-	config.AllowMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}
-	config.AllowHeaders = []string{"Origin", "Content-Type", "Accept", "Authorization"}
-	config.AllowCredentials = true
+	cors_config := cors.DefaultConfig()
+	cors_config.AllowOrigins = []string{"http://*"}
+	cors_config.AllowMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}
+	cors_config.AllowHeaders = []string{"Origin", "Content-Type", "Accept", "Authorization", "If-Match", "If-None-Match", "traceparent", "tracestate", middlewares.RequestIdHeader}
+	cors_config.ExposeHeaders = []string{"ETag", middlewares.RequestIdHeader}
+	cors_config.AllowCredentials = true
 
 	// Allowing router to use the cors middleware.
-	router.Use(cors.New(config))
+	router.Use(cors.New(cors_config))
+
+	// Tagging every request with an id for the logs and the audit trail.
+	router.Use(middlewares.RequestIdMiddleware())
+
+	// Logging the requests served and the handlers that panicked.
+	router.Use(middlewares.LoggingMiddleware())
+	router.Use(middlewares.RecoveryMiddleware())
+
+	// Counting the requests by route and status, and timing them.
+	registry := metrics.New()
+	registry.RegisterDB(db_conn, "library")
+
+	if app.Replica != nil {
+		registry.RegisterDB(app.Replica, "library_replica")
+	}
+	router.Use(middlewares.MetricsMiddleware(registry))
+	router.Use(middlewares.TracingMiddleware())
+
+	// Unknown routes are answered by ErrorMiddleware like any other error.
+	router.NoRoute(func(c *gin.Context) {
+		c.Error(data.NotFound("route_not_found", "No route for %s %s.", c.Request.Method, c.Request.URL.Path))
+	})
 
 	apiRoutes := router.Group("/api")
 
+	notify_config, err := notifications.NewConfig(app.Settings.NotificationSettings())
+
+	if err != nil {
+		fatal("Error in configuring notifications", err)
+	}
+
 	// Initialising the service handler
-	service_handler := services.NewLibraryService(db_conn)
+	service_handler := services.NewLibraryService(data.New(db_conn, app.Replica, app.Settings.Database.Timeouts, app.Settings.TxConfig()), services.Config{
+		Notifications:         notify_config,
+		Tokens:                utils.NewTokenManager(app.Settings.Auth.JWTSecret, app.Settings.Auth.TokenExpiry),
+		FineBlockThreshold:    app.Settings.Library.FineBlockThreshold,
+		HoldPickupDays:        app.Settings.Library.HoldPickupDays,
+		DueSoonReminderWindow: time.Duration(app.Settings.Library.DueSoonReminderHours) * time.Hour,
+		Cache:                 catalogue_cache,
+		CatalogueTTL:          app.Settings.Cache.TTL,
+		RateLimits:            rate_limits,
+		LoginLimits:           app.Settings.LoginLimits(),
+	})
+
+	// Background jobs
+	scheduler := jobs.NewScheduler(db_conn, service_handler)
+
+	err = jobs.RegisterDefaultJobs(scheduler, service_handler, app.Settings.JobSchedules)
+
+	if err != nil {
+		fatal("Error in registering jobs", err)
+	}
+
+	scheduler.Start()
+
+	// Publishing the domain events written to the outbox
+	sinks, err := outbox.NewSinks(app.Settings.SinkConfig())
+
+	if err != nil {
+		fatal("Error in configuring outbox sinks", err)
+	}
+
+	// Partner webhook subscriptions are fed from the outbox as well
+	sinks = append(sinks, webhooks.NewSubscriptionSink(service_handler))
+
+	dispatcher := outbox.NewDispatcher(service_handler, sinks)
+	dispatcher.Start()
+
+	deliverer := webhooks.NewDeliverer(service_handler, app.Settings.Webhooks.MaxAttempts)
+	deliverer.Start()
+
+	// Dependencies the readiness probe checks
+	migration_version, err := db.LatestMigrationVersion()
+
+	if err != nil {
+		fatal("Error in reading the migrations", err)
+	}
+
+	check_migrations := db.CheckMigrations(db_conn, migration_version)
+
+	// The schema is migrated with the migrate subcommand, the server does not migrate it and
+	// stays unready until it is at the latest version.
+	migrations_ctx, cancel_migrations := context.WithTimeout(context.Background(), 5*time.Second)
+
+	if _, err = check_migrations(migrations_ctx); err != nil {
+		slog.Warn("The schema is not migrated, run the migrate subcommand", "error", err)
+	}
+	cancel_migrations()
+
+	readiness := health.NewChecker()
+	readiness.Add("database", db.CheckConnection(db_conn))
+
+	if app.Replica != nil {
+		readiness.Add("replica", db.CheckConnection(app.Replica))
+	}
+	readiness.Add("migrations", check_migrations)
+	readiness.Add("jobs", scheduler.CheckHeartbeats)
+
+	generic_handler := handlers.NewGenericHandler(readiness)
+	routes.SetupHealthRoutes(router, generic_handler)
+
+	registry.RegisterLibraryStats(service_handler)
+	routes.SetupMetricsRoutes(router, registry.Handler())
 
 	{
-		routes.SetupGenericRoutes(apiRoutes, handlers.NewGenericHandler())
-		routes.SetupAdminRoutes(apiRoutes, handlers.NewAdminHandler(service_handler))
+		routes.SetupGenericRoutes(apiRoutes, generic_handler)
+		routes.SetupAdminRoutes(apiRoutes, handlers.NewAdminHandler(service_handler), middlewares.AuthMiddleware(service_handler), middlewares.AuditMiddleware(service_handler))
 		routes.SetupAuthRoutes(apiRoutes, handlers.NewAuthHandler(service_handler))
 	}
-	router.Run()
+	err = app.Serve(router)
+
+	// Stopping the workers in order: the jobs first as they queue notifications and events,
+	// then the outbox feeding the webhooks, the webhooks and the notifications being sent.
+	scheduler.Stop()
+	dispatcher.Stop()
+	deliverer.Stop()
+	service_handler.WaitForNotifications()
+
+	// Exporting the spans of the last requests and job runs.
+	tracing_ctx, cancel := context.WithTimeout(context.Background(), tracingFlushTimeout)
+	defer cancel()
+
+	if tracing_err := shutdown_tracing(tracing_ctx); tracing_err != nil {
+		slog.Error("Could not flush the traces", "error", tracing_err)
+	}
+
+	if catalogue_cache != nil {
+		catalogue_cache.Close()
+	}
+	rate_limits.Close()
+
+	// Closing the db connections last, the workers use them until they are stopped.
+	app.DB.Close()
+
+	if app.Replica != nil {
+		app.Replica.Close()
+	}
+
+	if err != nil {
+		fatal("Server stopped", err)
+	}
+	slog.Info("Server stopped")
 }
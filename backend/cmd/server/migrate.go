@@ -0,0 +1,179 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/config"
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/db"
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/logging"
+)
+
+const migrateUsage = `Usage: server migrate [flags] <action>
+
+Actions:
+  up               apply every pending migration
+  down N           roll back the last N migrations
+  goto VERSION     migrate up or down to VERSION
+  force VERSION    set the version without running migrations, after fixing a
+                   migration that failed part way (-1 for none applied)
+  status           show the version applied and the pending migrations`
+
+// The migrate subcommand, returning the exit code.
+func runMigrate(args []string) int {
+	settings, action_args, err := config.LoadDatabase("migrate", migrateUsage, args)
+
+	if errors.Is(err, flag.ErrHelp) {
+		return 0
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	if len(action_args) == 0 {
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 2
+	}
+
+	_, err = logging.Setup(settings.LoggingSettings())
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	db_conn, err := db.InitDB(settings.Database.DSN, settings.DBOptions())
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Could not connect to the database: %s\n", err)
+		return 1
+	}
+
+	migrator, err := db.NewMigrator(db_conn)
+
+	if err != nil {
+		db_conn.Close()
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	defer migrator.Close()
+
+	// On SIGINT or SIGTERM, stop once the migration being applied is done.
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	go func() {
+		<-ctx.Done()
+		migrator.Stop()
+	}()
+
+	err = migrateAction(migrator, action_args[0], action_args[1:])
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "migrate %s: %s\n", action_args[0], err)
+		return 1
+	}
+	return 0
+}
+
+func migrateAction(migrator *db.Migrator, action string, args []string) error {
+	switch action {
+	case "up":
+		err := expectArgs(args, 0)
+
+		if err != nil {
+			return err
+		}
+		return migrator.Up()
+	case "down":
+		steps, err := intArg(args)
+
+		if err != nil {
+			return err
+		}
+		return migrator.Down(steps)
+	case "goto":
+		version, err := intArg(args)
+
+		if err != nil {
+			return err
+		}
+
+		if version < 0 {
+			return fmt.Errorf("the version must be 0 or more, got %d", version)
+		}
+		return migrator.Goto(uint(version))
+	case "force":
+		version, err := intArg(args)
+
+		if err != nil {
+			return err
+		}
+		return migrator.Force(version)
+	case "status":
+		err := expectArgs(args, 0)
+
+		if err != nil {
+			return err
+		}
+		return printMigrationStatus(migrator)
+	default:
+		return fmt.Errorf("unknown action, expected up, down, goto, force or status")
+	}
+}
+
+func printMigrationStatus(migrator *db.Migrator) error {
+	status, err := migrator.Status()
+
+	if err != nil {
+		return err
+	}
+
+	pending := make([]string, 0, len(status.Pending))
+
+	for _, version := range status.Pending {
+		pending = append(pending, strconv.FormatUint(uint64(version), 10))
+	}
+
+	fmt.Printf("version: %d\n", status.Version)
+	fmt.Printf("dirty:   %t\n", status.Dirty)
+	fmt.Printf("latest:  %d\n", status.Latest)
+
+	if len(pending) == 0 {
+		fmt.Println("pending: none")
+	} else {
+		fmt.Printf("pending: %s\n", strings.Join(pending, ", "))
+	}
+	return nil
+}
+
+func expectArgs(args []string, count int) error {
+	if len(args) != count {
+		return fmt.Errorf("expected %d arguments, got %d", count, len(args))
+	}
+	return nil
+}
+
+func intArg(args []string) (int, error) {
+	err := expectArgs(args, 1)
+
+	if err != nil {
+		return 0, err
+	}
+
+	value, err := strconv.Atoi(args[0])
+
+	if err != nil {
+		return 0, fmt.Errorf("expected a number, got %q", args[0])
+	}
+	return value, nil
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os/signal"
+	"syscall"
+)
+
+// Serve the handler until SIGINT or SIGTERM, then stop taking new connections and wait for
+// the requests in flight, up to the shutdown timeout.
+func (app *Config) Serve(handler http.Handler) error {
+	settings := app.Settings.Server
+
+	server := &http.Server{
+		Addr:              fmt.Sprintf(":%d", settings.Port),
+		Handler:           handler,
+		ReadTimeout:       settings.ReadTimeout,
+		ReadHeaderTimeout: settings.ReadHeaderTimeout,
+		WriteTimeout:      settings.WriteTimeout,
+		IdleTimeout:       settings.IdleTimeout,
+		MaxHeaderBytes:    settings.MaxHeaderBytes,
+		TLSConfig:         &tls.Config{MinVersion: tls.VersionTLS12},
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	serve_err := make(chan error, 1)
+
+	go func() {
+		if settings.TLS() {
+			slog.Info("Serving https", "address", server.Addr)
+			serve_err <- server.ListenAndServeTLS(settings.TLSCertFile, settings.TLSKeyFile)
+		} else {
+			slog.Info("Serving http", "address", server.Addr)
+			serve_err <- server.ListenAndServe()
+		}
+	}()
+
+	select {
+	case err := <-serve_err:
+		return err
+	case <-ctx.Done():
+	}
+
+	// A second signal kills the process right away.
+	stop()
+	slog.Info("Shutting down, waiting for the requests in flight", "timeout", settings.ShutdownTimeout)
+
+	shutdown_ctx, cancel := context.WithTimeout(context.Background(), settings.ShutdownTimeout)
+	defer cancel()
+
+	err := server.Shutdown(shutdown_ctx)
+
+	if err != nil {
+		return fmt.Errorf("shutting down the server: %w", err)
+	}
+
+	if err = <-serve_err; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
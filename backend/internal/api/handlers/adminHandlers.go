@@ -1,11 +1,12 @@
 package handlers
 
 import (
-	"encoding/json"
+	"errors"
 	"net/http"
-	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/api/middlewares"
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/data"
 	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/services"
 )
 
@@ -19,117 +20,217 @@ func NewAdminHandler(libService *services.LibraryService) *AdminHandler {
 	}
 }
 
-func (h *AdminHandler) QueryBooks(c *gin.Context) {
+type bookQueryRequestBody struct {
+	BookId     int    `json:"book_id" binding:"omitempty,gt=0"`
+	Title      string `json:"title" binding:"max=256"`
+	Category   string `json:"category" binding:"max=64"`
+	Publisher  string `json:"publisher" binding:"max=64"`
+	AuthorName string `json:"author_name" binding:"max=64"`
+}
 
-	var input_json map[string]any
-	dec := json.NewDecoder(c.Request.Body)
-	err := dec.Decode(&input_json)
+func (h *AdminHandler) QueryBooks(c *gin.Context) {
+	var request_body bookQueryRequestBody
 
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	// Without a body every book is listed.
+	if c.Request.ContentLength != 0 && !bindJSON(c, &request_body) {
 		return
 	}
 
-	if book_id, ok := input_json["book_id"]; ok {
-		book, err := h.libraryService.GetBook(int(book_id.(float64)))
+	if request_body.BookId != 0 {
+		book, err := h.libraryService.GetBook(c.Request.Context(), request_body.BookId)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			c.Error(err)
 			return
 		}
 		book_list := []any{book}
-		c.JSON(http.StatusOK, book_list)
+		respondCatalogue(c, book_list, book.Version)
 		return
 	} else {
-		book_list, err := h.libraryService.GetBooks(input_json)
+		filter := data.BookFilter{
+			Title:      request_body.Title,
+			Category:   request_body.Category,
+			Publisher:  request_body.Publisher,
+			AuthorName: request_body.AuthorName,
+		}
+		book_list, err := h.libraryService.GetBooks(c.Request.Context(), filter)
 
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			c.Error(err)
 			return
 		}
-		c.JSON(http.StatusOK, book_list)
+		respondCatalogue(c, book_list, 0)
 		return
 	}
 
 }
 
-type authorRequestBody struct {
-	ID    int    `json:"id,omitempty"`
-	Name  string `json:"name,omitempty"`
-	About string `json:"about,omitempty"`
+type authorInputRequestBody struct {
+	Name  string `json:"name" binding:"required,max=64"`
+	About string `json:"about" binding:"required"`
 }
 
 type bookRequestBody struct {
-	Title      string  `json:"title"`
-	Category   string  `json:"category"`
-	Publisher  string  `json:"publisher"`
-	BookCount  int     `json:"book_count"`
-	Price      float32 `json:"price"`
-	FinePerDay float32 `json:"fine_per_day"`
-	AuthorId   int     `json:"author_id"`
+	Title      string  `json:"title" binding:"required,max=256"`
+	Category   string  `json:"category" binding:"required,max=64"`
+	Publisher  string  `json:"publisher" binding:"required,max=64"`
+	BookCount  int     `json:"book_count" binding:"gte=0"`
+	Price      float32 `json:"price" binding:"gte=0"`
+	FinePerDay float32 `json:"fine_per_day" binding:"gte=0"`
+	AuthorId   int     `json:"author_id" binding:"required,gt=0"`
+}
+
+// Only the fields present in the body are updated.
+type bookUpdateRequestBody struct {
+	Title      *string  `json:"title" binding:"omitempty,min=1,max=256"`
+	Category   *string  `json:"category" binding:"omitempty,min=1,max=64"`
+	Publisher  *string  `json:"publisher" binding:"omitempty,min=1,max=64"`
+	BookCount  *int     `json:"book_count" binding:"omitempty,gte=0"`
+	Price      *float32 `json:"price" binding:"omitempty,gte=0"`
+	FinePerDay *float32 `json:"fine_per_day" binding:"omitempty,gte=0"`
+	AuthorId   *int     `json:"author_id" binding:"omitempty,gt=0"`
+}
+
+func (r bookUpdateRequestBody) toUpdate() data.BookUpdate {
+	return data.BookUpdate{
+		Title:      r.Title,
+		Category:   r.Category,
+		Publisher:  r.Publisher,
+		BookCount:  r.BookCount,
+		Price:      r.Price,
+		FinePerDay: r.FinePerDay,
+		AuthorId:   r.AuthorId,
+	}
+}
+
+type lendRequestBody struct {
+	UserId  int   `json:"user_id" binding:"required,gt=0"`
+	BookIds []int `json:"book_ids" binding:"required,min=1,dive,gt=0"`
 }
 
 func (h *AdminHandler) InsertAuthor(c *gin.Context) {
-	var request_body authorRequestBody
+	var request_body authorInputRequestBody
+
+	if !bindJSON(c, &request_body) {
+		return
+	}
 
-	err := c.BindJSON(&request_body)
+	author, err := h.libraryService.InsertAuthor(c.Request.Context(), request_body.Name, request_body.About)
 
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"message": "", "error": err.Error()})
+		c.Error(err)
 		return
 	}
 
-	if request_body.Name == "" || request_body.About == "" {
-		c.JSON(http.StatusInternalServerError, gin.H{"message": "", "error": "name and about is mandatory to insert the author."})
+	middlewares.AuditChange(c, "author", author.ID, nil, author)
+	setETag(c, author.Version)
+	c.JSON(http.StatusOK, author)
+}
+
+func (h *AdminHandler) UpdateAuthor(c *gin.Context) {
+	author_id, err := pathId(c, "author_id")
+
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	version, ok := ifMatchVersion(c)
+
+	if !ok {
 		return
 	}
 
-	author, err := h.libraryService.InsertAuthor(request_body.Name, request_body.About)
+	var request_body authorInputRequestBody
+
+	if !bindJSON(c, &request_body) {
+		return
+	}
+
+	// State before the update, for the audit log.
+	previous_author, err := h.libraryService.GetAuthorForUpdate(c.Request.Context(), author_id)
 
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
+
+	author, err := h.libraryService.UpdateAuthor(c.Request.Context(), author_id, version, request_body.Name, request_body.About)
+
+	if errors.Is(err, data.ErrEditConflict) {
+		current_author, get_err := h.libraryService.GetAuthorForUpdate(c.Request.Context(), author_id)
+
+		if get_err != nil {
+			c.Error(get_err)
+			return
+		}
+		respondEditConflict(c, err, current_author, current_author.Version)
+		return
+	}
+
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	middlewares.AuditChange(c, "author", author_id, previous_author, author)
+	setETag(c, author.Version)
 	c.JSON(http.StatusOK, author)
 }
 
+// The author with the id of the path or of the id query parameter, otherwise the authors
+// matching the name query parameter.
 func (h *AdminHandler) GetAuthor(c *gin.Context) {
-	var request_body authorRequestBody
-
-	err := c.BindJSON(&request_body)
+	author_id, err := authorId(c)
 
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"message": "", "error": err.Error()})
+		c.Error(err)
 		return
 	}
 
-	if request_body.ID != 0 {
-		authors, err := h.libraryService.GetAuthor(request_body.ID, "")
+	if author_id != 0 {
+		authors, err := h.libraryService.GetAuthor(c.Request.Context(), author_id, "")
+
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"message": "", "error": err.Error()})
+			c.Error(err)
 			return
 		}
-		c.JSON(http.StatusOK, authors)
+		respondCatalogue(c, authors, authors[0].Version)
 		return
-	} else {
-		authors, err := h.libraryService.GetAuthor(0, request_body.Name)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"message": "", "error": err.Error()})
-			return
-		}
-		c.JSON(http.StatusOK, authors)
+	}
+
+	name := c.Query("name")
+
+	if len(name) > 64 {
+		c.Error(data.Invalid("invalid_query_parameter", "name must be at most 64 characters long."))
+		return
+	}
+
+	authors, err := h.libraryService.GetAuthor(c.Request.Context(), 0, name)
+
+	if err != nil {
+		c.Error(err)
 		return
 	}
+	respondCatalogue(c, authors, 0)
+}
 
+// Id of the author from the path or the id query parameter, 0 when there is neither.
+func authorId(c *gin.Context) (int, error) {
+	if c.Param("author_id") != "" {
+		return pathId(c, "author_id")
+	}
+	return queryId(c, "id")
 }
 
 func (h *AdminHandler) InsertBook(c *gin.Context) {
 	var request_body bookRequestBody
-	err := c.BindJSON(&request_body)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"message": "", "error": err.Error()})
+
+	if !bindJSON(c, &request_body) {
 		return
 	}
+
 	book, err := h.libraryService.InsertBook(
+		c.Request.Context(),
 		request_body.Title,
 		request_body.Category,
 		request_body.Publisher,
@@ -140,62 +241,143 @@ func (h *AdminHandler) InsertBook(c *gin.Context) {
 	)
 
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"message": "", "error": err.Error()})
+		c.Error(err)
 		return
 	}
 
+	middlewares.AuditChange(c, "book", book.ID, nil, book)
+	setETag(c, book.Version)
 	c.JSON(http.StatusCreated, book)
 	return
 }
 
 func (h *AdminHandler) UpdateBook(c *gin.Context) {
-	id := c.Param("book_id")
-	book_id, err := strconv.Atoi(id)
+	book_id, err := pathId(c, "book_id")
 
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(err)
+		return
+	}
+	version, ok := ifMatchVersion(c)
+
+	if !ok {
+		return
+	}
+
+	var request_body bookUpdateRequestBody
+
+	if !bindJSON(c, &request_body) {
 		return
 	}
-	var input_json map[string]any
-	dec := json.NewDecoder(c.Request.Body)
-	err = dec.Decode(&input_json)
+
+	// State before the update, for the audit log.
+	previous_book, err := h.libraryService.GetBookForUpdate(c.Request.Context(), book_id)
 
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
 	book, err := h.libraryService.UpdateBook(
+		c.Request.Context(),
 		book_id,
-		input_json,
+		version,
+		request_body.toUpdate(),
 	)
 
+	if errors.Is(err, data.ErrEditConflict) {
+		current_book, get_err := h.libraryService.GetBookForUpdate(c.Request.Context(), book_id)
+
+		if get_err != nil {
+			c.Error(get_err)
+			return
+		}
+		respondEditConflict(c, err, current_book, current_book.Version)
+		return
+	}
+
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"message": "", "error": err.Error()})
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusCreated, book)
-	return
+	middlewares.AuditChange(c, "book", book_id, previous_book, book)
+	setETag(c, book.Version)
+	c.JSON(http.StatusOK, book)
 }
 
 func (h *AdminHandler) LendBook(c *gin.Context) {
-	var input_json map[string]any
-	dec := json.NewDecoder(c.Request.Body)
-	err := dec.Decode(&input_json)
+	var request_body lendRequestBody
 
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !bindJSON(c, &request_body) {
 		return
 	}
 
-	book_list, err := h.libraryService.LendBooks(input_json)
+	book_list, err := h.libraryService.LendBooks(c.Request.Context(), request_body.UserId, request_body.BookIds)
 
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
+	middlewares.AuditChange(c, "book_borrow_list", book_list.ID, nil, book_list)
+
 	c.JSON(http.StatusCreated, book_list)
 	return
 }
+
+type borrowRequestBody struct {
+	BorrowId int `json:"borrow_id" binding:"required,gt=0"`
+}
+
+func (h *AdminHandler) ReturnBook(c *gin.Context) {
+	var request_body borrowRequestBody
+
+	if !bindJSON(c, &request_body) {
+		return
+	}
+
+	previous_item, err := h.libraryService.GetLoan(c.Request.Context(), request_body.BorrowId)
+
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	item, err := h.libraryService.ReturnBook(c.Request.Context(), request_body.BorrowId, c.GetInt("user_id"))
+
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	middlewares.AuditChange(c, "book_borrow", item.ID, previous_item, item)
+
+	c.JSON(http.StatusOK, item)
+}
+
+func (h *AdminHandler) RenewBook(c *gin.Context) {
+	var request_body borrowRequestBody
+
+	if !bindJSON(c, &request_body) {
+		return
+	}
+
+	previous_item, err := h.libraryService.GetLoan(c.Request.Context(), request_body.BorrowId)
+
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	item, err := h.libraryService.RenewBook(c.Request.Context(), request_body.BorrowId)
+
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	middlewares.AuditChange(c, "book_borrow", item.ID, previous_item, item)
+
+	c.JSON(http.StatusOK, item)
+}
@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/data"
+)
+
+func TestLoanHandlers(t *testing.T) {
+	type step struct {
+		path       string
+		token      func(f loanFixture) string
+		body       func(f loanFixture, borrow_id int) gin.H
+		wantStatus int
+		wantCode   string
+	}
+
+	admin := func(f loanFixture) string { return f.adminToken }
+	lend := func(f loanFixture, borrow_id int) gin.H {
+		return gin.H{"user_id": f.userId, "book_ids": []int{f.bookId}}
+	}
+	borrowed := func(f loanFixture, borrow_id int) gin.H { return gin.H{"borrow_id": borrow_id} }
+
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{"lends, renews and returns a book", []step{
+			{"/api/admin/lend-book", admin, lend, http.StatusCreated, ""},
+			{"/api/admin/renew-book", admin, borrowed, http.StatusOK, ""},
+			{"/api/admin/return-book", admin, borrowed, http.StatusOK, ""},
+		}},
+		{"rejects lending the last copy twice", []step{
+			{"/api/admin/lend-book", admin, lend, http.StatusCreated, ""},
+			{"/api/admin/lend-book", admin, lend, http.StatusConflict, "book_unavailable"},
+		}},
+		{"rejects renewing past the limit", []step{
+			{"/api/admin/lend-book", admin, lend, http.StatusCreated, ""},
+			{"/api/admin/renew-book", admin, borrowed, http.StatusOK, ""},
+			{"/api/admin/renew-book", admin, borrowed, http.StatusConflict, "renewal_limit_reached"},
+		}},
+		{"rejects returning twice", []step{
+			{"/api/admin/lend-book", admin, lend, http.StatusCreated, ""},
+			{"/api/admin/return-book", admin, borrowed, http.StatusOK, ""},
+			{"/api/admin/return-book", admin, borrowed, http.StatusConflict, "loan_already_returned"},
+		}},
+		{"rejects an unknown loan", []step{
+			{"/api/admin/return-book", admin, func(f loanFixture, borrow_id int) gin.H { return gin.H{"borrow_id": 1000} }, http.StatusNotFound, "loan_not_found"},
+		}},
+		{"rejects a request without books", []step{
+			{"/api/admin/lend-book", admin, func(f loanFixture, borrow_id int) gin.H { return gin.H{"user_id": f.userId} }, http.StatusBadRequest, "validation_failed"},
+		}},
+		{"rejects a request without a token", []step{
+			{"/api/admin/lend-book", func(f loanFixture) string { return "" }, lend, http.StatusUnauthorized, "missing_authorization"},
+		}},
+		{"rejects a member", []step{
+			{"/api/admin/lend-book", func(f loanFixture) string { return f.memberToken }, lend, http.StatusForbidden, "admin_required"},
+		}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := newTestServer(t)
+			fixture := newLoanFixture(t, server)
+			borrow_id := 0
+
+			for i, step := range test.steps {
+				var response_body struct {
+					errorBody
+					BookList []*data.BookBorrorw `json:"lended_books"`
+				}
+
+				status := server.do(t, http.MethodPost, step.path, step.token(fixture), step.body(fixture, borrow_id), &response_body)
+
+				if status != step.wantStatus || response_body.Code != step.wantCode {
+					t.Fatalf("step %d %s = %d %+v, want %d with code %q", i, step.path, status, response_body.errorBody, step.wantStatus, step.wantCode)
+				}
+
+				if len(response_body.BookList) > 0 {
+					borrow_id = response_body.BookList[0].ID
+				}
+			}
+		})
+	}
+}
+
+func TestLoanHandlersAudit(t *testing.T) {
+	ctx := context.Background()
+	server := newTestServer(t)
+	fixture := newLoanFixture(t, server)
+
+	var borrow_list data.BookBorrowList
+
+	status := server.do(t, http.MethodPost, "/api/admin/lend-book", fixture.adminToken, gin.H{"user_id": fixture.userId, "book_ids": []int{fixture.bookId}}, &borrow_list)
+
+	if status != http.StatusCreated {
+		t.Fatalf("lend = %d, want %d", status, http.StatusCreated)
+	}
+
+	status = server.do(t, http.MethodPost, "/api/admin/return-book", fixture.adminToken, gin.H{"borrow_id": borrow_list.BookList[0].ID}, nil)
+
+	if status != http.StatusOK {
+		t.Fatalf("return = %d, want %d", status, http.StatusOK)
+	}
+
+	entries, err := server.model.AuditEntry.QueryEntries(ctx, data.AuditFilter{Limit: 10})
+
+	if err != nil {
+		t.Fatalf("could not query the audit log: %v", err)
+	}
+
+	tests := []struct {
+		action     string
+		entityType string
+		entityId   int
+		wantBefore bool
+	}{
+		{"POST /api/admin/return-book", "book_borrow", borrow_list.BookList[0].ID, true},
+		{"POST /api/admin/lend-book", "book_borrow_list", borrow_list.ID, false},
+	}
+
+	if len(entries) != len(tests) {
+		t.Fatalf("audit log has %d entries, want %d", len(entries), len(tests))
+	}
+
+	for i, test := range tests {
+		entry := entries[i]
+
+		if entry.Action != test.action || entry.EntityType != test.entityType || entry.EntityId != strconv.Itoa(test.entityId) {
+			t.Errorf("entry %d = %s %s %s, want %s %s %d", i, entry.Action, entry.EntityType, entry.EntityId, test.action, test.entityType, test.entityId)
+		}
+
+		if (entry.Before != nil) != test.wantBefore || entry.After == nil {
+			t.Errorf("entry %d before = %s after = %s, want a before state %v", i, entry.Before, entry.After, test.wantBefore)
+		}
+	}
+}
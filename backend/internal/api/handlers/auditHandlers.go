@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/data"
+)
+
+const (
+	defaultAuditLimit  = 100
+	maxAuditLimit      = 1000
+	defaultExportLimit = 10000
+	maxExportLimit     = 100000
+)
+
+// Accepts either a timestamp or a plain date, taken as midnight server time. The timestamps are
+// stored in server time, so the result is converted to it.
+func parseTimestamp(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	for _, layout := range []string{time.RFC3339, time.DateOnly} {
+		parsed, err := time.ParseInLocation(layout, value, time.Local)
+
+		if err == nil {
+			parsed = parsed.Local()
+			return &parsed, nil
+		}
+	}
+	return nil, data.Invalid("invalid_query_parameter", "%s is not a RFC 3339 timestamp or a YYYY-MM-DD date.", value)
+}
+
+func parsePositiveQuery(c *gin.Context, name string, default_value, max_value int) (int, error) {
+	value := c.Query(name)
+
+	if value == "" {
+		return default_value, nil
+	}
+
+	parsed, err := strconv.Atoi(value)
+
+	if err != nil || parsed < 0 {
+		return 0, data.Invalid("invalid_query_parameter", "%s must be a positive number.", name)
+	}
+
+	if max_value > 0 && parsed > max_value {
+		return max_value, nil
+	}
+	return parsed, nil
+}
+
+func auditFilterFromQuery(c *gin.Context, default_limit, max_limit int) (data.AuditFilter, error) {
+	filter := data.AuditFilter{
+		EntityType: c.Query("entity_type"),
+		EntityId:   c.Query("entity_id"),
+		Action:     c.Query("action"),
+		RequestId:  c.Query("request_id"),
+	}
+
+	var err error
+
+	if filter.ActorId, err = parsePositiveQuery(c, "actor_id", 0, 0); err != nil {
+		return filter, err
+	}
+
+	if filter.From, err = parseTimestamp(c.Query("from")); err != nil {
+		return filter, err
+	}
+
+	if filter.To, err = parseTimestamp(c.Query("to")); err != nil {
+		return filter, err
+	}
+
+	if filter.Limit, err = parsePositiveQuery(c, "limit", default_limit, max_limit); err != nil {
+		return filter, err
+	}
+
+	if filter.Offset, err = parsePositiveQuery(c, "offset", 0, 0); err != nil {
+		return filter, err
+	}
+	return filter, nil
+}
+
+func (h *AdminHandler) GetAuditLog(c *gin.Context) {
+	filter, err := auditFilterFromQuery(c, defaultAuditLimit, maxAuditLimit)
+
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	entries, err := h.libraryService.GetAuditLog(c.Request.Context(), filter)
+
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, entries)
+}
+
+func (h *AdminHandler) ExportAuditLog(c *gin.Context) {
+	filter, err := auditFilterFromQuery(c, defaultExportLimit, maxExportLimit)
+
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	entries, err := h.libraryService.GetAuditLog(c.Request.Context(), filter)
+
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	file_name := fmt.Sprintf("audit-log-%s.csv", time.Now().Format("20060102-150405"))
+
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", file_name))
+	c.Status(http.StatusOK)
+
+	writer := csv.NewWriter(c.Writer)
+	writer.Write([]string{"id", "created_at", "actor_id", "actor_email", "action", "path", "entity_type", "entity_id",
+		"status_code", "ip_address", "request_id", "before", "after", "diff"})
+
+	for _, entry := range entries {
+		actor_id := ""
+
+		if entry.ActorId != nil {
+			actor_id = strconv.Itoa(*entry.ActorId)
+		}
+
+		writer.Write([]string{
+			strconv.FormatInt(entry.ID, 10),
+			entry.CreatedAt.Format(time.RFC3339),
+			actor_id,
+			entry.ActorEmail,
+			entry.Action,
+			entry.Path,
+			entry.EntityType,
+			entry.EntityId,
+			strconv.Itoa(entry.StatusCode),
+			entry.IPAddress,
+			entry.RequestId,
+			string(entry.Before),
+			string(entry.After),
+			string(entry.Diff),
+		})
+	}
+	writer.Flush()
+}
@@ -3,8 +3,10 @@ package handlers
 import (
 	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/data"
 	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/services"
 	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/utils"
 )
@@ -21,45 +23,45 @@ func NewAuthHandler(libraryService *services.LibraryService) *AuthHandler {
 
 func (a *AuthHandler) Register(c *gin.Context) {
 	type requestBody struct {
-		Name        string `json:"name" binding:"required"`
-		Email       string `json:"email" binding:"required"`
+		Name        string `json:"name" binding:"required,max=64"`
+		Email       string `json:"email" binding:"required,email,max=32"`
 		Password    string `json:"password" binding:"required"`
-		PhoneNumber string `json:"phone_number" binding:"required"`
+		PhoneNumber string `json:"phone_number" binding:"required,max=16"`
 	}
 
 	var request_body requestBody
 
-	err := c.BindJSON(&request_body)
-
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"message": "", "error": err.Error()})
+	if !bindJSON(c, &request_body) {
 		return
 	}
 
 	// password validation and hashing
-	err = utils.ValidatePassword(request_body.Password)
+	err := utils.ValidatePassword(request_body.Password)
 
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"message": "", "error": err.Error()})
+		c.Error(err)
 		return
 	}
 
-	registered_user, err := a.libraryService.RegisterUser(
+	_, err = a.libraryService.RegisterUser(
+		c.Request.Context(),
 		request_body.Name,
 		request_body.Email,
 		request_body.Password,
 		request_body.PhoneNumber,
 		false,
 		false,
+		c.ClientIP(),
 	)
 
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"message": "", "error": err.Error()})
+		c.Error(err)
 		return
 	}
 
+	// The same answer whether the email was taken or not.
 	c.JSON(http.StatusOK, gin.H{
-		"message": fmt.Sprintf("%v Successfully registered", registered_user.Email),
+		"message": fmt.Sprintf("%v Successfully registered", request_body.Email),
 	})
 }
 
@@ -71,17 +73,14 @@ func (h *AuthHandler) Login(c *gin.Context) {
 
 	var request_body requestBody
 
-	err := c.BindJSON(&request_body)
-
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"message": "", "error": err.Error()})
+	if !bindJSON(c, &request_body) {
 		return
 	}
 
-	token, err := h.libraryService.LoginUser(request_body.Email, request_body.Password)
+	token, err := h.libraryService.LoginUser(c.Request.Context(), request_body.Email, request_body.Password, c.ClientIP())
 
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"message": "", "token": "", "error": err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -91,3 +90,21 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		"error":   "",
 	})
 }
+
+func (h *AuthHandler) Logout(c *gin.Context) {
+	auth_header_slice := strings.Split(c.GetHeader("Authorization"), " ")
+
+	if len(auth_header_slice) != 2 {
+		c.Error(data.Unauthorized("invalid_authorization", "Invalid Authorization Header."))
+		return
+	}
+
+	err := h.libraryService.LogoutUser(c.Request.Context(), auth_header_slice[1])
+
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out successfully"})
+}
@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRegister(t *testing.T) {
+	tests := []struct {
+		name       string
+		registered bool
+		body       gin.H
+		wantStatus int
+		wantCode   string
+	}{
+		{"registers a new email", false, gin.H{"name": "Member", "email": "member@example.com", "password": testPassword, "phone_number": "555"}, http.StatusOK, ""},
+		{"answers a taken email alike", true, gin.H{"name": "Member", "email": "member@example.com", "password": testPassword, "phone_number": "555"}, http.StatusOK, ""},
+		{"rejects a weak password", false, gin.H{"name": "Member", "email": "member@example.com", "password": "short", "phone_number": "555"}, http.StatusBadRequest, "weak_password"},
+		{"rejects a missing email", false, gin.H{"name": "Member", "password": testPassword, "phone_number": "555"}, http.StatusBadRequest, "validation_failed"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := newTestServer(t)
+
+			if test.registered {
+				server.do(t, http.MethodPost, "/api/auth/register", "", test.body, nil)
+			}
+
+			var response_body struct {
+				errorBody
+			}
+
+			status := server.do(t, http.MethodPost, "/api/auth/register", "", test.body, &response_body)
+
+			if status != test.wantStatus || response_body.Code != test.wantCode {
+				t.Fatalf("register = %d %+v, want %d with code %q", status, response_body, test.wantStatus, test.wantCode)
+			}
+
+			if test.wantStatus == http.StatusOK && response_body.Message != "member@example.com Successfully registered" {
+				t.Errorf("register message = %q, want the same answer for new and taken emails", response_body.Message)
+			}
+		})
+	}
+}
+
+func TestLogin(t *testing.T) {
+	tests := []struct {
+		name       string
+		failures   int
+		password   string
+		wantStatus int
+		wantCode   string
+	}{
+		{"logs in", 0, testPassword, http.StatusOK, ""},
+		{"rejects a wrong password", 0, "wrong password", http.StatusUnauthorized, "invalid_credentials"},
+		{"locks the account after failed logins", 3, testPassword, http.StatusTooManyRequests, "account_locked"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := newTestServer(t)
+
+			server.do(t, http.MethodPost, "/api/auth/register", "", gin.H{"name": "Member", "email": "member@example.com", "password": testPassword, "phone_number": "555"}, nil)
+
+			for range test.failures {
+				server.do(t, http.MethodPost, "/api/auth/login", "", gin.H{"email": "member@example.com", "password": "wrong password"}, nil)
+			}
+
+			var response_body struct {
+				errorBody
+				Token string `json:"token"`
+			}
+
+			status := server.do(t, http.MethodPost, "/api/auth/login", "", gin.H{"email": "member@example.com", "password": test.password}, &response_body)
+
+			if status != test.wantStatus || response_body.Code != test.wantCode {
+				t.Fatalf("login = %d %+v, want %d with code %q", status, response_body, test.wantStatus, test.wantCode)
+			}
+
+			if test.wantStatus == http.StatusOK && response_body.Token == "" {
+				t.Error("login answered without a token")
+			}
+		})
+	}
+}
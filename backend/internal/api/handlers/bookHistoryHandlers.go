@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *AdminHandler) GetBookRevisions(c *gin.Context) {
+	book_id, err := pathId(c, "book_id")
+
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	revisions, err := h.libraryService.GetBookRevisions(c.Request.Context(), book_id)
+
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, revisions)
+}
+
+func (h *AdminHandler) GetBookRevision(c *gin.Context) {
+	book_id, err := pathId(c, "book_id")
+
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	revision, err := pathId(c, "revision")
+
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	book_revision, err := h.libraryService.GetBookRevision(c.Request.Context(), book_id, revision)
+
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, book_revision)
+}
+
+// Book as it was at ?at=<RFC 3339 timestamp or YYYY-MM-DD date>, now when at is not given.
+func (h *AdminHandler) GetBookAsOf(c *gin.Context) {
+	book_id, err := pathId(c, "book_id")
+
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	at, err := parseTimestamp(c.Query("at"))
+
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if at == nil {
+		now := time.Now()
+		at = &now
+	}
+
+	book_revision, err := h.libraryService.GetBookAsOf(c.Request.Context(), book_id, *at)
+
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, book_revision)
+}
@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/api/middlewares"
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/data"
+)
+
+func (h *AdminHandler) GetLibraryHours(c *gin.Context) {
+	hours, err := h.libraryService.GetLibraryHours(c.Request.Context())
+
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, hours)
+}
+
+func (h *AdminHandler) UpdateLibraryHours(c *gin.Context) {
+	weekday, err := strconv.Atoi(c.Param("weekday"))
+
+	if err != nil {
+		c.Error(data.Invalid("invalid_path_parameter", "weekday must be a number between 0 (Sunday) and 6 (Saturday)."))
+		return
+	}
+
+	type requestBody struct {
+		OpensAt  *string `json:"opens_at"`
+		ClosesAt *string `json:"closes_at"`
+		IsClosed bool    `json:"is_closed"`
+	}
+
+	var request_body requestBody
+
+	if !bindJSON(c, &request_body) {
+		return
+	}
+
+	previous_day, err := h.libraryService.GetLibraryDay(c.Request.Context(), weekday)
+
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	day, err := h.libraryService.UpdateLibraryHours(c.Request.Context(), data.LibraryHours{
+		Weekday:  weekday,
+		OpensAt:  request_body.OpensAt,
+		ClosesAt: request_body.ClosesAt,
+		IsClosed: request_body.IsClosed,
+	})
+
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	middlewares.AuditChange(c, "library_hours", weekday, previous_day, day)
+
+	c.JSON(http.StatusOK, day)
+}
+
+func (h *AdminHandler) GetLibraryClosures(c *gin.Context) {
+	closures, err := h.libraryService.GetLibraryClosures(c.Request.Context())
+
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, closures)
+}
+
+func (h *AdminHandler) InsertLibraryClosure(c *gin.Context) {
+	type requestBody struct {
+		Date        string `json:"date" binding:"required"`
+		Recurring   bool   `json:"recurring"`
+		Description string `json:"description"`
+	}
+
+	var request_body requestBody
+
+	if !bindJSON(c, &request_body) {
+		return
+	}
+
+	closure, err := h.libraryService.InsertLibraryClosure(c.Request.Context(), request_body.Date, request_body.Recurring, request_body.Description)
+
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	middlewares.AuditChange(c, "library_closure", closure.ID, nil, closure)
+
+	c.JSON(http.StatusCreated, closure)
+}
+
+func (h *AdminHandler) DeleteLibraryClosure(c *gin.Context) {
+	closure_id, err := pathId(c, "closure_id")
+
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	previous_closure, err := h.libraryService.GetLibraryClosure(c.Request.Context(), closure_id)
+
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	err = h.libraryService.DeleteLibraryClosure(c.Request.Context(), closure_id)
+
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	middlewares.AuditChange(c, "library_closure", closure_id, previous_closure, nil)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Closure deleted successfully"})
+}
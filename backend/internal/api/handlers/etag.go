@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/api/middlewares"
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/data"
+)
+
+// The ETag of a record is its version.
+func setETag(c *gin.Context, version int) {
+	c.Header("ETag", strconv.Quote(strconv.Itoa(version)))
+}
+
+// Clients may keep the catalogue responses but have to revalidate them before each use.
+const catalogueCacheControl = "private, no-cache"
+
+// Respond 200 with the catalogue records, or 304 when If-None-Match has the ETag already.
+// The ETag is a digest of the body, after the version of the record when it is one, as the
+// stock of a book changes without a new version.
+func respondCatalogue(c *gin.Context, body any, version int) {
+	encoded, err := json.Marshal(body)
+
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	digest := sha256.Sum256(encoded)
+	etag := hex.EncodeToString(digest[:12])
+
+	if version > 0 {
+		etag = strconv.Itoa(version) + "-" + etag
+	}
+	etag = strconv.Quote(etag)
+
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", catalogueCacheControl)
+
+	if ifNoneMatch(c.GetHeader("If-None-Match"), etag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.Data(http.StatusOK, "application/json; charset=utf-8", encoded)
+}
+
+// Whether the If-None-Match header matches the ETag, comparing them weakly.
+func ifNoneMatch(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// Read the version the client last saw from If-Match. The header is required on updates,
+// responding 428 when it is missing and 400 when it is not a version this api handed out.
+func ifMatchVersion(c *gin.Context) (int, bool) {
+	if_match := strings.TrimSpace(c.GetHeader("If-Match"))
+
+	if if_match == "" {
+		c.Error(data.PreconditionRequired("if_match_required", "If-Match header with the ETag of the record is required."))
+		return 0, false
+	}
+
+	// The catalogue ETags carry a digest after the version.
+	tag, _, _ := strings.Cut(strings.Trim(strings.TrimPrefix(if_match, "W/"), `"`), "-")
+	version, err := strconv.Atoi(tag)
+
+	if err != nil || version <= 0 {
+		c.Error(data.Invalid("invalid_if_match", "If-Match header must be an ETag returned by the api."))
+		return 0, false
+	}
+	return version, true
+}
+
+// Respond to a lost update with the current state of the record so the client can merge.
+func respondEditConflict(c *gin.Context, err error, current any, version int) {
+	status, body := middlewares.ErrorResponse(err)
+	body["current"] = current
+
+	setETag(c, version)
+	c.JSON(status, body)
+}
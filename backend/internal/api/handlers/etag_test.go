@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestUpdateIfMatch(t *testing.T) {
+	type record struct {
+		path string
+		body gin.H
+	}
+
+	// The author and book of the fixture, both at version 1.
+	records := map[string]func(t *testing.T, server *testServer, f loanFixture) record{
+		"author": func(t *testing.T, server *testServer, f loanFixture) record {
+			author, err := server.service.InsertAuthor(context.Background(), "N. K. Jemisin", "Author")
+
+			if err != nil {
+				t.Fatalf("could not create the author: %v", err)
+			}
+			return record{"/api/admin/update-author/" + strconv.Itoa(author.ID), gin.H{"name": "Nora K. Jemisin", "about": "Author"}}
+		},
+		"book": func(t *testing.T, server *testServer, f loanFixture) record {
+			return record{"/api/admin/update-book/" + strconv.Itoa(f.bookId), gin.H{"title": "The Dispossessed: An Ambiguous Utopia"}}
+		},
+	}
+
+	tests := []struct {
+		name string
+		// If-Match headers of the updates made one after the other, the last one is checked.
+		ifMatch    []string
+		wantStatus int
+		wantCode   string
+		wantETag   string
+	}{
+		{"updates the version read", []string{`"1"`}, http.StatusOK, "", `"2"`},
+		{"accepts a weak ETag", []string{`W/"1"`}, http.StatusOK, "", `"2"`},
+		{"accepts a catalogue ETag", []string{`"1-0a1b2c3d4e5f"`}, http.StatusOK, "", `"2"`},
+		{"requires If-Match", []string{""}, http.StatusPreconditionRequired, "if_match_required", ""},
+		{"rejects an ETag the api did not hand out", []string{`"latest"`}, http.StatusBadRequest, "invalid_if_match", ""},
+		{"rejects a version that is not positive", []string{`"0"`}, http.StatusBadRequest, "invalid_if_match", ""},
+		{"answers a lost update with the current record", []string{`"1"`, `"1"`}, http.StatusConflict, "edit_conflict", `"2"`},
+	}
+
+	for kind, new_record := range records {
+		for _, test := range tests {
+			t.Run(kind+" "+test.name, func(t *testing.T) {
+				server := newTestServer(t)
+				fixture := newLoanFixture(t, server)
+				record := new_record(t, server, fixture)
+
+				var status int
+				var body []byte
+				var etag string
+
+				for _, if_match := range test.ifMatch {
+					header := http.Header{}
+
+					if if_match != "" {
+						header.Set("If-Match", if_match)
+					}
+
+					response := server.serve(t, http.MethodPut, record.path, fixture.adminToken, header, record.body)
+					status, body, etag = response.Code, response.Body.Bytes(), response.Header().Get("ETag")
+				}
+
+				var response_body struct {
+					errorBody
+					Version int `json:"version"`
+					Current *struct {
+						Version int `json:"version"`
+					} `json:"current"`
+				}
+
+				err := json.Unmarshal(body, &response_body)
+
+				if err != nil {
+					t.Fatalf("could not decode the response %q: %v", body, err)
+				}
+
+				if status != test.wantStatus || response_body.Code != test.wantCode {
+					t.Fatalf("update = %d %s, want %d with code %q", status, body, test.wantStatus, test.wantCode)
+				}
+
+				if etag != test.wantETag {
+					t.Errorf("update ETag = %q, want %q", etag, test.wantETag)
+				}
+
+				switch test.wantStatus {
+				case http.StatusOK:
+					if response_body.Version != 2 {
+						t.Errorf("updated version = %d, want 2", response_body.Version)
+					}
+				case http.StatusConflict:
+					if response_body.Current == nil || response_body.Current.Version != 2 {
+						t.Errorf("conflict = %s, want the current record at version 2", body)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestRespondCatalogue(t *testing.T) {
+	tests := []struct {
+		name string
+		// Whether a copy of the book is lent after the first read.
+		lend bool
+		// If-None-Match of the second read, from the ETag of the first.
+		ifNoneMatch func(etag string) string
+		wantStatus  int
+	}{
+		{"answers a matching ETag with 304", false, func(etag string) string { return etag }, http.StatusNotModified},
+		{"compares the ETags weakly", false, func(etag string) string { return "W/" + etag }, http.StatusNotModified},
+		{"matches any of the ETags", false, func(etag string) string { return `"1-0a1b2c3d4e5f", ` + etag }, http.StatusNotModified},
+		{"matches any record for *", false, func(etag string) string { return "*" }, http.StatusNotModified},
+		{"answers another ETag with the book", false, func(etag string) string { return `"1-0a1b2c3d4e5f"` }, http.StatusOK},
+		{"answers without If-None-Match with the book", false, func(etag string) string { return "" }, http.StatusOK},
+		// Lending does not change the version of the book, only its stock.
+		{"answers the ETag of a book lent since with the book", true, func(etag string) string { return etag }, http.StatusOK},
+	}
+
+	// Bodies of the reads of the book by id and of the list of books.
+	reads := map[string]func(f loanFixture) any{
+		"book":  func(f loanFixture) any { return gin.H{"book_id": f.bookId} },
+		"books": func(f loanFixture) any { return nil },
+	}
+
+	for kind, read_body := range reads {
+		for _, test := range tests {
+			t.Run(kind+" "+test.name, func(t *testing.T) {
+				server := newTestServer(t)
+				fixture := newLoanFixture(t, server)
+				request_body := read_body(fixture)
+
+				first := server.serve(t, http.MethodGet, "/api/admin/get-book", fixture.adminToken, nil, request_body)
+				etag := first.Header().Get("ETag")
+
+				if first.Code != http.StatusOK || etag == "" {
+					t.Fatalf("get-book = %d with ETag %q, want %d with an ETag", first.Code, etag, http.StatusOK)
+				}
+
+				if cache_control := first.Header().Get("Cache-Control"); cache_control != catalogueCacheControl {
+					t.Errorf("get-book Cache-Control = %q, want %q", cache_control, catalogueCacheControl)
+				}
+
+				if test.lend {
+					status := server.do(t, http.MethodPost, "/api/admin/lend-book", fixture.adminToken, gin.H{"user_id": fixture.userId, "book_ids": []int{fixture.bookId}}, nil)
+
+					if status != http.StatusCreated {
+						t.Fatalf("lend = %d, want %d", status, http.StatusCreated)
+					}
+				}
+
+				header := http.Header{}
+
+				if if_none_match := test.ifNoneMatch(etag); if_none_match != "" {
+					header.Set("If-None-Match", if_none_match)
+				}
+
+				second := server.serve(t, http.MethodGet, "/api/admin/get-book", fixture.adminToken, header, request_body)
+
+				if second.Code != test.wantStatus {
+					t.Fatalf("get-book with If-None-Match %q = %d, want %d", header.Get("If-None-Match"), second.Code, test.wantStatus)
+				}
+
+				switch test.wantStatus {
+				case http.StatusNotModified:
+					if second.Body.Len() != 0 || second.Header().Get("ETag") != etag {
+						t.Errorf("304 = %q with ETag %q, want no body and the ETag %q", second.Body.String(), second.Header().Get("ETag"), etag)
+					}
+				case http.StatusOK:
+					if (second.Header().Get("ETag") != etag) != test.lend {
+						t.Errorf("ETag = %q after the first %q, want it to change only with the stock", second.Header().Get("ETag"), etag)
+					}
+				}
+			})
+		}
+	}
+}
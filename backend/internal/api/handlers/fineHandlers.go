@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/api/middlewares"
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/data"
+)
+
+type fineRequestBody struct {
+	UserId   int     `json:"user_id" binding:"required,gt=0"`
+	BorrowId *int    `json:"borrow_id"`
+	Amount   float32 `json:"amount" binding:"required,gt=0"`
+	Note     string  `json:"note"`
+}
+
+func (h *AdminHandler) RecordFineEntry(entry_type string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var request_body fineRequestBody
+
+		if !bindJSON(c, &request_body) {
+			return
+		}
+
+		// Admin who is recording the entry at the desk.
+		recorded_by := c.GetInt("user_id")
+
+		var entry *data.FineEntry
+		var err error
+
+		switch entry_type {
+		case data.FineEntryCharge:
+			entry, err = h.libraryService.ChargeFine(c.Request.Context(), request_body.UserId, request_body.BorrowId, request_body.Amount, request_body.Note, &recorded_by)
+		case data.FineEntryPayment:
+			entry, err = h.libraryService.RecordFinePayment(c.Request.Context(), request_body.UserId, request_body.Amount, request_body.Note, recorded_by)
+		case data.FineEntryWaiver:
+			entry, err = h.libraryService.WaiveFine(c.Request.Context(), request_body.UserId, request_body.BorrowId, request_body.Amount, request_body.Note, recorded_by)
+		case data.FineEntryRefund:
+			entry, err = h.libraryService.RefundFine(c.Request.Context(), request_body.UserId, request_body.Amount, request_body.Note, recorded_by)
+		}
+
+		if err != nil {
+			c.Error(err)
+			return
+		}
+
+		middlewares.AuditChange(c, "fine_entry", entry.ID, nil, entry)
+		c.JSON(http.StatusCreated, entry)
+	}
+}
+
+func (h *AdminHandler) GetFineBalance(c *gin.Context) {
+	user_id, err := pathId(c, "user_id")
+
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	balance, err := h.libraryService.GetFineBalance(c.Request.Context(), user_id)
+
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"user_id": user_id, "balance": balance})
+}
+
+func (h *AdminHandler) GetFineStatement(c *gin.Context) {
+	user_id, err := pathId(c, "user_id")
+
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	statement, err := h.libraryService.GetFineStatement(c.Request.Context(), user_id)
+
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, statement)
+}
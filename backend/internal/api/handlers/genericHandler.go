@@ -4,13 +4,15 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/health"
 )
 
 type GenericHandler struct {
+	readiness *health.Checker
 }
 
-func NewGenericHandler() *GenericHandler {
-	return &GenericHandler{}
+func NewGenericHandler(readiness *health.Checker) *GenericHandler {
+	return &GenericHandler{readiness: readiness}
 }
 
 func (h *GenericHandler) Ping(c *gin.Context) {
@@ -18,3 +20,24 @@ func (h *GenericHandler) Ping(c *gin.Context) {
 		"message": "Pong",
 	})
 }
+
+// Liveness probe, the process is up and serving requests. It checks no dependency so an
+// outage of one does not get the process restarted.
+func (h *GenericHandler) Healthz(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": health.StatusUp})
+}
+
+// Readiness probe, answered with 503 while any dependency is down so no traffic is routed
+// to the instance until it recovers.
+func (h *GenericHandler) Readyz(c *gin.Context) {
+	report := h.readiness.Run(c.Request.Context())
+
+	status := http.StatusOK
+
+	if report.Status != health.StatusUp {
+		status = http.StatusServiceUnavailable
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(status, report)
+}
@@ -0,0 +1,196 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/api/middlewares"
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/cache"
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/data"
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/data/memory"
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/ratelimit"
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/services"
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/utils"
+)
+
+const testPassword = "correct horse"
+
+type testServer struct {
+	router  *gin.Engine
+	model   data.Models
+	service *services.LibraryService
+	tokens  *utils.TokenManager
+}
+
+// The auth, catalogue and loan routes on in-memory models, behind the middlewares main puts them.
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	model := memory.NewModels()
+	tokens := utils.NewTokenManager("test secret", time.Hour)
+	service := services.NewLibraryService(model, services.Config{
+		Tokens:       tokens,
+		Cache:        cache.NewLRU(100),
+		CatalogueTTL: time.Hour,
+		RateLimits:   ratelimit.NewMemoryStore(),
+		LoginLimits: services.LoginLimits{
+			RegisterIPLimit:    5,
+			RegisterIPWindow:   time.Hour,
+			IPLimit:            20,
+			IPWindow:           time.Minute,
+			AccountLimit:       10,
+			AccountWindow:      time.Minute,
+			LockoutThreshold:   3,
+			LockoutDuration:    time.Minute,
+			MaxLockoutDuration: time.Hour,
+		},
+	})
+
+	router := gin.New()
+	router.Use(middlewares.ErrorMiddleware())
+
+	auth_handler := NewAuthHandler(service)
+	router.POST("/api/auth/register", auth_handler.Register)
+	router.POST("/api/auth/login", auth_handler.Login)
+	router.POST("/api/auth/logout", auth_handler.Logout)
+
+	admin_handler := NewAdminHandler(service)
+	admin_router := router.Group("/api/admin")
+	admin_router.Use(middlewares.AuthMiddleware(service), middlewares.AuditMiddleware(service))
+	admin_router.POST("/add-author", admin_handler.InsertAuthor)
+	admin_router.GET("/get-author", admin_handler.GetAuthor)
+	admin_router.GET("/get-author/:author_id", admin_handler.GetAuthor)
+	admin_router.PUT("/update-author/:author_id", admin_handler.UpdateAuthor)
+	admin_router.GET("/get-book", admin_handler.QueryBooks)
+	admin_router.POST("/add-book", admin_handler.InsertBook)
+	admin_router.PUT("/update-book/:book_id", admin_handler.UpdateBook)
+	admin_router.POST("/lend-book", admin_handler.LendBook)
+	admin_router.POST("/return-book", admin_handler.ReturnBook)
+	admin_router.POST("/renew-book", admin_handler.RenewBook)
+
+	return &testServer{router: router, model: model, service: service, tokens: tokens}
+}
+
+// Serve the request and decode the JSON response into response_body, when given.
+func (s *testServer) do(t *testing.T, method, path, token string, request_body any, response_body any) int {
+	t.Helper()
+
+	recorder := s.serve(t, method, path, token, nil, request_body)
+
+	if response_body != nil {
+		err := json.Unmarshal(recorder.Body.Bytes(), response_body)
+
+		if err != nil {
+			t.Fatalf("could not decode the response %q: %v", recorder.Body.String(), err)
+		}
+	}
+	return recorder.Code
+}
+
+// Serve the request with the headers given. request_body is sent as JSON, as is when it is
+// []byte, and no body is sent when it is nil.
+func (s *testServer) serve(t *testing.T, method, path, token string, header http.Header, request_body any) *httptest.ResponseRecorder {
+	t.Helper()
+
+	var body io.Reader
+
+	if raw_body, ok := request_body.([]byte); ok {
+		body = bytes.NewReader(raw_body)
+	} else if request_body != nil {
+		encoded_body, err := json.Marshal(request_body)
+
+		if err != nil {
+			t.Fatalf("could not encode the request: %v", err)
+		}
+		body = bytes.NewReader(encoded_body)
+	}
+
+	request := httptest.NewRequest(method, path, body)
+
+	if request_body != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+
+	for name, values := range header {
+		request.Header[name] = values
+	}
+
+	if token != "" {
+		request.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	recorder := httptest.NewRecorder()
+	s.router.ServeHTTP(recorder, request)
+	return recorder
+}
+
+type errorBody struct {
+	Message string `json:"message"`
+	Error   string `json:"error"`
+	Code    string `json:"code"`
+}
+
+type loanFixture struct {
+	adminToken  string
+	memberToken string
+	userId      int
+	bookId      int
+}
+
+// An admin, a member, a book with a copy in stock and a policy lending it for a week.
+func newLoanFixture(t *testing.T, server *testServer) loanFixture {
+	t.Helper()
+	ctx := context.Background()
+
+	admin, err := server.model.User.CreateUser(ctx, data.User{Name: "Admin", Email: "admin@example.com", IsActive: true, IsAdmin: true})
+
+	if err != nil {
+		t.Fatalf("could not create the admin: %v", err)
+	}
+
+	member, err := server.model.User.CreateUser(ctx, data.User{Name: "Member", Email: "member@example.com", IsActive: true})
+
+	if err != nil {
+		t.Fatalf("could not create the member: %v", err)
+	}
+
+	author, err := server.service.InsertAuthor(ctx, "Ursula K. Le Guin", "Author")
+
+	if err != nil {
+		t.Fatalf("could not create the author: %v", err)
+	}
+
+	book, err := server.service.InsertBook(ctx, "The Dispossessed", "fiction", "Harper", 1, 20, 1, author.ID)
+
+	if err != nil {
+		t.Fatalf("could not create the book: %v", err)
+	}
+
+	_, err = server.service.InsertBorrowPolicy(ctx, data.BorrowPolicy{LoanDays: 7, MaxRenewals: 1, MaxLoans: 2})
+
+	if err != nil {
+		t.Fatalf("could not create the policy: %v", err)
+	}
+
+	admin_token, err := server.tokens.CreateToken(strconv.Itoa(admin.ID), admin.Email, true)
+
+	if err != nil {
+		t.Fatalf("could not create the admin token: %v", err)
+	}
+
+	member_token, err := server.tokens.CreateToken(strconv.Itoa(member.ID), member.Email, false)
+
+	if err != nil {
+		t.Fatalf("could not create the member token: %v", err)
+	}
+	return loanFixture{adminToken: admin_token, memberToken: member_token, userId: member.ID, bookId: book.ID}
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/api/middlewares"
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/data"
+)
+
+func (h *AdminHandler) PlaceHold(c *gin.Context) {
+	type requestBody struct {
+		BookId int `json:"book_id" binding:"required,gt=0"`
+		UserId int `json:"user_id" binding:"required,gt=0"`
+	}
+
+	var request_body requestBody
+
+	if !bindJSON(c, &request_body) {
+		return
+	}
+
+	hold, err := h.libraryService.PlaceHold(c.Request.Context(), request_body.BookId, request_body.UserId)
+
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	middlewares.AuditChange(c, "book_hold", hold.ID, nil, hold)
+
+	c.JSON(http.StatusCreated, hold)
+}
+
+func (h *AdminHandler) GetUserHolds(c *gin.Context) {
+	user_id, err := pathId(c, "user_id")
+
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	holds, err := h.libraryService.GetUserHolds(c.Request.Context(), user_id)
+
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, holds)
+}
+
+func (h *AdminHandler) MarkHoldReady(c *gin.Context) {
+	h.updateHold(c, h.libraryService.MarkHoldReady)
+}
+
+func (h *AdminHandler) CancelHold(c *gin.Context) {
+	h.updateHold(c, h.libraryService.CancelHold)
+}
+
+func (h *AdminHandler) updateHold(c *gin.Context, update func(ctx context.Context, hold_id int) (*data.BookHold, error)) {
+	hold_id, err := pathId(c, "hold_id")
+
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	previous_hold, err := h.libraryService.GetHold(c.Request.Context(), hold_id)
+
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	hold, err := update(c.Request.Context(), hold_id)
+
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	middlewares.AuditChange(c, "book_hold", hold_id, previous_hold, hold)
+
+	c.JSON(http.StatusOK, hold)
+}
@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/data"
+)
+
+const defaultJobRunLimit = 50
+
+func (h *AdminHandler) GetJobRuns(c *gin.Context) {
+	limit := defaultJobRunLimit
+
+	if value := c.Query("limit"); value != "" {
+		parsed_limit, err := strconv.Atoi(value)
+
+		if err != nil || parsed_limit <= 0 {
+			c.Error(data.Invalid("invalid_query_parameter", "limit must be a positive number."))
+			return
+		}
+		limit = parsed_limit
+	}
+
+	runs, err := h.libraryService.GetJobRuns(c.Request.Context(), c.Query("job_name"), limit)
+
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, runs)
+}
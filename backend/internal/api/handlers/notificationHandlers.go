@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/api/middlewares"
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/data"
+)
+
+const defaultNotificationLimit = 50
+
+func (h *AdminHandler) ActivateUser(c *gin.Context) {
+	user_id, err := pathId(c, "user_id")
+
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	previous_user, err := h.libraryService.GetUser(c.Request.Context(), user_id)
+
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	err = h.libraryService.ActivateUser(c.Request.Context(), user_id)
+
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	middlewares.AuditChange(c, "user", user_id, gin.H{"is_active": previous_user.IsActive}, gin.H{"is_active": true})
+
+	c.JSON(http.StatusOK, gin.H{"message": "User activated successfully"})
+}
+
+func (h *AdminHandler) GetNotificationPreferences(c *gin.Context) {
+	user_id, err := pathId(c, "user_id")
+
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	preferences, err := h.libraryService.GetNotificationPreferences(c.Request.Context(), user_id)
+
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"channels":    h.libraryService.GetNotificationChannels(),
+		"preferences": preferences,
+	})
+}
+
+func (h *AdminHandler) UpdateNotificationPreference(c *gin.Context) {
+	user_id, err := pathId(c, "user_id")
+
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	type requestBody struct {
+		Channel string `json:"channel" binding:"required"`
+		Enabled *bool  `json:"enabled" binding:"required"`
+	}
+
+	var request_body requestBody
+
+	if !bindJSON(c, &request_body) {
+		return
+	}
+
+	preference, err := h.libraryService.UpdateNotificationPreference(c.Request.Context(), user_id, request_body.Channel, *request_body.Enabled)
+
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	middlewares.AuditChange(c, "notification_preference", user_id, nil, preference)
+
+	c.JSON(http.StatusOK, preference)
+}
+
+func (h *AdminHandler) GetUserNotifications(c *gin.Context) {
+	user_id, err := pathId(c, "user_id")
+
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	limit := defaultNotificationLimit
+
+	if value := c.Query("limit"); value != "" {
+		parsed_limit, err := strconv.Atoi(value)
+
+		if err != nil || parsed_limit <= 0 {
+			c.Error(data.Invalid("invalid_query_parameter", "limit must be a positive number."))
+			return
+		}
+		limit = parsed_limit
+	}
+
+	deliveries, err := h.libraryService.GetUserNotifications(c.Request.Context(), user_id, limit)
+
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, deliveries)
+}
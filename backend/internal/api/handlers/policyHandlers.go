@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/api/middlewares"
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/data"
+)
+
+type borrowPolicyRequestBody struct {
+	MemberType  *string  `json:"member_type"`
+	Category    *string  `json:"category"`
+	LoanDays    int      `json:"loan_days" binding:"required,gt=0"`
+	MaxRenewals int      `json:"max_renewals" binding:"gte=0"`
+	MaxLoans    int      `json:"max_loans" binding:"required,gt=0"`
+	FinePerDay  *float32 `json:"fine_per_day"`
+	MaxFine     *float32 `json:"max_fine"`
+}
+
+func (r borrowPolicyRequestBody) toPolicy() data.BorrowPolicy {
+	return data.BorrowPolicy{
+		MemberType:  r.MemberType,
+		Category:    r.Category,
+		LoanDays:    r.LoanDays,
+		MaxRenewals: r.MaxRenewals,
+		MaxLoans:    r.MaxLoans,
+		FinePerDay:  r.FinePerDay,
+		MaxFine:     r.MaxFine,
+	}
+}
+
+func (h *AdminHandler) GetBorrowPolicies(c *gin.Context) {
+	policies, err := h.libraryService.GetBorrowPolicies(c.Request.Context())
+
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, policies)
+}
+
+func (h *AdminHandler) InsertBorrowPolicy(c *gin.Context) {
+	var request_body borrowPolicyRequestBody
+
+	if !bindJSON(c, &request_body) {
+		return
+	}
+
+	policy, err := h.libraryService.InsertBorrowPolicy(c.Request.Context(), request_body.toPolicy())
+
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	middlewares.AuditChange(c, "borrow_policy", policy.ID, nil, policy)
+
+	c.JSON(http.StatusCreated, policy)
+}
+
+func (h *AdminHandler) UpdateBorrowPolicy(c *gin.Context) {
+	policy_id, err := pathId(c, "policy_id")
+
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var request_body borrowPolicyRequestBody
+
+	if !bindJSON(c, &request_body) {
+		return
+	}
+
+	previous_policy, err := h.libraryService.GetBorrowPolicy(c.Request.Context(), policy_id)
+
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	policy, err := h.libraryService.UpdateBorrowPolicy(c.Request.Context(), policy_id, request_body.toPolicy())
+
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	middlewares.AuditChange(c, "borrow_policy", policy_id, previous_policy, policy)
+
+	c.JSON(http.StatusOK, policy)
+}
+
+func (h *AdminHandler) DeleteBorrowPolicy(c *gin.Context) {
+	policy_id, err := pathId(c, "policy_id")
+
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	previous_policy, err := h.libraryService.GetBorrowPolicy(c.Request.Context(), policy_id)
+
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	err = h.libraryService.DeleteBorrowPolicy(c.Request.Context(), policy_id)
+
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	middlewares.AuditChange(c, "borrow_policy", policy_id, previous_policy, nil)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Borrow policy deleted successfully"})
+}
+
+func (h *AdminHandler) UpdateMemberType(c *gin.Context) {
+	user_id, err := pathId(c, "user_id")
+
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	type requestBody struct {
+		MemberType string `json:"member_type" binding:"required"`
+	}
+
+	var request_body requestBody
+
+	if !bindJSON(c, &request_body) {
+		return
+	}
+
+	previous_user, err := h.libraryService.GetUser(c.Request.Context(), user_id)
+
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	err = h.libraryService.UpdateMemberType(c.Request.Context(), user_id, request_body.MemberType)
+
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	middlewares.AuditChange(c, "user", user_id, gin.H{"member_type": previous_user.MemberType}, gin.H{"member_type": request_body.MemberType})
+
+	c.JSON(http.StatusOK, gin.H{"user_id": user_id, "member_type": request_body.MemberType})
+}
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/api/middlewares"
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/data"
+)
+
+type userRequestBody struct {
+	Name        string `json:"name" binding:"required,max=64"`
+	Email       string `json:"email" binding:"required,email,max=32"`
+	PhoneNumber string `json:"phone_number" binding:"required,max=16"`
+}
+
+func (h *AdminHandler) GetUser(c *gin.Context) {
+	user_id, err := pathId(c, "user_id")
+
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	user, err := h.libraryService.GetUser(c.Request.Context(), user_id)
+
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	// The password hash never leaves the server.
+	user.Password = ""
+
+	setETag(c, user.Version)
+	c.JSON(http.StatusOK, user)
+}
+
+func (h *AdminHandler) UpdateUser(c *gin.Context) {
+	user_id, err := pathId(c, "user_id")
+
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	version, ok := ifMatchVersion(c)
+
+	if !ok {
+		return
+	}
+
+	var request_body userRequestBody
+
+	if !bindJSON(c, &request_body) {
+		return
+	}
+
+	previous_user, err := h.libraryService.GetUser(c.Request.Context(), user_id)
+
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	previous_user.Password = ""
+
+	user, err := h.libraryService.UpdateUser(c.Request.Context(), user_id, version, request_body.Name, request_body.Email, request_body.PhoneNumber)
+
+	if errors.Is(err, data.ErrEditConflict) {
+		current_user, get_err := h.libraryService.GetUser(c.Request.Context(), user_id)
+
+		if get_err != nil {
+			c.Error(get_err)
+			return
+		}
+		current_user.Password = ""
+		respondEditConflict(c, err, current_user, current_user.Version)
+		return
+	}
+
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	user.Password = ""
+
+	middlewares.AuditChange(c, "user", user_id, previous_user, user)
+	setETag(c, user.Version)
+	c.JSON(http.StatusOK, user)
+}
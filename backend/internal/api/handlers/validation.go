@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/data"
+)
+
+func init() {
+	// Report fields by their json names rather than the Go ones.
+	if engine, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		engine.RegisterTagNameFunc(func(field reflect.StructField) string {
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+
+			if name == "-" {
+				return ""
+			}
+
+			if name == "" {
+				return field.Name
+			}
+			return name
+		})
+	}
+}
+
+func fieldMessage(field_error validator.FieldError) string {
+	is_list := field_error.Kind() == reflect.Slice || field_error.Kind() == reflect.Array
+
+	switch field_error.Tag() {
+	case "required":
+		return "is required"
+	case "email":
+		return "must be a valid email address"
+	case "url":
+		return "must be a valid url"
+	case "oneof":
+		return fmt.Sprintf("must be one of %s", strings.ReplaceAll(field_error.Param(), " ", ", "))
+	case "min":
+		if is_list {
+			return fmt.Sprintf("must contain at least %s items", field_error.Param())
+		}
+
+		if field_error.Kind() == reflect.String {
+			return fmt.Sprintf("must be at least %s characters long", field_error.Param())
+		}
+		return fmt.Sprintf("must be at least %s", field_error.Param())
+	case "max":
+		if is_list {
+			return fmt.Sprintf("must contain at most %s items", field_error.Param())
+		}
+
+		if field_error.Kind() == reflect.String {
+			return fmt.Sprintf("must be at most %s characters long", field_error.Param())
+		}
+		return fmt.Sprintf("must be at most %s", field_error.Param())
+	case "gt":
+		return fmt.Sprintf("must be greater than %s", field_error.Param())
+	case "gte":
+		return fmt.Sprintf("must be greater than or equal to %s", field_error.Param())
+	case "lt":
+		return fmt.Sprintf("must be less than %s", field_error.Param())
+	case "lte":
+		return fmt.Sprintf("must be less than or equal to %s", field_error.Param())
+	case "unique":
+		return "must not contain duplicates"
+	default:
+		return fmt.Sprintf("failed the %s validation", field_error.Tag())
+	}
+}
+
+// Path of the field within the request body, e.g. book_ids[1], without the struct name.
+func fieldPath(field_error validator.FieldError) string {
+	_, path, found := strings.Cut(field_error.Namespace(), ".")
+
+	if !found {
+		return field_error.Field()
+	}
+	return path
+}
+
+// Map a binding error to an error code, a message and the messages of the fields at fault.
+func fieldErrors(err error) (string, string, map[string]string) {
+	var validation_errors validator.ValidationErrors
+	var type_error *json.UnmarshalTypeError
+	var syntax_error *json.SyntaxError
+
+	switch {
+	case errors.As(err, &validation_errors):
+		fields := make(map[string]string, len(validation_errors))
+
+		for _, field_error := range validation_errors {
+			fields[fieldPath(field_error)] = fieldMessage(field_error)
+		}
+		return "validation_failed", "Request validation failed.", fields
+	case errors.As(err, &type_error):
+		field := type_error.Field
+
+		if field == "" {
+			return "invalid_body", "Request body must be a JSON object.", map[string]string{}
+		}
+		return "validation_failed", "Request validation failed.", map[string]string{field: fmt.Sprintf("must be of type %s", type_error.Type.String())}
+	case errors.As(err, &syntax_error), errors.Is(err, io.ErrUnexpectedEOF):
+		return "invalid_body", "Request body is not valid JSON.", map[string]string{}
+	case errors.Is(err, io.EOF):
+		return "missing_body", "Request body is required.", map[string]string{}
+	default:
+		return "invalid_body", "Request body could not be read.", map[string]string{}
+	}
+}
+
+// Answer 400 with the error envelope listing every invalid field under "fields".
+func respondValidationError(c *gin.Context, err error) {
+	code, message, fields := fieldErrors(err)
+	c.Error(&data.DomainError{Kind: data.KindValidation, Code: code, Message: message, Fields: fields})
+}
+
+// The id in the path parameter name, e.g. book_id in /update-book/:book_id.
+func pathId(c *gin.Context, name string) (int, error) {
+	id, err := strconv.Atoi(c.Param(name))
+
+	if err != nil || id <= 0 {
+		return 0, data.Invalid("invalid_path_parameter", "%s must be a positive number.", name)
+	}
+	return id, nil
+}
+
+// Positive id of the query parameter, 0 when it is not given.
+func queryId(c *gin.Context, name string) (int, error) {
+	value := c.Query(name)
+
+	if value == "" {
+		return 0, nil
+	}
+
+	id, err := strconv.Atoi(value)
+
+	if err != nil || id <= 0 {
+		return 0, data.Invalid("invalid_query_parameter", "%s must be a positive number.", name)
+	}
+	return id, nil
+}
+
+// Decode and validate the JSON body into request_body, responding with the field errors when
+// it is not valid. Unknown fields are ignored.
+func bindJSON(c *gin.Context, request_body any) bool {
+	err := c.ShouldBindJSON(request_body)
+
+	if err != nil {
+		respondValidationError(c, err)
+		return false
+	}
+	return true
+}
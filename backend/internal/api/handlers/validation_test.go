@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestBindJSONFieldErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		path       string
+		body       []byte
+		wantCode   string
+		wantFields map[string]string
+	}{
+		{"lists every missing field", "/api/admin/lend-book", []byte(`{}`), "validation_failed", map[string]string{
+			"user_id":  "is required",
+			"book_ids": "is required",
+		}},
+		{"reports a field of the wrong type", "/api/admin/lend-book", []byte(`{"user_id": "7", "book_ids": [1]}`), "validation_failed", map[string]string{
+			"user_id": "must be of type int",
+		}},
+		{"reports the item of a list by index", "/api/admin/lend-book", []byte(`{"user_id": 7, "book_ids": [1, 0]}`), "validation_failed", map[string]string{
+			"book_ids[1]": "must be greater than 0",
+		}},
+		{"reports an empty list", "/api/admin/lend-book", []byte(`{"user_id": 7, "book_ids": []}`), "validation_failed", map[string]string{
+			"book_ids": "must contain at least 1 items",
+		}},
+		{"reports a string too long", "/api/admin/add-author", []byte(`{"name": "` + strings.Repeat("a", 65) + `", "about": "Author"}`), "validation_failed", map[string]string{
+			"name": "must be at most 64 characters long",
+		}},
+		{"rejects a body that is not JSON", "/api/admin/lend-book", []byte(`{"user_id": 7,`), "invalid_body", map[string]string{}},
+		{"rejects a body that is not an object", "/api/admin/lend-book", []byte(`[7]`), "invalid_body", map[string]string{}},
+		{"rejects a missing body", "/api/admin/lend-book", []byte{}, "missing_body", map[string]string{}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := newTestServer(t)
+			fixture := newLoanFixture(t, server)
+
+			response := server.serve(t, http.MethodPost, test.path, fixture.adminToken, nil, test.body)
+
+			var response_body struct {
+				errorBody
+				Fields map[string]string `json:"fields"`
+			}
+
+			err := json.Unmarshal(response.Body.Bytes(), &response_body)
+
+			if err != nil {
+				t.Fatalf("could not decode the response %q: %v", response.Body.String(), err)
+			}
+
+			if response.Code != http.StatusBadRequest || response_body.Code != test.wantCode || response_body.Error == "" {
+				t.Fatalf("%s = %d %s, want %d with code %q and a message", test.path, response.Code, response.Body.String(), http.StatusBadRequest, test.wantCode)
+			}
+
+			if !reflect.DeepEqual(response_body.Fields, test.wantFields) {
+				t.Errorf("fields = %v, want %v", response_body.Fields, test.wantFields)
+			}
+		})
+	}
+}
+
+func TestGetAuthor(t *testing.T) {
+	tests := []struct {
+		name string
+		// Path of the request, for the author created by the test.
+		path       func(author_id int) string
+		body       any
+		wantStatus int
+		wantCode   string
+		wantNames  []string
+	}{
+		{"reads the author of the path", func(id int) string { return "/api/admin/get-author/" + strconv.Itoa(id) }, nil, http.StatusOK, "", []string{"N. K. Jemisin"}},
+		{"reads the author of the query", func(id int) string { return "/api/admin/get-author?id=" + strconv.Itoa(id) }, nil, http.StatusOK, "", []string{"N. K. Jemisin"}},
+		{"searches the authors by name", func(id int) string { return "/api/admin/get-author?name=jemisin" }, nil, http.StatusOK, "", []string{"N. K. Jemisin"}},
+		{"lists the authors without a body", func(id int) string { return "/api/admin/get-author" }, nil, http.StatusOK, "", []string{"N. K. Jemisin", "Ursula K. Le Guin"}},
+		{"ignores a body", func(id int) string { return "/api/admin/get-author?name=guin" }, gin.H{"id": 1000}, http.StatusOK, "", []string{"Ursula K. Le Guin"}},
+		{"rejects an invalid path id", func(id int) string { return "/api/admin/get-author/first" }, nil, http.StatusBadRequest, "invalid_path_parameter", nil},
+		{"rejects an invalid query id", func(id int) string { return "/api/admin/get-author?id=-1" }, nil, http.StatusBadRequest, "invalid_query_parameter", nil},
+		{"rejects a name too long", func(id int) string { return "/api/admin/get-author?name=" + strings.Repeat("a", 65) }, nil, http.StatusBadRequest, "invalid_query_parameter", nil},
+		{"answers an unknown author with 404", func(id int) string { return "/api/admin/get-author/" + strconv.Itoa(id+1000) }, nil, http.StatusNotFound, "author_not_found", nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := newTestServer(t)
+			fixture := newLoanFixture(t, server)
+
+			author, err := server.service.InsertAuthor(context.Background(), "N. K. Jemisin", "Author")
+
+			if err != nil {
+				t.Fatalf("could not create the author: %v", err)
+			}
+
+			response := server.serve(t, http.MethodGet, test.path(author.ID), fixture.adminToken, nil, test.body)
+
+			if response.Code != test.wantStatus {
+				t.Fatalf("get-author = %d %s, want %d", response.Code, response.Body.String(), test.wantStatus)
+			}
+
+			if test.wantCode != "" {
+				var response_body errorBody
+
+				if err = json.Unmarshal(response.Body.Bytes(), &response_body); err != nil || response_body.Code != test.wantCode {
+					t.Errorf("get-author = %s, want code %q", response.Body.String(), test.wantCode)
+				}
+				return
+			}
+
+			var authors []struct {
+				Name string `json:"name"`
+			}
+
+			if err = json.Unmarshal(response.Body.Bytes(), &authors); err != nil {
+				t.Fatalf("could not decode the response %q: %v", response.Body.String(), err)
+			}
+
+			names := make([]string, 0, len(authors))
+
+			for _, found := range authors {
+				names = append(names, found.Name)
+			}
+
+			if !reflect.DeepEqual(names, test.wantNames) {
+				t.Errorf("get-author = %v, want %v", names, test.wantNames)
+			}
+		})
+	}
+}
@@ -0,0 +1,229 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/api/middlewares"
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/data"
+)
+
+const defaultWebhookDeliveryLimit = 50
+
+type webhookRequestBody struct {
+	Name       string   `json:"name" binding:"required"`
+	URL        string   `json:"url" binding:"required,url"`
+	Secret     string   `json:"secret"`
+	EventTypes []string `json:"event_types"`
+	Active     *bool    `json:"active"`
+}
+
+func (r webhookRequestBody) subscription() data.WebhookSubscription {
+	active := true
+
+	if r.Active != nil {
+		active = *r.Active
+	}
+
+	return data.WebhookSubscription{
+		Name:       r.Name,
+		URL:        r.URL,
+		Secret:     r.Secret,
+		EventTypes: r.EventTypes,
+		Active:     active,
+	}
+}
+
+func (h *AdminHandler) InsertWebhookSubscription(c *gin.Context) {
+	var request_body webhookRequestBody
+
+	if !bindJSON(c, &request_body) {
+		return
+	}
+
+	subscription, err := h.libraryService.InsertWebhookSubscription(c.Request.Context(), request_body.subscription())
+
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	middlewares.AuditChange(c, "webhook_subscription", subscription.ID, nil, subscription)
+
+	c.JSON(http.StatusCreated, subscription)
+}
+
+func (h *AdminHandler) GetWebhookSubscriptions(c *gin.Context) {
+	subscriptions, err := h.libraryService.GetWebhookSubscriptions(c.Request.Context())
+
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, subscriptions)
+}
+
+func (h *AdminHandler) UpdateWebhookSubscription(c *gin.Context) {
+	subscription_id, err := pathId(c, "subscription_id")
+
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var request_body webhookRequestBody
+
+	if !bindJSON(c, &request_body) {
+		return
+	}
+
+	previous_subscription, err := h.libraryService.GetWebhookSubscriptionWithoutSecret(c.Request.Context(), subscription_id)
+
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	subscription, err := h.libraryService.UpdateWebhookSubscription(c.Request.Context(), subscription_id, request_body.subscription())
+
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	middlewares.AuditChange(c, "webhook_subscription", subscription_id, previous_subscription, subscription)
+
+	c.JSON(http.StatusOK, subscription)
+}
+
+func (h *AdminHandler) RotateWebhookSecret(c *gin.Context) {
+	subscription_id, err := pathId(c, "subscription_id")
+
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	previous_subscription, err := h.libraryService.GetWebhookSubscriptionWithoutSecret(c.Request.Context(), subscription_id)
+
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	subscription, err := h.libraryService.RotateWebhookSecret(c.Request.Context(), subscription_id)
+
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	// The new secret is redacted from the audit log.
+	middlewares.AuditChange(c, "webhook_subscription", subscription_id, previous_subscription, subscription)
+
+	c.JSON(http.StatusOK, subscription)
+}
+
+func (h *AdminHandler) DeleteWebhookSubscription(c *gin.Context) {
+	subscription_id, err := pathId(c, "subscription_id")
+
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	previous_subscription, err := h.libraryService.GetWebhookSubscriptionWithoutSecret(c.Request.Context(), subscription_id)
+
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	err = h.libraryService.DeleteWebhookSubscription(c.Request.Context(), subscription_id)
+
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	middlewares.AuditChange(c, "webhook_subscription", subscription_id, previous_subscription, nil)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Webhook subscription deleted successfully"})
+}
+
+func (h *AdminHandler) GetWebhookDeliveries(c *gin.Context) {
+	subscription_id, err := pathId(c, "subscription_id")
+
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	limit := defaultWebhookDeliveryLimit
+
+	if value := c.Query("limit"); value != "" {
+		parsed_limit, err := strconv.Atoi(value)
+
+		if err != nil || parsed_limit <= 0 {
+			c.Error(data.Invalid("invalid_query_parameter", "limit must be a positive number."))
+			return
+		}
+		limit = parsed_limit
+	}
+
+	deliveries, err := h.libraryService.GetWebhookDeliveries(c.Request.Context(), subscription_id, c.Query("status"), limit)
+
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, deliveries)
+}
+
+func (h *AdminHandler) GetWebhookDelivery(c *gin.Context) {
+	delivery_id, err := strconv.ParseInt(c.Param("delivery_id"), 10, 64)
+
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	delivery, err := h.libraryService.GetWebhookDelivery(c.Request.Context(), delivery_id)
+
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, delivery)
+}
+
+func (h *AdminHandler) ReplayWebhookDelivery(c *gin.Context) {
+	delivery_id, err := strconv.ParseInt(c.Param("delivery_id"), 10, 64)
+
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	previous_delivery, err := h.libraryService.GetWebhookDelivery(c.Request.Context(), delivery_id)
+
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	delivery, err := h.libraryService.ReplayWebhookDelivery(c.Request.Context(), delivery_id)
+
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	middlewares.AuditChange(c, "webhook_delivery", delivery_id, previous_delivery, delivery)
+
+	c.JSON(http.StatusAccepted, delivery)
+}
@@ -0,0 +1,104 @@
+package middlewares
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/data"
+)
+
+type AuditRecorder interface {
+	RecordAudit(ctx context.Context, entry data.AuditEntry) error
+}
+
+const auditChangeKey = "audit_change"
+
+type auditChange struct {
+	entityType string
+	entityId   string
+	before     any
+	after      any
+}
+
+// Describe the entity a request changed and its state before and after the change, for the
+// audit middleware to record. Either state may be nil for creations and deletions.
+func AuditChange(c *gin.Context, entity_type string, entity_id any, before, after any) {
+	c.Set(auditChangeKey, &auditChange{
+		entityType: entity_type,
+		entityId:   fmt.Sprint(entity_id),
+		before:     before,
+		after:      after,
+	})
+}
+
+func isMutation(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	default:
+		return true
+	}
+}
+
+func marshalState(state any) json.RawMessage {
+	if state == nil {
+		return nil
+	}
+
+	encoded, err := json.Marshal(state)
+
+	if err != nil || string(encoded) == "null" {
+		return nil
+	}
+	return encoded
+}
+
+// Records every mutating request in the audit log once it has been handled, failed ones
+// included. It has to run after AuthMiddleware, which identifies the actor.
+func AuditMiddleware(recorder AuditRecorder) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !isMutation(c.Request.Method) {
+			c.Next()
+			return
+		}
+
+		c.Next()
+
+		entry := data.AuditEntry{
+			ActorEmail: c.GetString("email_id"),
+			Action:     c.Request.Method + " " + c.FullPath(),
+			Path:       c.Request.URL.Path,
+			IPAddress:  c.ClientIP(),
+			RequestId:  c.GetString("request_id"),
+			StatusCode: responseStatus(c),
+		}
+
+		if actor_id := c.GetInt("user_id"); actor_id != 0 {
+			entry.ActorId = &actor_id
+		}
+
+		if value, ok := c.Get(auditChangeKey); ok {
+			change := value.(*auditChange)
+			entry.EntityType = change.entityType
+			entry.EntityId = change.entityId
+			entry.Before = marshalState(change.before)
+			entry.After = marshalState(change.after)
+		} else if len(c.Params) > 0 {
+			// Fall back on the path, e.g. book_id=7 on /update-book/:book_id.
+			entry.EntityType = strings.TrimSuffix(c.Params[0].Key, "_id")
+			entry.EntityId = c.Params[0].Value
+		}
+
+		// Recorded even when the client went away before the response.
+		err := recorder.RecordAudit(context.WithoutCancel(c.Request.Context()), entry)
+
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Could not record audit entry", "method", c.Request.Method, "path", entry.Path, "error", err)
+		}
+	}
+}
@@ -1,38 +1,62 @@
 package middlewares
 
 import (
-	"net/http"
+	"context"
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/data"
 	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/utils"
 )
 
-func AuthMiddleware() gin.HandlerFunc {
+type TokenAuthenticator interface {
+	ParseToken(token string) (*utils.ParsedToken, error)
+	IsTokenRevoked(ctx context.Context, token_id string) (bool, error)
+}
+
+func AuthMiddleware(authenticator TokenAuthenticator) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 
 		if authHeader == "" {
-			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Authorization header is required."})
+			c.Error(data.Unauthorized("missing_authorization", "Authorization header is required."))
+			c.Abort()
 			return
 		}
 		auth_header_slice := strings.Split(authHeader, " ")
 
-		if len(auth_header_slice) > 2 {
-			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid Authorization Header."})
+		if len(auth_header_slice) != 2 || !strings.EqualFold(auth_header_slice[0], "Bearer") {
+			c.Error(data.Unauthorized("invalid_authorization", "Invalid Authorization Header."))
+			c.Abort()
 			return
 		}
 
 		token := auth_header_slice[1]
-		parsed_token, err := utils.ParseAndValidateToken(token)
+		parsed_token, err := authenticator.ParseToken(token)
+
+		if err != nil {
+			c.Error(err)
+			c.Abort()
+			return
+		}
+
+		revoked, err := authenticator.IsTokenRevoked(c.Request.Context(), parsed_token.TokenId)
 
 		if err != nil {
-			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			c.Error(err)
+			c.Abort()
+			return
+		}
+
+		if revoked {
+			c.Error(data.Unauthorized("token_revoked", "Token has been revoked."))
+			c.Abort()
 			return
 		}
 
 		if !parsed_token.IsAdmin {
-			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "You don't have access to these endpoints."})
+			c.Error(data.Forbidden("admin_required", "You don't have access to these endpoints."))
+			c.Abort()
 			return
 		}
 		c.Set("user_id", parsed_token.UserId)
@@ -0,0 +1,88 @@
+package middlewares
+
+import (
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/data"
+)
+
+const internalErrorCode = "internal_error"
+
+var kindStatus = map[data.ErrorKind]int{
+	data.KindValidation:   http.StatusBadRequest,
+	data.KindUnauthorized: http.StatusUnauthorized,
+	data.KindForbidden:    http.StatusForbidden,
+	data.KindNotFound:     http.StatusNotFound,
+	data.KindConflict:     http.StatusConflict,
+	data.KindPrecondition: http.StatusPreconditionRequired,
+	data.KindRateLimited:  http.StatusTooManyRequests,
+}
+
+// Status and body of the response to err. The body is always {"message", "error", "code"},
+// plus "fields" for invalid requests. Errors that are not domain errors are answered with a
+// generic message so database and driver errors never reach the client.
+func ErrorResponse(err error) (int, gin.H) {
+	domain_error, ok := data.AsDomainError(err)
+
+	if !ok {
+		return http.StatusInternalServerError, gin.H{
+			"message": "",
+			"error":   "Something went wrong, please try again later.",
+			"code":    internalErrorCode,
+		}
+	}
+
+	status, ok := kindStatus[domain_error.Kind]
+
+	if !ok {
+		status = http.StatusInternalServerError
+	}
+
+	body := gin.H{"message": "", "error": domain_error.Message, "code": domain_error.Code}
+
+	if domain_error.Fields != nil {
+		body["fields"] = domain_error.Fields
+	}
+	return status, body
+}
+
+// Status of the response, including the error ErrorMiddleware is yet to answer with.
+func responseStatus(c *gin.Context) int {
+	if !c.Writer.Written() && len(c.Errors) > 0 {
+		status, _ := ErrorResponse(c.Errors.Last().Err)
+		return status
+	}
+	return c.Writer.Status()
+}
+
+// Answers the last error a handler or middleware added with c.Error, unless a response was
+// already written. It has to run before every other middleware so their errors are answered too.
+func ErrorMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+
+		err := c.Errors.Last().Err
+		status, body := ErrorResponse(err)
+
+		if status >= http.StatusInternalServerError {
+			slog.ErrorContext(c.Request.Context(), "Request failed", "method", c.Request.Method, "path", c.Request.URL.Path, "error", err)
+		}
+
+		if status == http.StatusUnauthorized {
+			c.Header("WWW-Authenticate", `Bearer realm="api"`)
+		}
+
+		if domain_error, ok := data.AsDomainError(err); ok && domain_error.RetryAfter > 0 {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(domain_error.RetryAfter.Seconds()))))
+		}
+		c.AbortWithStatusJSON(status, body)
+	}
+}
@@ -0,0 +1,23 @@
+package middlewares
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RequestObserver interface {
+	ObserveRequest(method, route string, status int, elapsed time.Duration)
+}
+
+// Records every request with the route it matched and the status it was answered with,
+// including the errors ErrorMiddleware answers.
+func MetricsMiddleware(observer RequestObserver) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		started_at := time.Now()
+
+		c.Next()
+
+		observer.ObserveRequest(c.Request.Method, c.FullPath(), responseStatus(c), time.Since(started_at))
+	}
+}
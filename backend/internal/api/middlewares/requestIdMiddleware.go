@@ -0,0 +1,52 @@
+package middlewares
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/logging"
+)
+
+const RequestIdHeader = "X-Request-Id"
+
+const maxRequestIdLength = 128
+
+func newRequestId() string {
+	id := make([]byte, 16)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+// Only ids a proxy could reasonably have generated are kept, anything else is replaced so
+// that it can't end up in the logs or the audit trail.
+func validRequestId(id string) bool {
+	if id == "" || len(id) > maxRequestIdLength {
+		return false
+	}
+
+	for _, r := range id {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' || r == '.') {
+			return false
+		}
+	}
+	return true
+}
+
+// Tags every request with an id, reusing the X-Request-Id sent by the client or a proxy,
+// and echoes it back so a response can be matched with the server side records. The id is
+// added to the request context for the logs.
+func RequestIdMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		request_id := c.GetHeader(RequestIdHeader)
+
+		if !validRequestId(request_id) {
+			request_id = newRequestId()
+		}
+
+		c.Set("request_id", request_id)
+		c.Request = c.Request.WithContext(logging.WithRequestId(c.Request.Context(), request_id))
+		c.Header(RequestIdHeader, request_id)
+		c.Next()
+	}
+}
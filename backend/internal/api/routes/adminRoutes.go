@@ -3,17 +3,75 @@ package routes
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/api/handlers"
-	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/api/middlewares"
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/data"
 )
 
-func SetupAdminRoutes(router *gin.RouterGroup, handler *handlers.AdminHandler) {
+func SetupAdminRoutes(router *gin.RouterGroup, handler *handlers.AdminHandler, authMiddleware, auditMiddleware gin.HandlerFunc) {
 	adminRouter := router.Group("/admin")
-	adminRouter.Use(middlewares.AuthMiddleware())
+	adminRouter.Use(authMiddleware, auditMiddleware)
 	adminRouter.POST("/add-author", handler.InsertAuthor)
 	adminRouter.GET("/get-author", handler.GetAuthor)
+	adminRouter.GET("/get-author/:author_id", handler.GetAuthor)
+	adminRouter.PUT("/update-author/:author_id", handler.UpdateAuthor)
 	adminRouter.GET("/get-book", handler.QueryBooks)
 	adminRouter.POST("/add-book", handler.InsertBook)
 	adminRouter.PUT("/update-book/:book_id", handler.UpdateBook)
+	adminRouter.GET("/books/:book_id/revisions", handler.GetBookRevisions)
+	adminRouter.GET("/books/:book_id/revisions/:revision", handler.GetBookRevision)
+	adminRouter.GET("/books/:book_id/as-of", handler.GetBookAsOf)
 	adminRouter.POST("/lend-book", handler.LendBook)
+	adminRouter.POST("/return-book", handler.ReturnBook)
+	adminRouter.POST("/renew-book", handler.RenewBook)
+	adminRouter.GET("/get-user/:user_id", handler.GetUser)
+	adminRouter.PUT("/update-user/:user_id", handler.UpdateUser)
+	adminRouter.PUT("/update-member-type/:user_id", handler.UpdateMemberType)
+	adminRouter.PUT("/activate-user/:user_id", handler.ActivateUser)
 
+	fineRouter := adminRouter.Group("/fines")
+	fineRouter.POST("/charge", handler.RecordFineEntry(data.FineEntryCharge))
+	fineRouter.POST("/payment", handler.RecordFineEntry(data.FineEntryPayment))
+	fineRouter.POST("/waiver", handler.RecordFineEntry(data.FineEntryWaiver))
+	fineRouter.POST("/refund", handler.RecordFineEntry(data.FineEntryRefund))
+	fineRouter.GET("/:user_id/balance", handler.GetFineBalance)
+	fineRouter.GET("/:user_id/statement", handler.GetFineStatement)
+
+	policyRouter := adminRouter.Group("/policies")
+	policyRouter.GET("", handler.GetBorrowPolicies)
+	policyRouter.POST("", handler.InsertBorrowPolicy)
+	policyRouter.PUT("/:policy_id", handler.UpdateBorrowPolicy)
+	policyRouter.DELETE("/:policy_id", handler.DeleteBorrowPolicy)
+
+	calendarRouter := adminRouter.Group("/calendar")
+	calendarRouter.GET("/hours", handler.GetLibraryHours)
+	calendarRouter.PUT("/hours/:weekday", handler.UpdateLibraryHours)
+	calendarRouter.GET("/closures", handler.GetLibraryClosures)
+	calendarRouter.POST("/closures", handler.InsertLibraryClosure)
+	calendarRouter.DELETE("/closures/:closure_id", handler.DeleteLibraryClosure)
+
+	holdRouter := adminRouter.Group("/holds")
+	holdRouter.POST("", handler.PlaceHold)
+	holdRouter.GET("/user/:user_id", handler.GetUserHolds)
+	holdRouter.PUT("/:hold_id/ready", handler.MarkHoldReady)
+	holdRouter.PUT("/:hold_id/cancel", handler.CancelHold)
+
+	adminRouter.GET("/jobs/runs", handler.GetJobRuns)
+
+	notificationRouter := adminRouter.Group("/notifications")
+	notificationRouter.GET("/:user_id", handler.GetUserNotifications)
+	notificationRouter.GET("/:user_id/preferences", handler.GetNotificationPreferences)
+	notificationRouter.PUT("/:user_id/preferences", handler.UpdateNotificationPreference)
+
+	auditRouter := adminRouter.Group("/audit")
+	auditRouter.GET("", handler.GetAuditLog)
+	auditRouter.GET("/export", handler.ExportAuditLog)
+
+	webhookRouter := adminRouter.Group("/webhooks")
+	webhookRouter.GET("", handler.GetWebhookSubscriptions)
+	webhookRouter.POST("", handler.InsertWebhookSubscription)
+	webhookRouter.PUT("/:subscription_id", handler.UpdateWebhookSubscription)
+	webhookRouter.DELETE("/:subscription_id", handler.DeleteWebhookSubscription)
+	webhookRouter.POST("/:subscription_id/rotate-secret", handler.RotateWebhookSecret)
+	webhookRouter.GET("/:subscription_id/deliveries", handler.GetWebhookDeliveries)
+	webhookRouter.GET("/deliveries/:delivery_id", handler.GetWebhookDelivery)
+	webhookRouter.POST("/deliveries/:delivery_id/replay", handler.ReplayWebhookDelivery)
 }
@@ -10,5 +10,6 @@ func SetupAuthRoutes(router *gin.RouterGroup, handler *handlers.AuthHandler) {
 	{
 		authRouter.POST("/register", handler.Register)
 		authRouter.POST("/login", handler.Login)
+		authRouter.POST("/logout", handler.Logout)
 	}
 }
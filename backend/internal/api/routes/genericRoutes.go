@@ -1,6 +1,8 @@
 package routes
 
 import (
+	"net/http"
+
 	"github.com/gin-gonic/gin"
 	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/api/handlers"
 )
@@ -8,3 +10,14 @@ import (
 func SetupGenericRoutes(router *gin.RouterGroup, handler *handlers.GenericHandler) {
 	router.GET("/ping", handler.Ping)
 }
+
+// Probes of the container orchestrator, served at the root outside of /api.
+func SetupHealthRoutes(router gin.IRoutes, handler *handlers.GenericHandler) {
+	router.GET("/healthz", handler.Healthz)
+	router.GET("/readyz", handler.Readyz)
+}
+
+// Prometheus metrics, scraped at the root like the probes.
+func SetupMetricsRoutes(router gin.IRoutes, handler http.Handler) {
+	router.GET("/metrics", gin.WrapH(handler))
+}
@@ -0,0 +1,49 @@
+// Package cache keeps encoded values by key for a while, in the process or in a Redis
+// compatible server the instances of the server share.
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const (
+	BackendNone  = "none"
+	BackendLRU   = "lru"
+	BackendRedis = "redis"
+)
+
+type Cache interface {
+	// The value of the key, false when it is not cached or has expired.
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	// Keep the value for ttl, 0 keeps it until it is evicted or deleted.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, keys ...string) error
+	Close() error
+}
+
+type Settings struct {
+	// none, lru or redis.
+	Backend string
+	// Entries the lru backend keeps at most.
+	Size int
+	// host:port of the Redis compatible server.
+	RedisAddr     string
+	RedisPassword string
+	RedisDB       int
+}
+
+// The cache of the backend, nil for none.
+func New(settings Settings) (Cache, error) {
+	switch settings.Backend {
+	case BackendNone, "":
+		return nil, nil
+	case BackendLRU:
+		return NewLRU(settings.Size), nil
+	case BackendRedis:
+		return NewRedis(settings.RedisAddr, settings.RedisPassword, settings.RedisDB), nil
+	default:
+		return nil, fmt.Errorf("unknown cache backend %q", settings.Backend)
+	}
+}
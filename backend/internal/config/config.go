@@ -0,0 +1,647 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/cache"
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/data"
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/db"
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/jobs"
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/logging"
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/notifications"
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/outbox"
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/ratelimit"
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/services"
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/tracing"
+	"gopkg.in/yaml.v3"
+)
+
+// Settings of the server. They start from the defaults, then the YAML file given with -config
+// or CONFIG_FILE, the environment and the command line flags each override the ones before.
+type Config struct {
+	Server        ServerConfig        `yaml:"server"`
+	Database      DatabaseConfig      `yaml:"database"`
+	Auth          AuthConfig          `yaml:"auth"`
+	Library       LibraryConfig       `yaml:"library"`
+	Notifications NotificationsConfig `yaml:"notifications"`
+	Outbox        OutboxConfig        `yaml:"outbox"`
+	Webhooks      WebhooksConfig      `yaml:"webhooks"`
+	Tracing       TracingConfig       `yaml:"tracing"`
+	Logging       LoggingConfig       `yaml:"logging"`
+	Cache         CacheConfig         `yaml:"cache"`
+	RateLimit     RateLimitConfig     `yaml:"rate_limit"`
+	// Cron schedules of the background jobs by job name, replacing their default schedule.
+	JobSchedules map[string]string `yaml:"job_schedules"`
+}
+
+type ServerConfig struct {
+	Port              int           `yaml:"port"`
+	ReadTimeout       time.Duration `yaml:"read_timeout"`
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout"`
+	WriteTimeout      time.Duration `yaml:"write_timeout"`
+	IdleTimeout       time.Duration `yaml:"idle_timeout"`
+	MaxHeaderBytes    int           `yaml:"max_header_bytes"`
+	// How long the requests in flight get to finish on shutdown.
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+	// TLS is served when both are set.
+	TLSCertFile string `yaml:"tls_cert_file"`
+	TLSKeyFile  string `yaml:"tls_key_file"`
+	// Addresses or CIDRs of the proxies whose X-Forwarded-For is believed for the client ip.
+	TrustedProxies []string `yaml:"trusted_proxies"`
+	// Instances of the server running against the same database.
+	Instances int `yaml:"instances"`
+}
+
+func (s ServerConfig) TLS() bool {
+	return s.TLSCertFile != "" && s.TLSKeyFile != ""
+}
+
+type DatabaseConfig struct {
+	DSN string `yaml:"dsn"`
+	// Read replica the read only catalogue queries go to when set.
+	ReplicaDSN string `yaml:"replica_dsn"`
+	// Pool limits of the primary and the replica each, 0 for no limit.
+	MaxOpenConns    int           `yaml:"max_open_conns"`
+	MaxIdleConns    int           `yaml:"max_idle_conns"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime"`
+	ConnMaxIdleTime time.Duration `yaml:"conn_max_idle_time"`
+	// How long to keep trying to connect on startup.
+	StartupTimeout time.Duration `yaml:"startup_timeout"`
+	// Durations such as 5s or 1m, keyed read, list, write, transaction and batch.
+	Timeouts data.Timeouts `yaml:"timeouts"`
+	// Isolation of the units of work: read_committed, repeatable_read or serializable.
+	TxIsolation  string `yaml:"tx_isolation"`
+	TxMaxRetries int    `yaml:"tx_max_retries"`
+}
+
+type AuthConfig struct {
+	JWTSecret   string        `yaml:"jwt_secret"`
+	TokenExpiry time.Duration `yaml:"token_expiry"`
+}
+
+type LibraryConfig struct {
+	// Outstanding balance above which a member can not borrow new books.
+	FineBlockThreshold float32 `yaml:"fine_block_threshold"`
+	// Open days a member has to collect a book once the hold is ready.
+	HoldPickupDays int `yaml:"hold_pickup_days"`
+	// How many hours ahead members are reminded of the loans falling due.
+	DueSoonReminderHours int `yaml:"due_soon_reminder_hours"`
+}
+
+type NotificationsConfig struct {
+	SMTPHost        string   `yaml:"smtp_host"`
+	SMTPPort        string   `yaml:"smtp_port"`
+	SMTPUsername    string   `yaml:"smtp_username"`
+	SMTPPassword    string   `yaml:"smtp_password"`
+	SMTPFrom        string   `yaml:"smtp_from"`
+	WebhookURL      string   `yaml:"webhook_url"`
+	LogFile         string   `yaml:"log_file"`
+	DefaultChannels []string `yaml:"default_channels"`
+	MaxAttempts     int      `yaml:"max_attempts"`
+}
+
+type OutboxConfig struct {
+	// stdout, webhook or nats.
+	Sinks             []string `yaml:"sinks"`
+	WebhookURL        string   `yaml:"webhook_url"`
+	NATSURL           string   `yaml:"nats_url"`
+	NATSSubjectPrefix string   `yaml:"nats_subject_prefix"`
+}
+
+type WebhooksConfig struct {
+	MaxAttempts int `yaml:"max_attempts"`
+}
+
+type TracingConfig struct {
+	// none, stdout or otlp.
+	Exporter string `yaml:"exporter"`
+	// host:port of the OTLP/HTTP collector, the OTEL_EXPORTER_OTLP_* variables apply when empty.
+	OTLPEndpoint string `yaml:"otlp_endpoint"`
+	OTLPInsecure bool   `yaml:"otlp_insecure"`
+	ServiceName  string `yaml:"service_name"`
+	// Share of the traces started here that are recorded, from 0 to 1.
+	SampleRatio float64 `yaml:"sample_ratio"`
+}
+
+type LoggingConfig struct {
+	// debug, info, warn or error.
+	Level string `yaml:"level"`
+	// json or text.
+	Format string `yaml:"format"`
+	// SQL statements logged: off, slow for the ones taking slow_query_threshold or longer, or all.
+	SQL                string        `yaml:"sql"`
+	SlowQueryThreshold time.Duration `yaml:"slow_query_threshold"`
+}
+
+type CacheConfig struct {
+	// none, lru or redis.
+	Backend string `yaml:"backend"`
+	// How long the catalogue reads are cached.
+	TTL time.Duration `yaml:"ttl"`
+	// Entries the lru backend keeps at most.
+	Size int `yaml:"size"`
+	// host:port of the Redis compatible server.
+	RedisAddr     string `yaml:"redis_addr"`
+	RedisPassword string `yaml:"redis_password"`
+	RedisDB       int    `yaml:"redis_db"`
+}
+
+type RateLimitConfig struct {
+	// memory or redis.
+	Store string `yaml:"store"`
+	// host:port of the Redis compatible server.
+	RedisAddr     string `yaml:"redis_addr"`
+	RedisPassword string `yaml:"redis_password"`
+	RedisDB       int    `yaml:"redis_db"`
+	// What happens to the attempts when the redis store fails: allow, deny or memory to count
+	// them in each instance until the store is back.
+	OnStoreError string `yaml:"on_store_error"`
+	// Registrations allowed per client ip in their window.
+	RegisterIPLimit  int           `yaml:"register_ip_limit"`
+	RegisterIPWindow time.Duration `yaml:"register_ip_window"`
+	// Login attempts allowed per client ip and per account in their window.
+	LoginIPLimit       int           `yaml:"login_ip_limit"`
+	LoginIPWindow      time.Duration `yaml:"login_ip_window"`
+	LoginAccountLimit  int           `yaml:"login_account_limit"`
+	LoginAccountWindow time.Duration `yaml:"login_account_window"`
+	// Failed logins within the account window that lock the account, for the lockout duration
+	// doubled with each lockout of the day, up to the max.
+	LockoutThreshold   int           `yaml:"lockout_threshold"`
+	LockoutDuration    time.Duration `yaml:"lockout_duration"`
+	MaxLockoutDuration time.Duration `yaml:"max_lockout_duration"`
+}
+
+func Default() *Config {
+	tx_config := data.DefaultTxConfig()
+
+	return &Config{
+		Server: ServerConfig{
+			Port:              8080,
+			ReadTimeout:       10 * time.Second,
+			ReadHeaderTimeout: 5 * time.Second,
+			WriteTimeout:      30 * time.Second,
+			IdleTimeout:       2 * time.Minute,
+			MaxHeaderBytes:    1 << 20,
+			ShutdownTimeout:   30 * time.Second,
+			Instances:         1,
+		},
+		Database: DatabaseConfig{
+			MaxOpenConns:    25,
+			MaxIdleConns:    25,
+			ConnMaxLifetime: 30 * time.Minute,
+			ConnMaxIdleTime: 5 * time.Minute,
+			StartupTimeout:  time.Minute,
+			Timeouts:        data.DefaultTimeouts(),
+			TxIsolation:     "read_committed",
+			TxMaxRetries:    tx_config.MaxRetries,
+		},
+		Auth: AuthConfig{TokenExpiry: 3 * time.Hour},
+		Library: LibraryConfig{
+			HoldPickupDays:       3,
+			DueSoonReminderHours: 48,
+		},
+		Notifications: NotificationsConfig{
+			SMTPPort:        "25",
+			DefaultChannels: []string{notifications.ChannelEmail, notifications.ChannelLog},
+			MaxAttempts:     5,
+		},
+		Outbox:   OutboxConfig{NATSSubjectPrefix: "library."},
+		Webhooks: WebhooksConfig{MaxAttempts: 8},
+		Tracing: TracingConfig{
+			Exporter:    tracing.ExporterNone,
+			ServiceName: "library-backend",
+			SampleRatio: 1,
+		},
+		Logging: LoggingConfig{
+			Level:              "info",
+			Format:             logging.FormatJSON,
+			SQL:                db.QueryLogSlow,
+			SlowQueryThreshold: 200 * time.Millisecond,
+		},
+		Cache: CacheConfig{
+			Backend:   cache.BackendLRU,
+			TTL:       5 * time.Minute,
+			Size:      10000,
+			RedisAddr: "localhost:6379",
+		},
+		RateLimit: RateLimitConfig{
+			Store:              ratelimit.StoreMemory,
+			RedisAddr:          "localhost:6379",
+			OnStoreError:       ratelimit.OnErrorMemory,
+			RegisterIPLimit:    5,
+			RegisterIPWindow:   time.Hour,
+			LoginIPLimit:       20,
+			LoginIPWindow:      time.Minute,
+			LoginAccountLimit:  10,
+			LoginAccountWindow: 15 * time.Minute,
+			LockoutThreshold:   5,
+			LockoutDuration:    time.Minute,
+			MaxLockoutDuration: time.Hour,
+		},
+		JobSchedules: make(map[string]string),
+	}
+}
+
+// Load the config for the command line arguments, without the program name. Every problem
+// found is reported in the one error.
+func Load(args []string) (*Config, error) {
+	config, _, err := load("server", "Usage: server [flags]\n       server migrate [flags] <action>", args, (*Config).validate)
+	return config, err
+}
+
+// Load the config of a command only working with the database, like migrate, checking the
+// database and logging settings only. usage is shown with the flags on -h. The arguments left
+// after the flags are returned.
+func LoadDatabase(command, usage string, args []string) (*Config, []string, error) {
+	return load(command, usage, args, func(c *Config) []string {
+		return append(c.validateDatabase(), c.validateLogging()...)
+	})
+}
+
+func load(command, usage string, args []string, validate func(c *Config) []string) (*Config, []string, error) {
+	flags := flag.NewFlagSet(command, flag.ContinueOnError)
+	config_file := flags.String("config", "", "YAML config `file`, defaults to CONFIG_FILE")
+	env_file := flags.String("env-file", "", "`file` of environment variables to load first, such as .env")
+	dsn := flags.String("dsn", "", "Postgres connection string, overrides DSN")
+
+	var port *int
+
+	if command == "server" {
+		port = flags.Int("port", 0, "port to listen on, overrides PORT")
+	}
+
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "%s\n\nFlags:\n", usage)
+		flags.PrintDefaults()
+	}
+
+	err := flags.Parse(args)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Variables already set in the environment win over the ones in the file.
+	if *env_file != "" {
+		err = godotenv.Load(*env_file)
+
+		if err != nil {
+			return nil, nil, fmt.Errorf("loading env file: %w", err)
+		}
+	}
+
+	if *config_file == "" {
+		*config_file = os.Getenv("CONFIG_FILE")
+	}
+
+	config := Default()
+
+	if *config_file != "" {
+		err = config.loadFile(*config_file)
+
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	problems := config.loadEnv()
+
+	flags.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "port":
+			config.Server.Port = *port
+		case "dsn":
+			config.Database.DSN = *dsn
+		}
+	})
+
+	problems = append(problems, validate(config)...)
+
+	if len(problems) > 0 {
+		return nil, nil, fmt.Errorf("invalid configuration:\n  %s", strings.Join(problems, "\n  "))
+	}
+	return config, flags.Args(), nil
+}
+
+func (c *Config) loadFile(path string) error {
+	content, err := os.ReadFile(path)
+
+	if err != nil {
+		return fmt.Errorf("reading config file: %w", err)
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+
+	err = decoder.Decode(c)
+
+	// An empty file leaves the defaults as they are.
+	if err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("parsing config file %s: %w", path, err)
+	}
+	return nil
+}
+
+// Check adding the problem to problems when ok is false.
+func checker(problems *[]string) func(ok bool, format string, args ...any) {
+	return func(ok bool, format string, args ...any) {
+		if !ok {
+			*problems = append(*problems, fmt.Sprintf(format, args...))
+		}
+	}
+}
+
+func (c *Config) validate() []string {
+	problems := make([]string, 0)
+
+	check := checker(&problems)
+
+	check(c.Server.Port > 0 && c.Server.Port <= 65535, "server.port (PORT) must be between 1 and 65535, got %d", c.Server.Port)
+
+	for _, timeout := range []struct {
+		name  string
+		value time.Duration
+	}{
+		{"read_timeout", c.Server.ReadTimeout},
+		{"read_header_timeout", c.Server.ReadHeaderTimeout},
+		{"write_timeout", c.Server.WriteTimeout},
+		{"idle_timeout", c.Server.IdleTimeout},
+		{"shutdown_timeout", c.Server.ShutdownTimeout},
+	} {
+		check(timeout.value > 0, "server.%s (SERVER_%s) must be positive, got %s", timeout.name, strings.ToUpper(timeout.name), timeout.value)
+	}
+	check(c.Server.Instances > 0, "server.instances (SERVER_INSTANCES) must be positive, got %d", c.Server.Instances)
+	check(c.Server.MaxHeaderBytes > 0, "server.max_header_bytes (SERVER_MAX_HEADER_BYTES) must be positive, got %d", c.Server.MaxHeaderBytes)
+
+	check((c.Server.TLSCertFile == "") == (c.Server.TLSKeyFile == ""), "server.tls_cert_file (TLS_CERT_FILE) and server.tls_key_file (TLS_KEY_FILE) must be set together")
+
+	for _, file := range []struct {
+		name string
+		path string
+	}{
+		{"tls_cert_file (TLS_CERT_FILE)", c.Server.TLSCertFile},
+		{"tls_key_file (TLS_KEY_FILE)", c.Server.TLSKeyFile},
+	} {
+		if file.path != "" {
+			_, err := os.Stat(file.path)
+			check(err == nil, "server.%s can not be read: %v", file.name, err)
+		}
+	}
+
+	problems = append(problems, c.validateDatabase()...)
+
+	check(c.Auth.JWTSecret != "", "auth.jwt_secret (JWT_SECRET) is required")
+	check(c.Auth.TokenExpiry > 0, "auth.token_expiry (TOKEN_EXPIRY_DURATION) must be positive, got %s", c.Auth.TokenExpiry)
+
+	check(c.Library.FineBlockThreshold >= 0, "library.fine_block_threshold (FINE_BLOCK_THRESHOLD) can not be negative, got %v", c.Library.FineBlockThreshold)
+	check(c.Library.HoldPickupDays > 0, "library.hold_pickup_days (HOLD_PICKUP_DAYS) must be positive, got %d", c.Library.HoldPickupDays)
+	check(c.Library.DueSoonReminderHours > 0, "library.due_soon_reminder_hours (DUE_SOON_REMINDER_HOURS) must be positive, got %d", c.Library.DueSoonReminderHours)
+
+	for _, channel := range c.Notifications.DefaultChannels {
+		switch channel {
+		case notifications.ChannelEmail, notifications.ChannelWebhook, notifications.ChannelLog:
+		default:
+			check(false, "notifications.default_channels (NOTIFY_DEFAULT_CHANNELS) has unknown channel %q", channel)
+		}
+	}
+	check(c.Notifications.MaxAttempts > 0, "notifications.max_attempts (NOTIFY_MAX_ATTEMPTS) must be positive, got %d", c.Notifications.MaxAttempts)
+
+	for _, sink := range c.Outbox.Sinks {
+		switch sink {
+		case "stdout", "nats":
+		case "webhook":
+			check(c.Outbox.WebhookURL != "", "outbox.webhook_url (OUTBOX_WEBHOOK_URL) is required for the webhook sink")
+		default:
+			check(false, "outbox.sinks (OUTBOX_SINKS) has unknown sink %q", sink)
+		}
+	}
+
+	check(c.Webhooks.MaxAttempts > 0, "webhooks.max_attempts (WEBHOOK_MAX_ATTEMPTS) must be positive, got %d", c.Webhooks.MaxAttempts)
+
+	switch c.Tracing.Exporter {
+	case tracing.ExporterNone, tracing.ExporterStdout, tracing.ExporterOTLP:
+	default:
+		check(false, "tracing.exporter (TRACING_EXPORTER) must be none, stdout or otlp, got %q", c.Tracing.Exporter)
+	}
+	check(c.Tracing.ServiceName != "", "tracing.service_name (TRACING_SERVICE_NAME) is required")
+	check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "tracing.sample_ratio (TRACING_SAMPLE_RATIO) must be between 0 and 1, got %v", c.Tracing.SampleRatio)
+
+	problems = append(problems, c.validateLogging()...)
+
+	switch c.Cache.Backend {
+	case cache.BackendNone:
+	case cache.BackendLRU:
+		check(c.Cache.Size > 0, "cache.size (CACHE_SIZE) must be positive, got %d", c.Cache.Size)
+		// An instance only drops its own entries, the others would serve stale reads until they expire.
+		check(c.Server.Instances == 1, "cache.backend (CACHE_BACKEND) lru is kept in each instance, use redis or none for %d instances", c.Server.Instances)
+	case cache.BackendRedis:
+		check(c.Cache.RedisAddr != "", "cache.redis_addr (CACHE_REDIS_ADDR) is required for the redis cache")
+		check(c.Cache.RedisDB >= 0, "cache.redis_db (CACHE_REDIS_DB) can not be negative, got %d", c.Cache.RedisDB)
+	default:
+		check(false, "cache.backend (CACHE_BACKEND) must be none, lru or redis, got %q", c.Cache.Backend)
+	}
+	check(c.Cache.TTL > 0, "cache.ttl (CACHE_TTL) must be positive, got %s", c.Cache.TTL)
+
+	switch c.RateLimit.Store {
+	case ratelimit.StoreMemory:
+		// Each instance would count its own attempts, multiplying the limits by the instances.
+		check(c.Server.Instances == 1, "rate_limit.store (RATE_LIMIT_STORE) memory is kept in each instance, use redis for %d instances", c.Server.Instances)
+	case ratelimit.StoreRedis:
+		check(c.RateLimit.RedisAddr != "", "rate_limit.redis_addr (RATE_LIMIT_REDIS_ADDR) is required for the redis store")
+		check(c.RateLimit.RedisDB >= 0, "rate_limit.redis_db (RATE_LIMIT_REDIS_DB) can not be negative, got %d", c.RateLimit.RedisDB)
+	default:
+		check(false, "rate_limit.store (RATE_LIMIT_STORE) must be memory or redis, got %q", c.RateLimit.Store)
+	}
+
+	switch c.RateLimit.OnStoreError {
+	case ratelimit.OnErrorAllow, ratelimit.OnErrorDeny, ratelimit.OnErrorMemory:
+	default:
+		check(false, "rate_limit.on_store_error (RATE_LIMIT_ON_STORE_ERROR) must be allow, deny or memory, got %q", c.RateLimit.OnStoreError)
+	}
+
+	for _, setting := range []struct {
+		name  string
+		value int
+	}{
+		{"register_ip_limit", c.RateLimit.RegisterIPLimit},
+		{"login_ip_limit", c.RateLimit.LoginIPLimit},
+		{"login_account_limit", c.RateLimit.LoginAccountLimit},
+		{"lockout_threshold", c.RateLimit.LockoutThreshold},
+	} {
+		check(setting.value > 0, "rate_limit.%s (RATE_LIMIT_%s) must be positive, got %d", setting.name, strings.ToUpper(setting.name), setting.value)
+	}
+
+	for _, setting := range []struct {
+		name  string
+		value time.Duration
+	}{
+		{"register_ip_window", c.RateLimit.RegisterIPWindow},
+		{"login_ip_window", c.RateLimit.LoginIPWindow},
+		{"login_account_window", c.RateLimit.LoginAccountWindow},
+		{"lockout_duration", c.RateLimit.LockoutDuration},
+		{"max_lockout_duration", c.RateLimit.MaxLockoutDuration},
+	} {
+		check(setting.value > 0, "rate_limit.%s (RATE_LIMIT_%s) must be positive, got %s", setting.name, strings.ToUpper(setting.name), setting.value)
+	}
+	check(c.RateLimit.MaxLockoutDuration >= c.RateLimit.LockoutDuration, "rate_limit.max_lockout_duration (RATE_LIMIT_MAX_LOCKOUT_DURATION) can not be below rate_limit.lockout_duration (RATE_LIMIT_LOCKOUT_DURATION), got %s and %s", c.RateLimit.MaxLockoutDuration, c.RateLimit.LockoutDuration)
+
+	for _, name := range slices.Sorted(maps.Keys(c.JobSchedules)) {
+		_, ok := jobs.DefaultSchedules[name]
+		check(ok, "job_schedules (%s%s) has unknown job %q, known jobs are %s", jobScheduleEnvPrefix, strings.ToUpper(strings.ReplaceAll(name, "-", "_")), name, strings.Join(slices.Sorted(maps.Keys(jobs.DefaultSchedules)), ", "))
+	}
+
+	return problems
+}
+
+func (c *Config) validateDatabase() []string {
+	problems := make([]string, 0)
+
+	check := checker(&problems)
+
+	check(c.Database.DSN != "", "database.dsn (DSN) is required")
+	check(c.Database.MaxOpenConns >= 0, "database.max_open_conns (DB_MAX_OPEN_CONNS) can not be negative, got %d", c.Database.MaxOpenConns)
+	check(c.Database.MaxIdleConns >= 0, "database.max_idle_conns (DB_MAX_IDLE_CONNS) can not be negative, got %d", c.Database.MaxIdleConns)
+	check(c.Database.MaxOpenConns == 0 || c.Database.MaxIdleConns <= c.Database.MaxOpenConns, "database.max_idle_conns (DB_MAX_IDLE_CONNS) can not be above database.max_open_conns (DB_MAX_OPEN_CONNS), got %d and %d", c.Database.MaxIdleConns, c.Database.MaxOpenConns)
+	check(c.Database.ConnMaxLifetime >= 0, "database.conn_max_lifetime (DB_CONN_MAX_LIFETIME) can not be negative, got %s", c.Database.ConnMaxLifetime)
+	check(c.Database.ConnMaxIdleTime >= 0, "database.conn_max_idle_time (DB_CONN_MAX_IDLE_TIME) can not be negative, got %s", c.Database.ConnMaxIdleTime)
+	check(c.Database.StartupTimeout > 0, "database.startup_timeout (DB_STARTUP_TIMEOUT) must be positive, got %s", c.Database.StartupTimeout)
+
+	for _, timeout := range []struct {
+		name  string
+		value time.Duration
+	}{
+		{"read", c.Database.Timeouts.Read},
+		{"list", c.Database.Timeouts.List},
+		{"write", c.Database.Timeouts.Write},
+		{"transaction", c.Database.Timeouts.Transaction},
+		{"batch", c.Database.Timeouts.Batch},
+	} {
+		check(timeout.value > 0, "database.timeouts.%s (DB_%s_TIMEOUT) must be positive, got %s", timeout.name, strings.ToUpper(timeout.name), timeout.value)
+	}
+
+	_, err := data.ParseIsolation(c.Database.TxIsolation)
+	check(err == nil, "database.tx_isolation (DB_TX_ISOLATION) must be read_committed, repeatable_read or serializable, got %q", c.Database.TxIsolation)
+	check(c.Database.TxMaxRetries >= 0, "database.tx_max_retries (DB_TX_MAX_RETRIES) can not be negative, got %d", c.Database.TxMaxRetries)
+
+	return problems
+}
+
+func (c *Config) validateLogging() []string {
+	problems := make([]string, 0)
+
+	check := checker(&problems)
+
+	_, err := logging.ParseLevel(c.Logging.Level)
+	check(err == nil, "logging.level (LOG_LEVEL) must be debug, info, warn or error, got %q", c.Logging.Level)
+	check(c.Logging.Format == logging.FormatJSON || c.Logging.Format == logging.FormatText, "logging.format (LOG_FORMAT) must be json or text, got %q", c.Logging.Format)
+
+	switch c.Logging.SQL {
+	case db.QueryLogOff, db.QueryLogSlow, db.QueryLogAll:
+	default:
+		check(false, "logging.sql (LOG_SQL) must be off, slow or all, got %q", c.Logging.SQL)
+	}
+	check(c.Logging.SlowQueryThreshold > 0, "logging.slow_query_threshold (LOG_SLOW_QUERY_THRESHOLD) must be positive, got %s", c.Logging.SlowQueryThreshold)
+
+	return problems
+}
+
+func (c *Config) TxConfig() data.TxConfig {
+	isolation, _ := data.ParseIsolation(c.Database.TxIsolation)
+
+	return data.TxConfig{Isolation: isolation, MaxRetries: c.Database.TxMaxRetries}
+}
+
+func (c *Config) NotificationSettings() notifications.Settings {
+	return notifications.Settings{
+		SMTPHost:        c.Notifications.SMTPHost,
+		SMTPPort:        c.Notifications.SMTPPort,
+		SMTPUsername:    c.Notifications.SMTPUsername,
+		SMTPPassword:    c.Notifications.SMTPPassword,
+		SMTPFrom:        c.Notifications.SMTPFrom,
+		WebhookURL:      c.Notifications.WebhookURL,
+		LogFile:         c.Notifications.LogFile,
+		DefaultChannels: c.Notifications.DefaultChannels,
+		MaxAttempts:     c.Notifications.MaxAttempts,
+	}
+}
+
+func (c *Config) SinkConfig() outbox.SinkConfig {
+	return outbox.SinkConfig{
+		Names:             c.Outbox.Sinks,
+		WebhookURL:        c.Outbox.WebhookURL,
+		NATSURL:           c.Outbox.NATSURL,
+		NATSSubjectPrefix: c.Outbox.NATSSubjectPrefix,
+	}
+}
+
+func (c *Config) TracingSettings() tracing.Settings {
+	return tracing.Settings{
+		Exporter:     c.Tracing.Exporter,
+		OTLPEndpoint: c.Tracing.OTLPEndpoint,
+		OTLPInsecure: c.Tracing.OTLPInsecure,
+		ServiceName:  c.Tracing.ServiceName,
+		SampleRatio:  c.Tracing.SampleRatio,
+	}
+}
+
+func (c *Config) LoggingSettings() logging.Settings {
+	return logging.Settings{Level: c.Logging.Level, Format: c.Logging.Format}
+}
+
+func (c *Config) DBOptions() db.Options {
+	return db.Options{
+		Pool: db.PoolConfig{
+			MaxOpenConns:    c.Database.MaxOpenConns,
+			MaxIdleConns:    c.Database.MaxIdleConns,
+			ConnMaxLifetime: c.Database.ConnMaxLifetime,
+			ConnMaxIdleTime: c.Database.ConnMaxIdleTime,
+		},
+		StartupTimeout: c.Database.StartupTimeout,
+		QueryLog:       db.QueryLogConfig{Mode: c.Logging.SQL, SlowThreshold: c.Logging.SlowQueryThreshold},
+	}
+}
+
+func (c *Config) CacheSettings() cache.Settings {
+	return cache.Settings{
+		Backend:       c.Cache.Backend,
+		Size:          c.Cache.Size,
+		RedisAddr:     c.Cache.RedisAddr,
+		RedisPassword: c.Cache.RedisPassword,
+		RedisDB:       c.Cache.RedisDB,
+	}
+}
+
+func (c *Config) RateLimitSettings() ratelimit.Settings {
+	return ratelimit.Settings{
+		Store:         c.RateLimit.Store,
+		RedisAddr:     c.RateLimit.RedisAddr,
+		RedisPassword: c.RateLimit.RedisPassword,
+		RedisDB:       c.RateLimit.RedisDB,
+		OnError:       c.RateLimit.OnStoreError,
+	}
+}
+
+func (c *Config) LoginLimits() services.LoginLimits {
+	return services.LoginLimits{
+		FailOpen:           c.RateLimit.OnStoreError == ratelimit.OnErrorAllow,
+		RegisterIPLimit:    c.RateLimit.RegisterIPLimit,
+		RegisterIPWindow:   c.RateLimit.RegisterIPWindow,
+		IPLimit:            c.RateLimit.LoginIPLimit,
+		IPWindow:           c.RateLimit.LoginIPWindow,
+		AccountLimit:       c.RateLimit.LoginAccountLimit,
+		AccountWindow:      c.RateLimit.LoginAccountWindow,
+		LockoutThreshold:   c.RateLimit.LockoutThreshold,
+		LockoutDuration:    c.RateLimit.LockoutDuration,
+		MaxLockoutDuration: c.RateLimit.MaxLockoutDuration,
+	}
+}
@@ -0,0 +1,257 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Unset every variable the config reads, so the tests do not depend on the environment they
+// run in. Empty variables are skipped like unset ones.
+func clearEnv(t *testing.T) {
+	t.Helper()
+
+	for name := range Default().envVars() {
+		t.Setenv(name, "")
+	}
+	t.Setenv("CONFIG_FILE", "")
+
+	for _, variable := range os.Environ() {
+		name, _, _ := strings.Cut(variable, "=")
+
+		if strings.HasPrefix(name, jobScheduleEnvPrefix) {
+			t.Setenv(name, "")
+		}
+	}
+}
+
+// Set the variables a valid config needs and the ones given, as NAME=value.
+func setEnv(t *testing.T, variables ...string) {
+	t.Helper()
+	clearEnv(t)
+
+	t.Setenv("DSN", "postgres://library@localhost/library")
+	t.Setenv("JWT_SECRET", "test secret")
+
+	for _, variable := range variables {
+		name, value, _ := strings.Cut(variable, "=")
+		t.Setenv(name, value)
+	}
+}
+
+func writeFile(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+
+	err := os.WriteFile(path, []byte(content), 0o600)
+
+	if err != nil {
+		t.Fatalf("could not write the config file: %v", err)
+	}
+	return path
+}
+
+func TestLoadDefaults(t *testing.T) {
+	setEnv(t)
+
+	config, err := Load(nil)
+
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	want := Default()
+	want.Database.DSN = "postgres://library@localhost/library"
+	want.Auth.JWTSecret = "test secret"
+
+	if !reflect.DeepEqual(config, want) {
+		t.Errorf("Load() = %+v, want the defaults %+v", config, want)
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		check   func(c *Config) bool
+		wantErr string
+	}{
+		{"keeps the defaults for an empty file", "", func(c *Config) bool {
+			return c.Server.Port == 8080 && c.Cache.Backend == "lru"
+		}, ""},
+		{"reads the settings", "server:\n  port: 9000\n  read_timeout: 15s\nlibrary:\n  hold_pickup_days: 5\n", func(c *Config) bool {
+			return c.Server.Port == 9000 && c.Server.ReadTimeout == 15*time.Second && c.Library.HoldPickupDays == 5
+		}, ""},
+		{"reads the job schedules", "job_schedules:\n  mark-overdue-loans: \"0 * * * *\"\n", func(c *Config) bool {
+			return c.JobSchedules["mark-overdue-loans"] == "0 * * * *"
+		}, ""},
+		{"rejects an unknown setting", "server:\n  prot: 9000\n", nil, "field prot not found"},
+		{"rejects an unknown section", "servers:\n  port: 9000\n", nil, "field servers not found"},
+		{"rejects a setting of the wrong type", "server:\n  port: eighty\n", nil, "parsing config file"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			setEnv(t)
+
+			config, err := Load([]string{"-config", writeFile(t, test.content)})
+
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Fatalf("Load() error = %v, want it to mention %q", err, test.wantErr)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+
+			if !test.check(config) {
+				t.Errorf("Load() = %+v, does not have the settings of the file", config)
+			}
+		})
+	}
+}
+
+func TestLoadEnv(t *testing.T) {
+	tests := []struct {
+		name     string
+		variable string
+		check    func(c *Config) bool
+	}{
+		{"reads a number", "HOLD_PICKUP_DAYS=5", func(c *Config) bool { return c.Library.HoldPickupDays == 5 }},
+		{"reads a duration", "CACHE_TTL=90s", func(c *Config) bool { return c.Cache.TTL == 90*time.Second }},
+		{"reads the token expiry in seconds", "TOKEN_EXPIRY_DURATION=60", func(c *Config) bool { return c.Auth.TokenExpiry == time.Minute }},
+		{"reads the token expiry as a duration", "TOKEN_EXPIRY_DURATION=2h", func(c *Config) bool { return c.Auth.TokenExpiry == 2*time.Hour }},
+		{"reads a list", "NOTIFY_DEFAULT_CHANNELS=log, webhook", func(c *Config) bool {
+			return reflect.DeepEqual(c.Notifications.DefaultChannels, []string{"log", "webhook"})
+		}},
+		{"reads a job schedule", "JOB_SCHEDULE_MARK_OVERDUE_LOANS=0 * * * *", func(c *Config) bool {
+			return c.JobSchedules["mark-overdue-loans"] == "0 * * * *"
+		}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			setEnv(t, test.variable)
+
+			config, err := Load(nil)
+
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+
+			if !test.check(config) {
+				t.Errorf("Load() with %s = %+v, does not have the setting", test.variable, config)
+			}
+		})
+	}
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     bool
+		env      bool
+		flag     bool
+		wantPort int
+		wantDSN  string
+	}{
+		{"uses the defaults", false, false, false, 8080, "postgres://default"},
+		{"file over the defaults", true, false, false, 9000, "postgres://file"},
+		{"env over the file", true, true, false, 9100, "postgres://env"},
+		{"flags over the env", true, true, true, 9200, "postgres://flag"},
+		{"flags over the defaults", false, false, true, 9200, "postgres://flag"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			setEnv(t, "DSN=postgres://default")
+			args := make([]string, 0)
+
+			if test.file {
+				args = append(args, "-config", writeFile(t, "server:\n  port: 9000\ndatabase:\n  dsn: postgres://file\n"))
+				// The variables already set would win over the file.
+				t.Setenv("DSN", "")
+			}
+
+			if test.env {
+				t.Setenv("PORT", "9100")
+				t.Setenv("DSN", "postgres://env")
+			}
+
+			if test.flag {
+				args = append(args, "-port", "9200", "-dsn", "postgres://flag")
+			}
+
+			config, err := Load(args)
+
+			if err != nil {
+				t.Fatalf("Load(%v) error = %v", args, err)
+			}
+
+			if config.Server.Port != test.wantPort || config.Database.DSN != test.wantDSN {
+				t.Errorf("Load(%v) port = %d dsn = %q, want %d and %q", args, config.Server.Port, config.Database.DSN, test.wantPort, test.wantDSN)
+			}
+		})
+	}
+}
+
+func TestLoadValidation(t *testing.T) {
+	tests := []struct {
+		name      string
+		variables []string
+		// Problems the error reports, all of them.
+		wantProblems []string
+	}{
+		{"requires the secret", []string{"JWT_SECRET="}, []string{"auth.jwt_secret (JWT_SECRET) is required"}},
+		{"reports the variables that do not parse", []string{"PORT=eighty", "CACHE_TTL=5"}, []string{
+			`PORT must be a whole number, got "eighty"`,
+			`CACHE_TTL must be a duration such as 5s or 1m, got "5"`,
+		}},
+		{"rejects the stores kept in each instance", []string{"SERVER_INSTANCES=2"}, []string{
+			"cache.backend (CACHE_BACKEND) lru is kept in each instance",
+			"rate_limit.store (RATE_LIMIT_STORE) memory is kept in each instance",
+		}},
+		{"accepts shared stores for several instances", []string{"SERVER_INSTANCES=2", "CACHE_BACKEND=redis", "RATE_LIMIT_STORE=redis"}, nil},
+		{"rejects an unknown job", []string{"JOB_SCHEDULE_MARK_OVERDUE_BOOKS=0 * * * *"}, []string{
+			`job_schedules (JOB_SCHEDULE_MARK_OVERDUE_BOOKS) has unknown job "mark-overdue-books"`,
+		}},
+		{"collects every problem", []string{"DSN=", "HOLD_PICKUP_DAYS=0", "TRACING_SAMPLE_RATIO=2", "LOG_LEVEL=loud"}, []string{
+			"database.dsn (DSN) is required",
+			"library.hold_pickup_days (HOLD_PICKUP_DAYS) must be positive, got 0",
+			"tracing.sample_ratio (TRACING_SAMPLE_RATIO) must be between 0 and 1, got 2",
+			`logging.level (LOG_LEVEL) must be debug, info, warn or error, got "loud"`,
+		}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			setEnv(t, test.variables...)
+
+			_, err := Load(nil)
+
+			if len(test.wantProblems) == 0 {
+				if err != nil {
+					t.Fatalf("Load() error = %v", err)
+				}
+				return
+			}
+
+			if err == nil {
+				t.Fatal("Load() of an invalid config succeeded")
+			}
+
+			for _, problem := range test.wantProblems {
+				if !strings.Contains(err.Error(), problem) {
+					t.Errorf("Load() error = %v, want it to report %q", err, problem)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,221 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const jobScheduleEnvPrefix = "JOB_SCHEDULE_"
+
+// Parses the value of an environment variable into its setting.
+type envSetter func(value string) error
+
+func stringVar(target *string) envSetter {
+	return func(value string) error {
+		*target = value
+		return nil
+	}
+}
+
+func intVar(target *int) envSetter {
+	return func(value string) error {
+		parsed, err := strconv.Atoi(value)
+
+		if err != nil {
+			return fmt.Errorf("must be a whole number")
+		}
+		*target = parsed
+		return nil
+	}
+}
+
+func float32Var(target *float32) envSetter {
+	return func(value string) error {
+		parsed, err := strconv.ParseFloat(value, 32)
+
+		if err != nil {
+			return fmt.Errorf("must be a number")
+		}
+		*target = float32(parsed)
+		return nil
+	}
+}
+
+func durationVar(target *time.Duration) envSetter {
+	return func(value string) error {
+		parsed, err := time.ParseDuration(value)
+
+		if err != nil {
+			return fmt.Errorf("must be a duration such as 5s or 1m")
+		}
+		*target = parsed
+		return nil
+	}
+}
+
+func float64Var(target *float64) envSetter {
+	return func(value string) error {
+		parsed, err := strconv.ParseFloat(value, 64)
+
+		if err != nil {
+			return fmt.Errorf("must be a number")
+		}
+		*target = parsed
+		return nil
+	}
+}
+
+func boolVar(target *bool) envSetter {
+	return func(value string) error {
+		parsed, err := strconv.ParseBool(value)
+
+		if err != nil {
+			return fmt.Errorf("must be true or false")
+		}
+		*target = parsed
+		return nil
+	}
+}
+
+// A duration, or a number of seconds as TOKEN_EXPIRY_DURATION has always been given.
+func secondsVar(target *time.Duration) envSetter {
+	return func(value string) error {
+		if seconds, err := strconv.Atoi(value); err == nil {
+			*target = time.Duration(seconds) * time.Second
+			return nil
+		}
+		return durationVar(target)(value)
+	}
+}
+
+// A comma separated list.
+func listVar(target *[]string) envSetter {
+	return func(value string) error {
+		items := make([]string, 0)
+
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		*target = items
+		return nil
+	}
+}
+
+func (c *Config) envVars() map[string]envSetter {
+	return map[string]envSetter{
+		"PORT":                            intVar(&c.Server.Port),
+		"SERVER_READ_TIMEOUT":             durationVar(&c.Server.ReadTimeout),
+		"SERVER_READ_HEADER_TIMEOUT":      durationVar(&c.Server.ReadHeaderTimeout),
+		"SERVER_WRITE_TIMEOUT":            durationVar(&c.Server.WriteTimeout),
+		"SERVER_IDLE_TIMEOUT":             durationVar(&c.Server.IdleTimeout),
+		"SERVER_MAX_HEADER_BYTES":         intVar(&c.Server.MaxHeaderBytes),
+		"SERVER_SHUTDOWN_TIMEOUT":         durationVar(&c.Server.ShutdownTimeout),
+		"SERVER_INSTANCES":                intVar(&c.Server.Instances),
+		"TLS_CERT_FILE":                   stringVar(&c.Server.TLSCertFile),
+		"TLS_KEY_FILE":                    stringVar(&c.Server.TLSKeyFile),
+		"DSN":                             stringVar(&c.Database.DSN),
+		"DB_REPLICA_DSN":                  stringVar(&c.Database.ReplicaDSN),
+		"DB_MAX_OPEN_CONNS":               intVar(&c.Database.MaxOpenConns),
+		"DB_MAX_IDLE_CONNS":               intVar(&c.Database.MaxIdleConns),
+		"DB_CONN_MAX_LIFETIME":            durationVar(&c.Database.ConnMaxLifetime),
+		"DB_CONN_MAX_IDLE_TIME":           durationVar(&c.Database.ConnMaxIdleTime),
+		"DB_STARTUP_TIMEOUT":              durationVar(&c.Database.StartupTimeout),
+		"DB_READ_TIMEOUT":                 durationVar(&c.Database.Timeouts.Read),
+		"DB_LIST_TIMEOUT":                 durationVar(&c.Database.Timeouts.List),
+		"DB_WRITE_TIMEOUT":                durationVar(&c.Database.Timeouts.Write),
+		"DB_TRANSACTION_TIMEOUT":          durationVar(&c.Database.Timeouts.Transaction),
+		"DB_BATCH_TIMEOUT":                durationVar(&c.Database.Timeouts.Batch),
+		"DB_TX_ISOLATION":                 stringVar(&c.Database.TxIsolation),
+		"DB_TX_MAX_RETRIES":               intVar(&c.Database.TxMaxRetries),
+		"JWT_SECRET":                      stringVar(&c.Auth.JWTSecret),
+		"TOKEN_EXPIRY_DURATION":           secondsVar(&c.Auth.TokenExpiry),
+		"FINE_BLOCK_THRESHOLD":            float32Var(&c.Library.FineBlockThreshold),
+		"HOLD_PICKUP_DAYS":                intVar(&c.Library.HoldPickupDays),
+		"DUE_SOON_REMINDER_HOURS":         intVar(&c.Library.DueSoonReminderHours),
+		"SMTP_HOST":                       stringVar(&c.Notifications.SMTPHost),
+		"SMTP_PORT":                       stringVar(&c.Notifications.SMTPPort),
+		"SMTP_USERNAME":                   stringVar(&c.Notifications.SMTPUsername),
+		"SMTP_PASSWORD":                   stringVar(&c.Notifications.SMTPPassword),
+		"SMTP_FROM":                       stringVar(&c.Notifications.SMTPFrom),
+		"NOTIFY_WEBHOOK_URL":              stringVar(&c.Notifications.WebhookURL),
+		"NOTIFY_LOG_FILE":                 stringVar(&c.Notifications.LogFile),
+		"NOTIFY_DEFAULT_CHANNELS":         listVar(&c.Notifications.DefaultChannels),
+		"NOTIFY_MAX_ATTEMPTS":             intVar(&c.Notifications.MaxAttempts),
+		"OUTBOX_SINKS":                    listVar(&c.Outbox.Sinks),
+		"OUTBOX_WEBHOOK_URL":              stringVar(&c.Outbox.WebhookURL),
+		"NATS_URL":                        stringVar(&c.Outbox.NATSURL),
+		"OUTBOX_NATS_SUBJECT_PREFIX":      stringVar(&c.Outbox.NATSSubjectPrefix),
+		"WEBHOOK_MAX_ATTEMPTS":            intVar(&c.Webhooks.MaxAttempts),
+		"TRACING_EXPORTER":                stringVar(&c.Tracing.Exporter),
+		"TRACING_OTLP_ENDPOINT":           stringVar(&c.Tracing.OTLPEndpoint),
+		"TRACING_OTLP_INSECURE":           boolVar(&c.Tracing.OTLPInsecure),
+		"TRACING_SERVICE_NAME":            stringVar(&c.Tracing.ServiceName),
+		"TRACING_SAMPLE_RATIO":            float64Var(&c.Tracing.SampleRatio),
+		"LOG_LEVEL":                       stringVar(&c.Logging.Level),
+		"LOG_FORMAT":                      stringVar(&c.Logging.Format),
+		"LOG_SQL":                         stringVar(&c.Logging.SQL),
+		"LOG_SLOW_QUERY_THRESHOLD":        durationVar(&c.Logging.SlowQueryThreshold),
+		"CACHE_BACKEND":                   stringVar(&c.Cache.Backend),
+		"CACHE_TTL":                       durationVar(&c.Cache.TTL),
+		"CACHE_SIZE":                      intVar(&c.Cache.Size),
+		"CACHE_REDIS_ADDR":                stringVar(&c.Cache.RedisAddr),
+		"CACHE_REDIS_PASSWORD":            stringVar(&c.Cache.RedisPassword),
+		"CACHE_REDIS_DB":                  intVar(&c.Cache.RedisDB),
+		"TRUSTED_PROXIES":                 listVar(&c.Server.TrustedProxies),
+		"RATE_LIMIT_STORE":                stringVar(&c.RateLimit.Store),
+		"RATE_LIMIT_REDIS_ADDR":           stringVar(&c.RateLimit.RedisAddr),
+		"RATE_LIMIT_REDIS_PASSWORD":       stringVar(&c.RateLimit.RedisPassword),
+		"RATE_LIMIT_REDIS_DB":             intVar(&c.RateLimit.RedisDB),
+		"RATE_LIMIT_ON_STORE_ERROR":       stringVar(&c.RateLimit.OnStoreError),
+		"RATE_LIMIT_REGISTER_IP_LIMIT":    intVar(&c.RateLimit.RegisterIPLimit),
+		"RATE_LIMIT_REGISTER_IP_WINDOW":   durationVar(&c.RateLimit.RegisterIPWindow),
+		"RATE_LIMIT_LOGIN_IP_LIMIT":       intVar(&c.RateLimit.LoginIPLimit),
+		"RATE_LIMIT_LOGIN_IP_WINDOW":      durationVar(&c.RateLimit.LoginIPWindow),
+		"RATE_LIMIT_LOGIN_ACCOUNT_LIMIT":  intVar(&c.RateLimit.LoginAccountLimit),
+		"RATE_LIMIT_LOGIN_ACCOUNT_WINDOW": durationVar(&c.RateLimit.LoginAccountWindow),
+		"RATE_LIMIT_LOCKOUT_THRESHOLD":    intVar(&c.RateLimit.LockoutThreshold),
+		"RATE_LIMIT_LOCKOUT_DURATION":     durationVar(&c.RateLimit.LockoutDuration),
+		"RATE_LIMIT_MAX_LOCKOUT_DURATION": durationVar(&c.RateLimit.MaxLockoutDuration),
+	}
+}
+
+// Override the settings with the environment variables that are set, returning the ones
+// that could not be parsed. JOB_SCHEDULE_<NAME> sets the schedule of a job, e.g.
+// JOB_SCHEDULE_MARK_OVERDUE_LOANS for mark-overdue-loans.
+func (c *Config) loadEnv() []string {
+	problems := make([]string, 0)
+
+	env_vars := c.envVars()
+
+	for _, name := range slices.Sorted(maps.Keys(env_vars)) {
+		value := os.Getenv(name)
+
+		if value == "" {
+			continue
+		}
+
+		if err := env_vars[name](value); err != nil {
+			problems = append(problems, fmt.Sprintf("%s %s, got %q", name, err, value))
+		}
+	}
+
+	if c.JobSchedules == nil {
+		c.JobSchedules = make(map[string]string)
+	}
+
+	for _, variable := range os.Environ() {
+		name, value, _ := strings.Cut(variable, "=")
+
+		if job, ok := strings.CutPrefix(name, jobScheduleEnvPrefix); ok && value != "" {
+			c.JobSchedules[strings.ToLower(strings.ReplaceAll(job, "_", "-"))] = value
+		}
+	}
+	return problems
+}
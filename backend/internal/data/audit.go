@@ -0,0 +1,198 @@
+package data
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/sanggonlee/gosq"
+)
+
+type AuditEntry struct {
+	ID         int64           `json:"id"`
+	ActorId    *int            `json:"actor_id"`
+	ActorEmail string          `json:"actor_email"`
+	Action     string          `json:"action"`
+	Path       string          `json:"path"`
+	EntityType string          `json:"entity_type"`
+	EntityId   string          `json:"entity_id"`
+	Before     json.RawMessage `json:"before"`
+	After      json.RawMessage `json:"after"`
+	Diff       json.RawMessage `json:"diff"`
+	IPAddress  string          `json:"ip_address"`
+	RequestId  string          `json:"request_id"`
+	StatusCode int             `json:"status_code"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+// Filters of the audit log query, zero values are not filtered on.
+type AuditFilter struct {
+	ActorId    int
+	EntityType string
+	EntityId   string
+	Action     string
+	RequestId  string
+	From       *time.Time
+	To         *time.Time
+	Limit      int
+	Offset     int
+}
+
+const auditEntryColumns = `id, actor_id, actor_email, action, path, entity_type, entity_id, before, after, diff, ip_address, request_id, status_code, created_at`
+
+// JSONB columns are nullable, a missing document is stored as null rather than as JSON null.
+func nullableJSON(document json.RawMessage) any {
+	if len(document) == 0 {
+		return nil
+	}
+	return string(document)
+}
+
+// The audit log stored in Postgres.
+type AuditEntryStore struct {
+	pgStore
+}
+
+// Record an entry in the audit log
+func (a *AuditEntryStore) InsertEntry(ctx context.Context, entry AuditEntry) error {
+	ctx, cancel := context.WithTimeout(ctx, a.timeouts.Write)
+	defer cancel()
+
+	stmt := `insert into audit_log (actor_id, actor_email, action, path, entity_type, entity_id, before, after, diff, ip_address, request_id, status_code, created_at)
+				values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13);`
+
+	_, err := a.conn(ctx).ExecContext(ctx, stmt, entry.ActorId, entry.ActorEmail, entry.Action, entry.Path, entry.EntityType, entry.EntityId,
+		nullableJSON(entry.Before), nullableJSON(entry.After), nullableJSON(entry.Diff), entry.IPAddress, entry.RequestId, entry.StatusCode, time.Now())
+
+	return err
+}
+
+// Get the audit entries matching the filter, latest first
+func (a *AuditEntryStore) QueryEntries(ctx context.Context, filter AuditFilter) ([]*AuditEntry, error) {
+	ctx, cancel := context.WithTimeout(ctx, a.timeouts.List)
+	defer cancel()
+
+	type fields struct {
+		ActorId    bool
+		EntityType bool
+		EntityId   bool
+		Action     bool
+		RequestId  bool
+		From       bool
+		To         bool
+	}
+
+	field := fields{}
+	query_args := make([]any, 0, 9)
+
+	if filter.ActorId != 0 {
+		field.ActorId = true
+		query_args = append(query_args, filter.ActorId)
+	}
+
+	if filter.EntityType != "" {
+		field.EntityType = true
+		query_args = append(query_args, filter.EntityType)
+	}
+
+	if filter.EntityId != "" {
+		field.EntityId = true
+		query_args = append(query_args, filter.EntityId)
+	}
+
+	if filter.Action != "" {
+		field.Action = true
+		query_args = append(query_args, "%"+filter.Action+"%")
+	}
+
+	if filter.RequestId != "" {
+		field.RequestId = true
+		query_args = append(query_args, filter.RequestId)
+	}
+
+	if filter.From != nil {
+		field.From = true
+		query_args = append(query_args, *filter.From)
+	}
+
+	if filter.To != nil {
+		field.To = true
+		query_args = append(query_args, *filter.To)
+	}
+
+	query, err := gosq.Compile(`
+				select `+auditEntryColumns+` from audit_log where 1=1
+				{{ [if] .ActorId [then]    and actor_id = $%d }}
+				{{ [if] .EntityType [then] and entity_type = $%d }}
+				{{ [if] .EntityId [then]   and entity_id = $%d }}
+				{{ [if] .Action [then]     and action ilike $%d }}
+				{{ [if] .RequestId [then]  and request_id = $%d }}
+				{{ [if] .From [then]       and created_at >= $%d }}
+				{{ [if] .To [then]         and created_at < $%d }}
+				order by created_at desc, id desc limit $%d offset $%d;`,
+		field)
+
+	if err != nil {
+		return nil, err
+	}
+
+	query_args = append(query_args, filter.Limit, filter.Offset)
+
+	annotation_list := make([]any, 0, len(query_args))
+
+	for i := 1; i <= len(query_args); i++ {
+		annotation_list = append(annotation_list, i)
+	}
+
+	query = fmt.Sprintf(query, annotation_list...)
+
+	rows, err := a.conn(ctx).QueryContext(ctx, query, query_args...)
+
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := make([]*AuditEntry, 0)
+
+	for rows.Next() {
+		var entry AuditEntry
+		var before, after, diff []byte
+
+		err = rows.Scan(
+			&entry.ID,
+			&entry.ActorId,
+			&entry.ActorEmail,
+			&entry.Action,
+			&entry.Path,
+			&entry.EntityType,
+			&entry.EntityId,
+			&before,
+			&after,
+			&diff,
+			&entry.IPAddress,
+			&entry.RequestId,
+			&entry.StatusCode,
+			&entry.CreatedAt,
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+		entry.Before = jsonOrNil(before)
+		entry.After = jsonOrNil(after)
+		entry.Diff = jsonOrNil(diff)
+		entries = append(entries, &entry)
+	}
+	return entries, rows.Err()
+}
+
+// A null JSONB column scans as an empty slice, which is not valid JSON to respond with.
+func jsonOrNil(document []byte) json.RawMessage {
+	if len(document) == 0 {
+		return nil
+	}
+	return document
+}
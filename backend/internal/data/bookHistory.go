@@ -0,0 +1,114 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// A revision of a book's catalog record, kept by the book_history trigger.
+type BookRevision struct {
+	BookId     int        `json:"book_id"`
+	Revision   int        `json:"revision"`
+	Title      string     `json:"title"`
+	Category   string     `json:"category"`
+	Publisher  string     `json:"publisher"`
+	Price      float32    `json:"price"`
+	FinePerDay float32    `json:"fine_per_day"`
+	AuthorId   int        `json:"author_id"`
+	Archive    bool       `json:"archive"`
+	ValidFrom  time.Time  `json:"valid_from"`
+	ValidTo    *time.Time `json:"valid_to"`
+}
+
+const bookRevisionColumns = `book_id, revision, title, category, publisher, coalesce(price, 0), coalesce(fine_per_day, 0), author_id, coalesce(archive, false), valid_from, valid_to`
+
+func scanBookRevision(row interface{ Scan(...any) error }) (*BookRevision, error) {
+	var revision BookRevision
+
+	err := row.Scan(
+		&revision.BookId,
+		&revision.Revision,
+		&revision.Title,
+		&revision.Category,
+		&revision.Publisher,
+		&revision.Price,
+		&revision.FinePerDay,
+		&revision.AuthorId,
+		&revision.Archive,
+		&revision.ValidFrom,
+		&revision.ValidTo,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+	return &revision, nil
+}
+
+// Book revisions stored in Postgres.
+type BookRevisionStore struct {
+	pgStore
+}
+
+// Get the revisions of a book, latest first
+func (r *BookRevisionStore) GetRevisions(ctx context.Context, book_id int) ([]*BookRevision, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeouts.List)
+	defer cancel()
+
+	query := `select ` + bookRevisionColumns + ` from book_history where book_id = $1 order by revision desc;`
+
+	rows, err := r.conn(ctx).QueryContext(ctx, query, book_id)
+
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	revisions := make([]*BookRevision, 0)
+
+	for rows.Next() {
+		revision, err := scanBookRevision(rows)
+
+		if err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, revision)
+	}
+	return revisions, rows.Err()
+}
+
+// Get the revision of a book in effect at a point in time
+func (r *BookRevisionStore) GetRevisionAsOf(ctx context.Context, book_id int, at time.Time) (*BookRevision, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeouts.Read)
+	defer cancel()
+
+	query := `select ` + bookRevisionColumns + ` from book_history
+				where book_id = $1 and valid_from <= $2 and (valid_to is null or valid_to > $2);`
+
+	row := r.conn(ctx).QueryRowContext(ctx, query, book_id, at)
+
+	revision, err := scanBookRevision(row)
+
+	if err == sql.ErrNoRows {
+		return nil, NotFound("book_revision_not_found", "Book with id %d did not exist on %s.", book_id, at.Format(time.RFC3339))
+	}
+	return revision, err
+}
+
+// Get a revision of a book by its number
+func (r *BookRevisionStore) GetRevision(ctx context.Context, book_id, revision_number int) (*BookRevision, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeouts.Read)
+	defer cancel()
+
+	query := `select ` + bookRevisionColumns + ` from book_history where book_id = $1 and revision = $2;`
+
+	row := r.conn(ctx).QueryRowContext(ctx, query, book_id, revision_number)
+
+	revision, err := scanBookRevision(row)
+
+	if err == sql.ErrNoRows {
+		return nil, NotFound("book_revision_not_found", "Book with id %d has no revision %d.", book_id, revision_number)
+	}
+	return revision, err
+}
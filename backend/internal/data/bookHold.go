@@ -0,0 +1,144 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+const (
+	HoldWaiting   = "waiting"
+	HoldReady     = "ready"
+	HoldCollected = "collected"
+	HoldExpired   = "expired"
+	HoldCancelled = "cancelled"
+)
+
+type BookHold struct {
+	ID        int        `json:"id"`
+	BookId    int        `json:"book_id"`
+	UserId    int        `json:"user_id"`
+	Status    string     `json:"status"`
+	ReadyAt   *time.Time `json:"ready_at"`
+	ExpiresAt *time.Time `json:"expires_at"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+const bookHoldColumns = `id, book_id, user_id, status, ready_at, expires_at, created_at, updated_at`
+
+func scanBookHold(row interface{ Scan(...any) error }) (*BookHold, error) {
+	var hold BookHold
+
+	err := row.Scan(
+		&hold.ID,
+		&hold.BookId,
+		&hold.UserId,
+		&hold.Status,
+		&hold.ReadyAt,
+		&hold.ExpiresAt,
+		&hold.CreatedAt,
+		&hold.UpdatedAt,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+	return &hold, nil
+}
+
+func scanBookHolds(rows *sql.Rows) ([]*BookHold, error) {
+	defer rows.Close()
+
+	holds := make([]*BookHold, 0)
+
+	for rows.Next() {
+		hold, err := scanBookHold(rows)
+
+		if err != nil {
+			return nil, err
+		}
+		holds = append(holds, hold)
+	}
+	return holds, rows.Err()
+}
+
+// Holds stored in Postgres.
+type BookHoldStore struct {
+	pgStore
+}
+
+// Place a hold on a book for a user
+func (h *BookHoldStore) InsertHold(ctx context.Context, book_id, user_id int) (*BookHold, error) {
+	ctx, cancel := context.WithTimeout(ctx, h.timeouts.Write)
+	defer cancel()
+
+	stmt := `insert into book_hold (book_id, user_id, status, created_at, updated_at) values ($1, $2, 'waiting', $3, $4) returning ` + bookHoldColumns + `;`
+
+	row := h.conn(ctx).QueryRowContext(ctx, stmt, book_id, user_id, time.Now(), time.Now())
+
+	return scanBookHold(row)
+}
+
+// Get a hold by its id
+func (h *BookHoldStore) GetHoldWithId(ctx context.Context, id int) (*BookHold, error) {
+	ctx, cancel := context.WithTimeout(ctx, h.timeouts.Read)
+	defer cancel()
+
+	row := h.conn(ctx).QueryRowContext(ctx, `select `+bookHoldColumns+` from book_hold where id = $1;`, id)
+
+	hold, err := scanBookHold(row)
+
+	if err == sql.ErrNoRows {
+		return nil, NotFound("hold_not_found", "Hold with id %d does not exist.", id)
+	}
+	return hold, err
+}
+
+// Get the holds of a user, latest first
+func (h *BookHoldStore) GetUserHolds(ctx context.Context, user_id int) ([]*BookHold, error) {
+	ctx, cancel := context.WithTimeout(ctx, h.timeouts.List)
+	defer cancel()
+
+	query := `select ` + bookHoldColumns + ` from book_hold where user_id = $1 order by created_at desc;`
+
+	rows, err := h.conn(ctx).QueryContext(ctx, query, user_id)
+
+	if err != nil {
+		return nil, err
+	}
+	return scanBookHolds(rows)
+}
+
+// Move a hold from one status to another, stamping the ready and expiry times
+func (h *BookHoldStore) UpdateHoldStatus(ctx context.Context, id int, from_status, to_status string, ready_at, expires_at *time.Time) (*BookHold, error) {
+	ctx, cancel := context.WithTimeout(ctx, h.timeouts.Write)
+	defer cancel()
+
+	stmt := `update book_hold set status = $1, ready_at = coalesce($2, ready_at), expires_at = coalesce($3, expires_at), updated_at = $4
+				where id = $5 and status = $6 returning ` + bookHoldColumns + `;`
+
+	row := h.conn(ctx).QueryRowContext(ctx, stmt, to_status, ready_at, expires_at, time.Now(), id, from_status)
+
+	hold, err := scanBookHold(row)
+
+	if err == sql.ErrNoRows {
+		return nil, Conflict("hold_status_conflict", "No %s hold with id %d.", from_status, id)
+	}
+	return hold, err
+}
+
+// Expire the ready holds that were not collected in time
+func (h *BookHoldStore) ExpireHolds(ctx context.Context, now time.Time) ([]*BookHold, error) {
+	ctx, cancel := context.WithTimeout(ctx, h.timeouts.Batch)
+	defer cancel()
+
+	stmt := `update book_hold set status = 'expired', updated_at = $1 where status = 'ready' and expires_at < $1 returning ` + bookHoldColumns + `;`
+
+	rows, err := h.conn(ctx).QueryContext(ctx, stmt, now)
+
+	if err != nil {
+		return nil, err
+	}
+	return scanBookHolds(rows)
+}
@@ -0,0 +1,141 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// A borrowing rule. A nil MemberType or Category matches any member type or category,
+// and a nil FinePerDay falls back to the fine_per_day of the book.
+type BorrowPolicy struct {
+	ID          int       `json:"id"`
+	MemberType  *string   `json:"member_type"`
+	Category    *string   `json:"category"`
+	LoanDays    int       `json:"loan_days"`
+	MaxRenewals int       `json:"max_renewals"`
+	MaxLoans    int       `json:"max_loans"`
+	FinePerDay  *float32  `json:"fine_per_day"`
+	MaxFine     *float32  `json:"max_fine"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+const borrowPolicyColumns = `id, member_type, category, loan_days, max_renewals, max_loans, fine_per_day, max_fine, created_at, updated_at`
+
+func scanBorrowPolicy(row interface{ Scan(...any) error }) (*BorrowPolicy, error) {
+	var policy BorrowPolicy
+
+	err := row.Scan(
+		&policy.ID,
+		&policy.MemberType,
+		&policy.Category,
+		&policy.LoanDays,
+		&policy.MaxRenewals,
+		&policy.MaxLoans,
+		&policy.FinePerDay,
+		&policy.MaxFine,
+		&policy.CreatedAt,
+		&policy.UpdatedAt,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// Borrow policies stored in Postgres.
+type BorrowPolicyStore struct {
+	pgStore
+}
+
+// Get all the borrowing rules
+func (p *BorrowPolicyStore) GetPolicies(ctx context.Context) ([]*BorrowPolicy, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.timeouts.List)
+	defer cancel()
+
+	query := `select ` + borrowPolicyColumns + ` from borrow_policy order by member_type nulls first, category nulls first;`
+
+	rows, err := p.conn(ctx).QueryContext(ctx, query)
+
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	policies := make([]*BorrowPolicy, 0)
+
+	for rows.Next() {
+		policy, err := scanBorrowPolicy(rows)
+
+		if err != nil {
+			return nil, err
+		}
+		policies = append(policies, policy)
+	}
+	return policies, rows.Err()
+}
+
+// Get a borrowing rule by its id
+func (p *BorrowPolicyStore) GetPolicyWithId(ctx context.Context, id int) (*BorrowPolicy, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.timeouts.Read)
+	defer cancel()
+
+	row := p.conn(ctx).QueryRowContext(ctx, `select `+borrowPolicyColumns+` from borrow_policy where id = $1;`, id)
+
+	policy, err := scanBorrowPolicy(row)
+
+	if err == sql.ErrNoRows {
+		return nil, NotFound("borrow_policy_not_found", "Borrow policy with id %d does not exist.", id)
+	}
+	return policy, err
+}
+
+// Create a borrowing rule
+func (p *BorrowPolicyStore) InsertPolicy(ctx context.Context, policy BorrowPolicy) (*BorrowPolicy, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.timeouts.Write)
+	defer cancel()
+
+	stmt := `insert into borrow_policy (member_type, category, loan_days, max_renewals, max_loans, fine_per_day, max_fine, created_at, updated_at) values ($1, $2, $3, $4, $5, $6, $7, $8, $9) returning ` + borrowPolicyColumns + `;`
+
+	row := p.conn(ctx).QueryRowContext(ctx, stmt, policy.MemberType, policy.Category, policy.LoanDays, policy.MaxRenewals,
+		policy.MaxLoans, policy.FinePerDay, policy.MaxFine, time.Now(), time.Now())
+
+	return scanBorrowPolicy(row)
+}
+
+// Replace the values of a borrowing rule
+func (p *BorrowPolicyStore) UpdatePolicy(ctx context.Context, id int, policy BorrowPolicy) (*BorrowPolicy, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.timeouts.Write)
+	defer cancel()
+
+	stmt := `update borrow_policy set member_type = $1, category = $2, loan_days = $3, max_renewals = $4, max_loans = $5, fine_per_day = $6, max_fine = $7, updated_at = $8 where id = $9 returning ` + borrowPolicyColumns + `;`
+
+	row := p.conn(ctx).QueryRowContext(ctx, stmt, policy.MemberType, policy.Category, policy.LoanDays, policy.MaxRenewals,
+		policy.MaxLoans, policy.FinePerDay, policy.MaxFine, time.Now(), id)
+
+	updated_policy, err := scanBorrowPolicy(row)
+
+	if err == sql.ErrNoRows {
+		return nil, NotFound("borrow_policy_not_found", "Borrow policy with id %d does not exist.", id)
+	}
+	return updated_policy, err
+}
+
+// Delete a borrowing rule
+func (p *BorrowPolicyStore) DeletePolicy(ctx context.Context, id int) error {
+	ctx, cancel := context.WithTimeout(ctx, p.timeouts.Write)
+	defer cancel()
+
+	result, err := p.conn(ctx).ExecContext(ctx, `delete from borrow_policy where id = $1;`, id)
+
+	if err != nil {
+		return err
+	}
+
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return NotFound("borrow_policy_not_found", "Borrow policy with id %d does not exist.", id)
+	}
+	return nil
+}
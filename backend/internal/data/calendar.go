@@ -0,0 +1,178 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+type LibraryHours struct {
+	Weekday   int       `json:"weekday"`
+	OpensAt   *string   `json:"opens_at"`
+	ClosesAt  *string   `json:"closes_at"`
+	IsClosed  bool      `json:"is_closed"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type LibraryClosure struct {
+	ID          int       `json:"id"`
+	ClosureDate time.Time `json:"closure_date"`
+	Recurring   bool      `json:"recurring"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Opening hours stored in Postgres.
+type LibraryHoursStore struct {
+	pgStore
+}
+
+// Get the opening hours of the week, starting on Sunday
+func (h *LibraryHoursStore) GetHours(ctx context.Context) ([]*LibraryHours, error) {
+	ctx, cancel := context.WithTimeout(ctx, h.timeouts.List)
+	defer cancel()
+
+	query := `select weekday, to_char(opens_at, 'HH24:MI'), to_char(closes_at, 'HH24:MI'), is_closed, updated_at from library_hours order by weekday;`
+
+	rows, err := h.conn(ctx).QueryContext(ctx, query)
+
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	hours := make([]*LibraryHours, 0, 7)
+
+	for rows.Next() {
+		var day LibraryHours
+
+		err = rows.Scan(&day.Weekday, &day.OpensAt, &day.ClosesAt, &day.IsClosed, &day.UpdatedAt)
+
+		if err != nil {
+			return nil, err
+		}
+		hours = append(hours, &day)
+	}
+	return hours, rows.Err()
+}
+
+// Update the opening hours of a weekday
+func (h *LibraryHoursStore) UpdateHours(ctx context.Context, day LibraryHours) (*LibraryHours, error) {
+	ctx, cancel := context.WithTimeout(ctx, h.timeouts.Write)
+	defer cancel()
+
+	var updated_day LibraryHours
+
+	stmt := `update library_hours set opens_at = $1::time, closes_at = $2::time, is_closed = $3, updated_at = $4 where weekday = $5
+				returning weekday, to_char(opens_at, 'HH24:MI'), to_char(closes_at, 'HH24:MI'), is_closed, updated_at;`
+
+	row := h.conn(ctx).QueryRowContext(ctx, stmt, day.OpensAt, day.ClosesAt, day.IsClosed, time.Now(), day.Weekday)
+
+	err := row.Scan(&updated_day.Weekday, &updated_day.OpensAt, &updated_day.ClosesAt, &updated_day.IsClosed, &updated_day.UpdatedAt)
+
+	if err == sql.ErrNoRows {
+		return nil, NotFound("weekday_not_found", "Weekday %d does not exist.", day.Weekday)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+	return &updated_day, nil
+}
+
+// Library closures stored in Postgres.
+type LibraryClosureStore struct {
+	pgStore
+}
+
+// Get all the closures ordered by date
+func (c *LibraryClosureStore) GetClosures(ctx context.Context) ([]*LibraryClosure, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeouts.List)
+	defer cancel()
+
+	query := `select id, closure_date, recurring, description, created_at from library_closure order by closure_date;`
+
+	rows, err := c.conn(ctx).QueryContext(ctx, query)
+
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	closures := make([]*LibraryClosure, 0)
+
+	for rows.Next() {
+		var closure LibraryClosure
+
+		err = rows.Scan(&closure.ID, &closure.ClosureDate, &closure.Recurring, &closure.Description, &closure.CreatedAt)
+
+		if err != nil {
+			return nil, err
+		}
+		closures = append(closures, &closure)
+	}
+	return closures, rows.Err()
+}
+
+// Get a closure by its id
+func (c *LibraryClosureStore) GetClosureWithId(ctx context.Context, id int) (*LibraryClosure, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeouts.Read)
+	defer cancel()
+
+	var closure LibraryClosure
+
+	row := c.conn(ctx).QueryRowContext(ctx, `select id, closure_date, recurring, description, created_at from library_closure where id = $1;`, id)
+
+	err := row.Scan(&closure.ID, &closure.ClosureDate, &closure.Recurring, &closure.Description, &closure.CreatedAt)
+
+	if err == sql.ErrNoRows {
+		return nil, NotFound("closure_not_found", "Closure with id %d does not exist.", id)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+	return &closure, nil
+}
+
+// Create a closure
+func (c *LibraryClosureStore) InsertClosure(ctx context.Context, closure LibraryClosure) (*LibraryClosure, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeouts.Write)
+	defer cancel()
+
+	var inserted_closure LibraryClosure
+
+	stmt := `insert into library_closure (closure_date, recurring, description, created_at) values ($1, $2, $3, $4) returning id, closure_date, recurring, description, created_at;`
+
+	row := c.conn(ctx).QueryRowContext(ctx, stmt, closure.ClosureDate, closure.Recurring, closure.Description, time.Now())
+
+	err := row.Scan(
+		&inserted_closure.ID,
+		&inserted_closure.ClosureDate,
+		&inserted_closure.Recurring,
+		&inserted_closure.Description,
+		&inserted_closure.CreatedAt,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+	return &inserted_closure, nil
+}
+
+// Delete a closure
+func (c *LibraryClosureStore) DeleteClosure(ctx context.Context, id int) error {
+	ctx, cancel := context.WithTimeout(ctx, c.timeouts.Write)
+	defer cancel()
+
+	result, err := c.conn(ctx).ExecContext(ctx, `delete from library_closure where id = $1;`, id)
+
+	if err != nil {
+		return err
+	}
+
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return NotFound("closure_not_found", "Closure with id %d does not exist.", id)
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package data
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgconn"
+)
+
+// The kind of a domain error decides the status it is answered with.
+type ErrorKind string
+
+const (
+	KindValidation   ErrorKind = "validation"
+	KindUnauthorized ErrorKind = "unauthorized"
+	KindForbidden    ErrorKind = "forbidden"
+	KindNotFound     ErrorKind = "not_found"
+	KindConflict     ErrorKind = "conflict"
+	KindPrecondition ErrorKind = "precondition_required"
+	KindRateLimited  ErrorKind = "rate_limited"
+)
+
+// An error the client can act on. Code is a stable identifier clients can match on and
+// Message is safe to show, errors of any other type are internal and never shown.
+type DomainError struct {
+	Kind    ErrorKind
+	Code    string
+	Message string
+	// Messages of the invalid request fields, keyed by their json names.
+	Fields map[string]string
+	// How long the client should wait before trying again, 0 when it does not matter.
+	RetryAfter time.Duration
+}
+
+func (e *DomainError) Error() string {
+	return e.Message
+}
+
+func newDomainError(kind ErrorKind, code string, format string, args ...any) *DomainError {
+	return &DomainError{Kind: kind, Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+func Invalid(code string, format string, args ...any) *DomainError {
+	return newDomainError(KindValidation, code, format, args...)
+}
+
+func Unauthorized(code string, format string, args ...any) *DomainError {
+	return newDomainError(KindUnauthorized, code, format, args...)
+}
+
+func Forbidden(code string, format string, args ...any) *DomainError {
+	return newDomainError(KindForbidden, code, format, args...)
+}
+
+func NotFound(code string, format string, args ...any) *DomainError {
+	return newDomainError(KindNotFound, code, format, args...)
+}
+
+func Conflict(code string, format string, args ...any) *DomainError {
+	return newDomainError(KindConflict, code, format, args...)
+}
+
+func PreconditionRequired(code string, format string, args ...any) *DomainError {
+	return newDomainError(KindPrecondition, code, format, args...)
+}
+
+func RateLimited(code string, retry_after time.Duration, format string, args ...any) *DomainError {
+	err := newDomainError(KindRateLimited, code, format, args...)
+	err.RetryAfter = retry_after
+	return err
+}
+
+// Report whether err is, or wraps, a domain error of the kind.
+func IsKind(err error, kind ErrorKind) bool {
+	domain_error, ok := AsDomainError(err)
+	return ok && domain_error.Kind == kind
+}
+
+// Postgres error codes the api answers as client errors.
+const (
+	pgUniqueViolation     = "23505"
+	pgForeignKeyViolation = "23503"
+	pgCheckViolation      = "23514"
+)
+
+// Find the domain error behind err. Constraint violations raised by the database are domain
+// errors too, any other error is internal.
+func AsDomainError(err error) (*DomainError, bool) {
+	var domain_error *DomainError
+
+	if errors.As(err, &domain_error) {
+		return domain_error, true
+	}
+
+	var pg_error *pgconn.PgError
+
+	if !errors.As(err, &pg_error) {
+		return nil, false
+	}
+
+	switch pg_error.Code {
+	case pgUniqueViolation:
+		return Conflict("duplicate_record", "The record conflicts with an existing one."), true
+	case pgForeignKeyViolation:
+		return Conflict("invalid_reference", "The record refers to, or is referred by, a record that does not exist."), true
+	case pgCheckViolation:
+		return Invalid("constraint_violation", "The record has a value outside of the allowed range."), true
+	case pgSerializationFailure:
+		// Still failing after the unit of work ran out of retries.
+		return Conflict("concurrent_update", "The records were changed by a concurrent request, please try again."), true
+	default:
+		return nil, false
+	}
+}
@@ -0,0 +1,179 @@
+package data
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	FineEntryCharge  = "charge"
+	FineEntryPayment = "payment"
+	FineEntryWaiver  = "waiver"
+	FineEntryRefund  = "refund"
+)
+
+var fineEntryEvents = map[string]string{
+	FineEntryCharge:  EventFineCharged,
+	FineEntryPayment: EventFinePaid,
+	FineEntryWaiver:  EventFineWaived,
+	FineEntryRefund:  EventFineRefunded,
+}
+
+type FineEntry struct {
+	ID         int       `json:"id"`
+	UserId     int       `json:"user_id"`
+	BorrowId   *int      `json:"borrow_id"`
+	EntryType  string    `json:"entry_type"`
+	Amount     float32   `json:"amount"`
+	Note       string    `json:"note"`
+	RecordedBy *int      `json:"recorded_by"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type FineStatementLine struct {
+	FineEntry
+	Balance float32 `json:"balance"`
+}
+
+type FineStatement struct {
+	UserId  int                  `json:"user_id"`
+	Balance float32              `json:"balance"`
+	Entries []*FineStatementLine `json:"entries"`
+}
+
+// Signed effect of a ledger entry on the amount owed by the member. A refund gives paid
+// money back, it is recorded with a waiver of the same amount that cancels its effect.
+func (f *FineEntry) BalanceEffect() float32 {
+	switch f.EntryType {
+	case FineEntryCharge, FineEntryRefund:
+		return f.Amount
+	default:
+		return -f.Amount
+	}
+}
+
+// The fine ledger stored in Postgres.
+type FineEntryStore struct {
+	pgStore
+}
+
+// Add an entry to the fine ledger
+func (f *FineEntryStore) InsertFineEntry(ctx context.Context, entry FineEntry) (*FineEntry, error) {
+	ctx, cancel := context.WithTimeout(ctx, f.timeouts.Transaction)
+	defer cancel()
+
+	tx, err := f.begin(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var inserted_entry FineEntry
+
+	stmt := `insert into fine_ledger (user_id, borrow_id, entry_type, amount, note, recorded_by, created_at) values ($1, $2, $3, $4, $5, $6, $7) returning id, user_id, borrow_id, entry_type, amount, note, recorded_by, created_at;`
+
+	row := tx.QueryRowContext(ctx, stmt, entry.UserId, entry.BorrowId, entry.EntryType, entry.Amount, entry.Note, entry.RecordedBy, time.Now())
+
+	err = row.Scan(
+		&inserted_entry.ID,
+		&inserted_entry.UserId,
+		&inserted_entry.BorrowId,
+		&inserted_entry.EntryType,
+		&inserted_entry.Amount,
+		&inserted_entry.Note,
+		&inserted_entry.RecordedBy,
+		&inserted_entry.CreatedAt,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = insertOutboxEvent(ctx, tx, fineEntryEvents[inserted_entry.EntryType], "fine_entry", inserted_entry.ID, inserted_entry)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+
+	if err != nil {
+		return nil, err
+	}
+	return &inserted_entry, nil
+}
+
+// Get the outstanding fine balance of a user
+func (f *FineEntryStore) GetUserBalance(ctx context.Context, user_id int) (float32, error) {
+	ctx, cancel := context.WithTimeout(ctx, f.timeouts.Read)
+	defer cancel()
+
+	var balance float32
+
+	query := `select coalesce(sum(case when entry_type in ('charge', 'refund') then amount else -amount end), 0) from fine_ledger where user_id = $1;`
+
+	row := f.conn(ctx).QueryRowContext(ctx, query, user_id)
+	err := row.Scan(&balance)
+
+	if err != nil {
+		return 0, err
+	}
+	return balance, nil
+}
+
+// Get the total amount paid by a user net of refunds
+func (f *FineEntryStore) GetUserNetPaid(ctx context.Context, user_id int) (float32, error) {
+	ctx, cancel := context.WithTimeout(ctx, f.timeouts.Read)
+	defer cancel()
+
+	var net_paid float32
+
+	query := `select coalesce(sum(case when entry_type = 'payment' then amount else -amount end), 0) from fine_ledger where user_id = $1 and entry_type in ('payment', 'refund');`
+
+	row := f.conn(ctx).QueryRowContext(ctx, query, user_id)
+	err := row.Scan(&net_paid)
+
+	if err != nil {
+		return 0, err
+	}
+	return net_paid, nil
+}
+
+// Get the ledger entries of a user, oldest first
+func (f *FineEntryStore) GetUserEntries(ctx context.Context, user_id int) ([]*FineEntry, error) {
+	ctx, cancel := context.WithTimeout(ctx, f.timeouts.List)
+	defer cancel()
+
+	query := `select id, user_id, borrow_id, entry_type, amount, note, recorded_by, created_at from fine_ledger where user_id = $1 order by created_at, id;`
+
+	rows, err := f.conn(ctx).QueryContext(ctx, query, user_id)
+
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := make([]*FineEntry, 0)
+
+	for rows.Next() {
+		var entry FineEntry
+
+		err = rows.Scan(
+			&entry.ID,
+			&entry.UserId,
+			&entry.BorrowId,
+			&entry.EntryType,
+			&entry.Amount,
+			&entry.Note,
+			&entry.RecordedBy,
+			&entry.CreatedAt,
+		)
+
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, &entry)
+	}
+	return entries, rows.Err()
+}
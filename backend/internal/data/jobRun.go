@@ -0,0 +1,101 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+const (
+	JobRunRunning   = "running"
+	JobRunSucceeded = "succeeded"
+	JobRunFailed    = "failed"
+)
+
+// Returned when another instance already ran the scheduled slot of the job.
+var ErrJobRunClaimed = errors.New("job run already claimed")
+
+type JobRun struct {
+	ID          int        `json:"id"`
+	JobName     string     `json:"job_name"`
+	Instance    string     `json:"instance"`
+	Status      string     `json:"status"`
+	Error       string     `json:"error"`
+	ScheduledAt *time.Time `json:"scheduled_at"`
+	StartedAt   time.Time  `json:"started_at"`
+	FinishedAt  *time.Time `json:"finished_at"`
+}
+
+// Job runs stored in Postgres.
+type JobRunStore struct {
+	pgStore
+}
+
+// Record the start of the run of the job scheduled at scheduled_at, ErrJobRunClaimed when the
+// slot already has a run.
+func (j *JobRunStore) InsertJobRun(ctx context.Context, job_name, instance string, scheduled_at time.Time) (*JobRun, error) {
+	ctx, cancel := context.WithTimeout(ctx, j.timeouts.Write)
+	defer cancel()
+
+	var run JobRun
+
+	stmt := `insert into job_run (job_name, instance, status, scheduled_at, started_at) values ($1, $2, 'running', $3, $4)
+				on conflict (job_name, scheduled_at) do nothing
+				returning id, job_name, instance, status, error, scheduled_at, started_at, finished_at;`
+
+	row := j.conn(ctx).QueryRowContext(ctx, stmt, job_name, instance, scheduled_at.UTC(), time.Now())
+
+	err := row.Scan(&run.ID, &run.JobName, &run.Instance, &run.Status, &run.Error, &run.ScheduledAt, &run.StartedAt, &run.FinishedAt)
+
+	if err == sql.ErrNoRows {
+		return nil, ErrJobRunClaimed
+	}
+
+	if err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+// Record the outcome of a job run
+func (j *JobRunStore) FinishJobRun(ctx context.Context, id int, status, error_message string) error {
+	ctx, cancel := context.WithTimeout(ctx, j.timeouts.Write)
+	defer cancel()
+
+	stmt := `update job_run set status = $1, error = $2, finished_at = $3 where id = $4;`
+
+	_, err := j.conn(ctx).ExecContext(ctx, stmt, status, error_message, time.Now(), id)
+
+	return err
+}
+
+// Get the latest runs, of a single job when job_name is given
+func (j *JobRunStore) GetJobRuns(ctx context.Context, job_name string, limit int) ([]*JobRun, error) {
+	ctx, cancel := context.WithTimeout(ctx, j.timeouts.List)
+	defer cancel()
+
+	query := `select id, job_name, instance, status, error, scheduled_at, started_at, finished_at from job_run
+				where ($1 = '' or job_name = $1) order by started_at desc limit $2;`
+
+	rows, err := j.conn(ctx).QueryContext(ctx, query, job_name, limit)
+
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	runs := make([]*JobRun, 0)
+
+	for rows.Next() {
+		var run JobRun
+
+		err = rows.Scan(&run.ID, &run.JobName, &run.Instance, &run.Status, &run.Error, &run.ScheduledAt, &run.StartedAt, &run.FinishedAt)
+
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, &run)
+	}
+	return runs, rows.Err()
+}
@@ -0,0 +1,72 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/data"
+)
+
+// The audit log kept in memory.
+type AuditEntries struct {
+	*Store
+}
+
+func (a *AuditEntries) InsertEntry(ctx context.Context, entry data.AuditEntry) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	inserted_entry := entry
+	inserted_entry.ID = int64(a.newId())
+	inserted_entry.CreatedAt = time.Now()
+	a.auditEntries[inserted_entry.ID] = inserted_entry
+
+	return nil
+}
+
+func (a *AuditEntries) QueryEntries(ctx context.Context, filter data.AuditFilter) ([]*data.AuditEntry, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	entries := make([]*data.AuditEntry, 0)
+
+	for _, entry := range a.auditEntries {
+		if matchesAuditFilter(entry, filter) {
+			entries = append(entries, &entry)
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if !entries[i].CreatedAt.Equal(entries[j].CreatedAt) {
+			return entries[i].CreatedAt.After(entries[j].CreatedAt)
+		}
+		return entries[i].ID > entries[j].ID
+	})
+
+	start := min(filter.Offset, len(entries))
+	end := min(start+filter.Limit, len(entries))
+
+	return entries[start:end], nil
+}
+
+func matchesAuditFilter(entry data.AuditEntry, filter data.AuditFilter) bool {
+	switch {
+	case filter.ActorId != 0 && (entry.ActorId == nil || *entry.ActorId != filter.ActorId):
+		return false
+	case filter.EntityType != "" && entry.EntityType != filter.EntityType:
+		return false
+	case filter.EntityId != "" && entry.EntityId != filter.EntityId:
+		return false
+	case filter.Action != "" && !containsFold(entry.Action, filter.Action):
+		return false
+	case filter.RequestId != "" && entry.RequestId != filter.RequestId:
+		return false
+	case filter.From != nil && entry.CreatedAt.Before(*filter.From):
+		return false
+	case filter.To != nil && !entry.CreatedAt.Before(*filter.To):
+		return false
+	default:
+		return true
+	}
+}
@@ -0,0 +1,100 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/data"
+)
+
+// Authors kept in memory.
+type Authors struct {
+	*Store
+}
+
+func (a *Authors) GetAuthorWithId(ctx context.Context, id int) (data.Author, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	author, ok := a.authors[id]
+
+	if !ok {
+		return author, data.NotFound("author_not_found", "Author with id %d does not exist.", id)
+	}
+	return author, nil
+}
+
+func (a *Authors) GetAuthorWithDetails(ctx context.Context, name string) ([]data.Author, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var authors []data.Author
+
+	for _, author := range a.authors {
+		if containsFold(author.Name, name) {
+			authors = append(authors, author)
+		}
+	}
+
+	sort.Slice(authors, func(i, j int) bool {
+		return newerFirst(authors[i].CreatedAt, authors[j].CreatedAt, authors[i].ID, authors[j].ID)
+	})
+	return authors, nil
+}
+
+func (a *Authors) InsertAuthor(ctx context.Context, author data.Author) (*data.Author, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	now := time.Now()
+
+	added_author := data.Author{
+		ID:        a.newId(),
+		Name:      author.Name,
+		About:     author.About,
+		CreatedAt: now,
+		UpdatedAt: now,
+		Version:   1,
+	}
+	a.authors[added_author.ID] = added_author
+
+	return &added_author, nil
+}
+
+func (a *Authors) UpdateAuthor(ctx context.Context, id int, version int, author data.Author) (*data.Author, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	updated_author, ok := a.authors[id]
+
+	if !ok {
+		return nil, data.NotFound("author_not_found", "Author with id %d does not exist.", id)
+	}
+
+	if updated_author.Version != version {
+		return nil, data.ErrEditConflict
+	}
+
+	updated_author.Name = author.Name
+	updated_author.About = author.About
+	updated_author.UpdatedAt = time.Now()
+	updated_author.Version++
+	a.authors[id] = updated_author
+
+	return &updated_author, nil
+}
+
+// Case insensitive substring match, like ilike '%sub%'. An empty sub matches everything.
+func containsFold(s, sub string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(sub))
+}
+
+// Order of "order by created_at desc", with the id breaking ties between records created together.
+func newerFirst(created_i, created_j time.Time, id_i, id_j int) bool {
+	if !created_i.Equal(created_j) {
+		return created_i.After(created_j)
+	}
+	return id_i > id_j
+}
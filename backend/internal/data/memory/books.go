@@ -0,0 +1,149 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/data"
+)
+
+// Books kept in memory. Categories are not checked, there is no category table.
+type Books struct {
+	*Store
+}
+
+func (b *Books) InsertBook(ctx context.Context, book data.Book) (*data.Book, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.authors[book.AuthorId]; !ok {
+		return nil, data.Invalid("unknown_author", "Author with id %d does not exist.", book.AuthorId)
+	}
+
+	now := time.Now()
+
+	inserted_book := book
+	inserted_book.ID = b.newId()
+	inserted_book.CreatedAt = now
+	inserted_book.UpdatedAt = now
+	inserted_book.Archive = false
+	inserted_book.Version = 1
+	b.books[inserted_book.ID] = inserted_book
+
+	b.addRevision(inserted_book, now)
+	b.addEvent(data.EventBookCreated, "book", inserted_book.ID, inserted_book)
+
+	return &inserted_book, nil
+}
+
+func (b *Books) UpdateBook(ctx context.Context, book_id int, version int, update data.BookUpdate) (*data.Book, error) {
+	if update == (data.BookUpdate{}) {
+		return nil, data.Invalid("empty_update", "Nothing to update for book with id %d.", book_id)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	book, ok := b.books[book_id]
+
+	if !ok {
+		return nil, data.NotFound("book_not_found", "Book with id %d does not exist.", book_id)
+	}
+	before := book
+
+	if book.Version != version {
+		return nil, data.ErrEditConflict
+	}
+
+	if update.AuthorId != nil {
+		if _, ok := b.authors[*update.AuthorId]; !ok {
+			return nil, data.Conflict("invalid_reference", "The record refers to, or is referred by, a record that does not exist.")
+		}
+		book.AuthorId = *update.AuthorId
+	}
+
+	if update.Title != nil {
+		book.Title = *update.Title
+	}
+
+	if update.Category != nil {
+		book.Category = *update.Category
+	}
+
+	if update.Publisher != nil {
+		book.Publisher = *update.Publisher
+	}
+
+	if update.BookCount != nil {
+		book.BookCount = *update.BookCount
+	}
+
+	if update.Price != nil {
+		book.Price = *update.Price
+	}
+
+	if update.FinePerDay != nil {
+		book.FinePerDay = *update.FinePerDay
+	}
+
+	book.UpdatedAt = time.Now()
+	book.Version++
+	b.books[book_id] = book
+
+	if revisedBook(before, book) {
+		b.addRevision(book, book.UpdatedAt)
+	}
+
+	return &book, nil
+}
+
+func (b *Books) GetBookWithId(ctx context.Context, id int) (*data.Book, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	book, ok := b.books[id]
+
+	if !ok {
+		return nil, data.NotFound("book_not_found", "Book with id %d does not exist.", id)
+	}
+	return &book, nil
+}
+
+func (b *Books) GetBook(ctx context.Context, filter data.BookFilter) ([]*data.Book_with_name, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	results := make([]*data.Book_with_name, 0)
+
+	for _, book := range b.books {
+		author, ok := b.authors[book.AuthorId]
+
+		if !ok {
+			continue
+		}
+
+		if !containsFold(book.Title, filter.Title) || !containsFold(book.Category, filter.Category) ||
+			!containsFold(book.Publisher, filter.Publisher) || !containsFold(author.Name, filter.AuthorName) {
+			continue
+		}
+
+		results = append(results, &data.Book_with_name{
+			ID:         book.ID,
+			Title:      book.Title,
+			Category:   book.Category,
+			Publisher:  book.Publisher,
+			Price:      book.Price,
+			FinePerDay: book.FinePerDay,
+			BookCount:  book.BookCount,
+			AuthorName: author.Name,
+			CreatedAt:  book.CreatedAt,
+			UpdatedAt:  book.UpdatedAt,
+		})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return newerFirst(results[i].CreatedAt, results[j].CreatedAt, results[i].ID, results[j].ID)
+	})
+	return results, nil
+}
@@ -0,0 +1,121 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/data"
+)
+
+// The hours the migration creates the library with, open 09:00 to 18:00 but on Sundays.
+func (s *Store) seedHours() {
+	opens_at, closes_at := "09:00", "18:00"
+	now := time.Now()
+
+	for weekday := 0; weekday <= 6; weekday++ {
+		day := data.LibraryHours{Weekday: weekday, IsClosed: weekday == 0, UpdatedAt: now}
+
+		if !day.IsClosed {
+			day.OpensAt, day.ClosesAt = &opens_at, &closes_at
+		}
+		s.hours[weekday] = day
+	}
+}
+
+// Opening hours kept in memory.
+type LibraryHours struct {
+	*Store
+}
+
+func (h *LibraryHours) GetHours(ctx context.Context) ([]*data.LibraryHours, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	hours := make([]*data.LibraryHours, 0, 7)
+
+	for weekday := 0; weekday <= 6; weekday++ {
+		if day, ok := h.hours[weekday]; ok {
+			hours = append(hours, &day)
+		}
+	}
+	return hours, nil
+}
+
+func (h *LibraryHours) UpdateHours(ctx context.Context, day data.LibraryHours) (*data.LibraryHours, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, ok := h.hours[day.Weekday]; !ok {
+		return nil, data.NotFound("weekday_not_found", "Weekday %d does not exist.", day.Weekday)
+	}
+
+	updated_day := day
+	updated_day.UpdatedAt = time.Now()
+	h.hours[day.Weekday] = updated_day
+
+	return &updated_day, nil
+}
+
+// Closures kept in memory. Dates are unique like the table makes them.
+type LibraryClosures struct {
+	*Store
+}
+
+func (c *LibraryClosures) GetClosures(ctx context.Context) ([]*data.LibraryClosure, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	closures := make([]*data.LibraryClosure, 0, len(c.closures))
+
+	for _, closure := range c.closures {
+		closures = append(closures, &closure)
+	}
+
+	sort.Slice(closures, func(i, j int) bool {
+		return closures[i].ClosureDate.Before(closures[j].ClosureDate)
+	})
+	return closures, nil
+}
+
+func (c *LibraryClosures) GetClosureWithId(ctx context.Context, id int) (*data.LibraryClosure, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	closure, ok := c.closures[id]
+
+	if !ok {
+		return nil, data.NotFound("closure_not_found", "Closure with id %d does not exist.", id)
+	}
+	return &closure, nil
+}
+
+func (c *LibraryClosures) InsertClosure(ctx context.Context, closure data.LibraryClosure) (*data.LibraryClosure, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, existing_closure := range c.closures {
+		if existing_closure.ClosureDate.Equal(closure.ClosureDate) {
+			return nil, data.Conflict("duplicate_record", "The record conflicts with an existing one.")
+		}
+	}
+
+	inserted_closure := closure
+	inserted_closure.ID = c.newId()
+	inserted_closure.CreatedAt = time.Now()
+	c.closures[inserted_closure.ID] = inserted_closure
+
+	return &inserted_closure, nil
+}
+
+func (c *LibraryClosures) DeleteClosure(ctx context.Context, id int) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.closures[id]; !ok {
+		return data.NotFound("closure_not_found", "Closure with id %d does not exist.", id)
+	}
+	delete(c.closures, id)
+
+	return nil
+}
@@ -0,0 +1,105 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/data"
+)
+
+var fineEntryEvents = map[string]string{
+	data.FineEntryCharge:  data.EventFineCharged,
+	data.FineEntryPayment: data.EventFinePaid,
+	data.FineEntryWaiver:  data.EventFineWaived,
+	data.FineEntryRefund:  data.EventFineRefunded,
+}
+
+// The fine ledger kept in memory.
+type FineEntries struct {
+	*Store
+}
+
+func (f *FineEntries) InsertFineEntry(ctx context.Context, entry data.FineEntry) (*data.FineEntry, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, ok := fineEntryEvents[entry.EntryType]; !ok || entry.Amount <= 0 {
+		return nil, data.Invalid("constraint_violation", "The record has a value outside of the allowed range.")
+	}
+
+	if _, ok := f.users[entry.UserId]; !ok {
+		return nil, data.Conflict("invalid_reference", "The record refers to, or is referred by, a record that does not exist.")
+	}
+
+	if entry.BorrowId != nil {
+		if _, ok := f.items[*entry.BorrowId]; !ok {
+			return nil, data.Conflict("invalid_reference", "The record refers to, or is referred by, a record that does not exist.")
+		}
+	}
+
+	inserted_entry := entry
+	inserted_entry.ID = f.newId()
+	inserted_entry.CreatedAt = time.Now()
+	f.fines[inserted_entry.ID] = inserted_entry
+
+	f.addEvent(fineEntryEvents[inserted_entry.EntryType], "fine_entry", inserted_entry.ID, inserted_entry)
+
+	return &inserted_entry, nil
+}
+
+func (f *FineEntries) GetUserBalance(ctx context.Context, user_id int) (float32, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var balance float32
+
+	for _, entry := range f.fines {
+		if entry.UserId == user_id {
+			balance += entry.BalanceEffect()
+		}
+	}
+	return balance, nil
+}
+
+func (f *FineEntries) GetUserNetPaid(ctx context.Context, user_id int) (float32, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var net_paid float32
+
+	for _, entry := range f.fines {
+		if entry.UserId != user_id {
+			continue
+		}
+
+		switch entry.EntryType {
+		case data.FineEntryPayment:
+			net_paid += entry.Amount
+		case data.FineEntryRefund:
+			net_paid -= entry.Amount
+		}
+	}
+	return net_paid, nil
+}
+
+func (f *FineEntries) GetUserEntries(ctx context.Context, user_id int) ([]*data.FineEntry, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	entries := make([]*data.FineEntry, 0)
+
+	for _, entry := range f.fines {
+		if entry.UserId == user_id {
+			entries = append(entries, &entry)
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if !entries[i].CreatedAt.Equal(entries[j].CreatedAt) {
+			return entries[i].CreatedAt.Before(entries[j].CreatedAt)
+		}
+		return entries[i].ID < entries[j].ID
+	})
+	return entries, nil
+}
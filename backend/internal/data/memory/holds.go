@@ -0,0 +1,130 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/data"
+)
+
+// Holds kept in memory. Ready holds are collected with the loan of the book.
+type BookHolds struct {
+	*Store
+}
+
+func (h *BookHolds) InsertHold(ctx context.Context, book_id, user_id int) (*data.BookHold, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	_, book_ok := h.books[book_id]
+	_, user_ok := h.users[user_id]
+
+	if !book_ok || !user_ok {
+		return nil, data.Conflict("invalid_reference", "The record refers to, or is referred by, a record that does not exist.")
+	}
+
+	now := time.Now()
+
+	hold := data.BookHold{
+		ID:        h.newId(),
+		BookId:    book_id,
+		UserId:    user_id,
+		Status:    data.HoldWaiting,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	h.holds[hold.ID] = hold
+
+	return &hold, nil
+}
+
+func (h *BookHolds) GetHoldWithId(ctx context.Context, id int) (*data.BookHold, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	hold, ok := h.holds[id]
+
+	if !ok {
+		return nil, data.NotFound("hold_not_found", "Hold with id %d does not exist.", id)
+	}
+	return &hold, nil
+}
+
+func (h *BookHolds) GetUserHolds(ctx context.Context, user_id int) ([]*data.BookHold, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	holds := make([]*data.BookHold, 0)
+
+	for _, hold := range h.holds {
+		if hold.UserId == user_id {
+			holds = append(holds, &hold)
+		}
+	}
+
+	sort.Slice(holds, func(i, j int) bool {
+		return newerFirst(holds[i].CreatedAt, holds[j].CreatedAt, holds[i].ID, holds[j].ID)
+	})
+	return holds, nil
+}
+
+func (h *BookHolds) UpdateHoldStatus(ctx context.Context, id int, from_status, to_status string, ready_at, expires_at *time.Time) (*data.BookHold, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	hold, ok := h.holds[id]
+
+	if !ok || hold.Status != from_status {
+		return nil, data.Conflict("hold_status_conflict", "No %s hold with id %d.", from_status, id)
+	}
+
+	hold.Status = to_status
+
+	if ready_at != nil {
+		hold.ReadyAt = ready_at
+	}
+
+	if expires_at != nil {
+		hold.ExpiresAt = expires_at
+	}
+	hold.UpdatedAt = time.Now()
+	h.holds[id] = hold
+
+	return &hold, nil
+}
+
+func (h *BookHolds) ExpireHolds(ctx context.Context, now time.Time) ([]*data.BookHold, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	holds := make([]*data.BookHold, 0)
+
+	for id, hold := range h.holds {
+		if hold.Status != data.HoldReady || hold.ExpiresAt == nil || !hold.ExpiresAt.Before(now) {
+			continue
+		}
+
+		hold.Status = data.HoldExpired
+		hold.UpdatedAt = now
+		h.holds[id] = hold
+		holds = append(holds, &hold)
+	}
+
+	sort.Slice(holds, func(i, j int) bool {
+		return holds[i].ID < holds[j].ID
+	})
+	return holds, nil
+}
+
+// Collect the ready hold of the user on the book, as lending it does.
+// Must be called with the lock held.
+func (s *Store) collectHold(user_id, book_id int, now time.Time) {
+	for id, hold := range s.holds {
+		if hold.UserId == user_id && hold.BookId == book_id && hold.Status == data.HoldReady {
+			hold.Status = data.HoldCollected
+			hold.UpdatedAt = now
+			s.holds[id] = hold
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/data"
+)
+
+// Job runs kept in memory. A slot of a job has a single run, like the unique index of the
+// table makes it.
+type JobRuns struct {
+	*Store
+}
+
+func (j *JobRuns) InsertJobRun(ctx context.Context, job_name, instance string, scheduled_at time.Time) (*data.JobRun, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	scheduled_at = scheduled_at.UTC()
+
+	for _, run := range j.jobRuns {
+		if run.JobName == job_name && run.ScheduledAt != nil && run.ScheduledAt.Equal(scheduled_at) {
+			return nil, data.ErrJobRunClaimed
+		}
+	}
+
+	run := data.JobRun{
+		ID:          j.newId(),
+		JobName:     job_name,
+		Instance:    instance,
+		Status:      data.JobRunRunning,
+		ScheduledAt: &scheduled_at,
+		StartedAt:   time.Now(),
+	}
+	j.jobRuns[run.ID] = run
+
+	return &run, nil
+}
+
+func (j *JobRuns) FinishJobRun(ctx context.Context, id int, status, error_message string) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	run, ok := j.jobRuns[id]
+
+	if !ok {
+		return nil
+	}
+
+	finished_at := time.Now()
+
+	run.Status = status
+	run.Error = error_message
+	run.FinishedAt = &finished_at
+	j.jobRuns[id] = run
+
+	return nil
+}
+
+func (j *JobRuns) GetJobRuns(ctx context.Context, job_name string, limit int) ([]*data.JobRun, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	runs := make([]*data.JobRun, 0)
+
+	for _, run := range j.jobRuns {
+		if job_name == "" || run.JobName == job_name {
+			runs = append(runs, &run)
+		}
+	}
+
+	sort.Slice(runs, func(i, j int) bool {
+		return newerFirst(runs[i].StartedAt, runs[j].StartedAt, runs[i].ID, runs[j].ID)
+	})
+	return runs[:min(limit, len(runs))], nil
+}
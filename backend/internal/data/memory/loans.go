@@ -0,0 +1,252 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/data"
+)
+
+// Borrow lists and their items kept in memory.
+type Loans struct {
+	*Store
+}
+
+func (l *Loans) CreateBookBorrowList(ctx context.Context, borrow_list data.BookBorrowList) (*data.BookBorrowList, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if _, ok := l.users[borrow_list.UserId]; !ok {
+		return nil, data.Conflict("invalid_reference", "The record refers to, or is referred by, a record that does not exist.")
+	}
+
+	// Check every book first, nothing is written when one is unavailable.
+	taken := make(map[int]int)
+
+	for _, item := range borrow_list.BookList {
+		book, ok := l.books[item.BookId]
+
+		if !ok || book.Archive || book.BookCount-taken[item.BookId] <= 0 {
+			return nil, data.Conflict("book_unavailable", "Book with id %d is not available for lending.", item.BookId)
+		}
+		taken[item.BookId]++
+	}
+
+	now := time.Now()
+
+	created_list := data.BookBorrowList{
+		ID:        l.newId(),
+		DueDate:   borrow_list.DueDate,
+		UserId:    borrow_list.UserId,
+		CreateAt:  now,
+		UpdatedAt: now,
+	}
+	l.lists[created_list.ID] = created_list
+
+	for _, item := range borrow_list.BookList {
+		book := l.books[item.BookId]
+		book.BookCount--
+		l.books[item.BookId] = book
+
+		created_item := data.BookBorrorw{
+			ID:           l.newId(),
+			BookId:       item.BookId,
+			ListId:       created_list.ID,
+			DueDate:      item.DueDate,
+			FinePerDay:   item.FinePerDay,
+			MaxFine:      item.MaxFine,
+			BookRevision: item.BookRevision,
+		}
+		l.items[created_item.ID] = created_item
+		created_list.BookList = append(created_list.BookList, &created_item)
+
+		// A hold waiting at the desk for this member is collected with the loan.
+		l.collectHold(created_list.UserId, item.BookId, now)
+	}
+	l.addEvent(data.EventLoanCreated, "loan", created_list.ID, created_list)
+
+	return &created_list, nil
+}
+
+func (l *Loans) GetBookBorrow(ctx context.Context, id int) (*data.BookBorrorw, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	item, ok := l.items[id]
+
+	if !ok {
+		return nil, data.NotFound("loan_not_found", "Borrowed item with id %d does not exist.", id)
+	}
+	return &item, nil
+}
+
+func (l *Loans) GetBookBorrowList(ctx context.Context, id int) (*data.BookBorrowList, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	borrow_list, ok := l.lists[id]
+
+	if !ok {
+		return nil, data.NotFound("loan_not_found", "Borrow list with id %d does not exist.", id)
+	}
+	return &borrow_list, nil
+}
+
+func (l *Loans) GetOpenLoanCounts(ctx context.Context, user_id int) (map[string]int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	counts := make(map[string]int)
+
+	for _, item := range l.items {
+		if item.Returned || l.lists[item.ListId].UserId != user_id {
+			continue
+		}
+
+		if book, ok := l.books[item.BookId]; ok {
+			counts[book.Category]++
+		}
+	}
+	return counts, nil
+}
+
+func (l *Loans) ReturnBookBorrow(ctx context.Context, id int, returned_at time.Time) (*data.BookBorrorw, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	item, ok := l.items[id]
+
+	if !ok || item.Returned {
+		return nil, data.Conflict("loan_already_returned", "Borrowed item with id %d does not exist or is already returned.", id)
+	}
+
+	item.Returned = true
+	item.ReturnedAt = &returned_at
+	l.items[id] = item
+	l.addItemEvent(data.EventLoanReturned, item)
+
+	if book, ok := l.books[item.BookId]; ok {
+		book.BookCount++
+		l.books[item.BookId] = book
+	}
+
+	for _, other := range l.items {
+		if other.ListId == item.ListId && !other.Returned {
+			return &item, nil
+		}
+	}
+
+	borrow_list := l.lists[item.ListId]
+	borrow_list.Closed = true
+	borrow_list.UpdatedAt = returned_at
+	l.lists[item.ListId] = borrow_list
+
+	return &item, nil
+}
+
+func (l *Loans) RenewBookBorrow(ctx context.Context, id int, due_date time.Time) (*data.BookBorrorw, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	item, ok := l.items[id]
+
+	if !ok || item.Returned {
+		return nil, data.Conflict("loan_already_returned", "Borrowed item with id %d does not exist or is already returned.", id)
+	}
+
+	item.DueDate = due_date
+	item.RenewCount++
+	item.Extended = true
+	l.items[id] = item
+	l.addItemEvent(data.EventLoanRenewed, item)
+
+	borrow_list := l.lists[item.ListId]
+
+	if due_date.After(borrow_list.DueDate) {
+		borrow_list.DueDate = due_date
+	}
+	borrow_list.UpdatedAt = time.Now()
+	l.lists[item.ListId] = borrow_list
+
+	return &item, nil
+}
+
+func (l *Loans) MarkOverdueLoans(ctx context.Context, now time.Time) ([]*data.BookBorrorw, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	items := make([]*data.BookBorrorw, 0)
+
+	for id, item := range l.items {
+		if item.Returned || item.Overdue || !item.DueDate.Before(now) {
+			continue
+		}
+
+		item.Overdue = true
+		l.items[id] = item
+		l.addItemEvent(data.EventLoanOverdue, item)
+		items = append(items, &item)
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].ID < items[j].ID
+	})
+	return items, nil
+}
+
+func (l *Loans) GetDueSoonLoans(ctx context.Context, now, due_before time.Time) ([]*data.DueSoonLoan, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	loans := make([]*data.DueSoonLoan, 0)
+
+	for _, item := range l.items {
+		if _, reminded := l.reminders[item.ID]; reminded || item.Returned {
+			continue
+		}
+
+		if item.DueDate.Before(now) || !item.DueDate.Before(due_before) {
+			continue
+		}
+
+		book, book_ok := l.books[item.BookId]
+		user, user_ok := l.users[l.lists[item.ListId].UserId]
+
+		if !book_ok || !user_ok {
+			continue
+		}
+
+		loans = append(loans, &data.DueSoonLoan{
+			BorrowId:    item.ID,
+			BookId:      item.BookId,
+			Title:       book.Title,
+			DueDate:     item.DueDate,
+			UserId:      user.ID,
+			Name:        user.Name,
+			Email:       user.Email,
+			PhoneNumber: user.PhoneNumber,
+		})
+	}
+
+	sort.Slice(loans, func(i, j int) bool {
+		return loans[i].DueDate.Before(loans[j].DueDate)
+	})
+	return loans, nil
+}
+
+func (l *Loans) MarkReminderSent(ctx context.Context, id int, sent_at time.Time) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if _, ok := l.items[id]; ok {
+		l.reminders[id] = sent_at
+	}
+	return nil
+}
+
+// Write the event of a change to a borrowed item, naming the member who borrowed it.
+// Must be called with the lock held.
+func (l *Loans) addItemEvent(event_type string, item data.BookBorrorw) {
+	l.addEvent(event_type, "loan_item", item.ID, data.LoanItemEvent{UserId: l.lists[item.ListId].UserId, BookBorrorw: &item})
+}
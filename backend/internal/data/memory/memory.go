@@ -0,0 +1,204 @@
+// Package memory keeps every model in memory, behind the same repository interfaces as the
+// Postgres stores, so services and handlers can be tested without a database. The fakes
+// answer with the same domain errors as Postgres, and write the outbox events and book
+// revisions the Postgres stores and triggers write.
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/data"
+)
+
+// Records shared by the repositories, guarded by one lock.
+type Store struct {
+	mu     sync.Mutex
+	nextId int
+	records
+}
+
+// Records of every table, keyed by their primary key.
+type records struct {
+	authors           map[int]data.Author
+	books             map[int]data.Book
+	users             map[int]data.User
+	lists             map[int]data.BookBorrowList
+	items             map[int]data.BookBorrorw
+	reminders         map[int]time.Time
+	fines             map[int]data.FineEntry
+	policies          map[int]data.BorrowPolicy
+	hours             map[int]data.LibraryHours
+	closures          map[int]data.LibraryClosure
+	holds             map[int]data.BookHold
+	revokedTokens     map[string]data.RevokedToken
+	jobRuns           map[int]data.JobRun
+	preferences       map[preferenceKey]data.NotificationPreference
+	notifications     map[int]data.NotificationDelivery
+	events            map[int64]data.OutboxEvent
+	revisions         map[revisionKey]data.BookRevision
+	subscriptions     map[int]data.WebhookSubscription
+	webhookDeliveries map[int64]data.WebhookDelivery
+	auditEntries      map[int64]data.AuditEntry
+}
+
+func NewStore() *Store {
+	s := &Store{records: records{
+		authors:           make(map[int]data.Author),
+		books:             make(map[int]data.Book),
+		users:             make(map[int]data.User),
+		lists:             make(map[int]data.BookBorrowList),
+		items:             make(map[int]data.BookBorrorw),
+		reminders:         make(map[int]time.Time),
+		fines:             make(map[int]data.FineEntry),
+		policies:          make(map[int]data.BorrowPolicy),
+		hours:             make(map[int]data.LibraryHours),
+		closures:          make(map[int]data.LibraryClosure),
+		holds:             make(map[int]data.BookHold),
+		revokedTokens:     make(map[string]data.RevokedToken),
+		jobRuns:           make(map[int]data.JobRun),
+		preferences:       make(map[preferenceKey]data.NotificationPreference),
+		notifications:     make(map[int]data.NotificationDelivery),
+		events:            make(map[int64]data.OutboxEvent),
+		revisions:         make(map[revisionKey]data.BookRevision),
+		subscriptions:     make(map[int]data.WebhookSubscription),
+		webhookDeliveries: make(map[int64]data.WebhookDelivery),
+		auditEntries:      make(map[int64]data.AuditEntry),
+	}}
+	s.seedHours()
+
+	return s
+}
+
+// Models backed by a new store.
+func NewModels() data.Models {
+	return NewStore().Models()
+}
+
+func (s *Store) Models() data.Models {
+	return data.Models{
+		Tx:                     &UnitOfWork{s},
+		Author:                 &Authors{s},
+		Book:                   &Books{s},
+		User:                   &Users{s},
+		BookBorrowList:         &Loans{s},
+		FineEntry:              &FineEntries{s},
+		BorrowPolicy:           &BorrowPolicies{s},
+		LibraryHours:           &LibraryHours{s},
+		LibraryClosure:         &LibraryClosures{s},
+		BookHold:               &BookHolds{s},
+		RevokedToken:           &RevokedTokens{s},
+		JobRun:                 &JobRuns{s},
+		NotificationPreference: &NotificationPreferences{s},
+		NotificationDelivery:   &NotificationDeliveries{s},
+		OutboxEvent:            &OutboxEvents{s},
+		BookRevision:           &BookRevisions{s},
+		WebhookSubscription:    &WebhookSubscriptions{s},
+		WebhookDelivery:        &WebhookDeliveries{s},
+		AuditEntry:             &AuditEntries{s},
+		Stats:                  &Stats{s},
+	}
+}
+
+// Ids are unique across the store, like a sequence per table would make them unique per table.
+func (s *Store) newId() int {
+	s.nextId++
+	return s.nextId
+}
+
+// Write an event to the outbox, like the Postgres stores do with the change it describes.
+// Must be called with the lock held.
+func (s *Store) addEvent(event_type, aggregate_type string, aggregate_id int, payload any) {
+	encoded_payload, _ := json.Marshal(payload)
+	now := time.Now()
+
+	event := data.OutboxEvent{
+		ID:            int64(s.newId()),
+		EventType:     event_type,
+		AggregateType: aggregate_type,
+		AggregateId:   aggregate_id,
+		Payload:       encoded_payload,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+	}
+	s.events[event.ID] = event
+}
+
+var (
+	_ data.UnitOfWork                       = (*UnitOfWork)(nil)
+	_ data.AuthorRepository                 = (*Authors)(nil)
+	_ data.BookRepository                   = (*Books)(nil)
+	_ data.UserRepository                   = (*Users)(nil)
+	_ data.LoanRepository                   = (*Loans)(nil)
+	_ data.FineEntryRepository              = (*FineEntries)(nil)
+	_ data.BorrowPolicyRepository           = (*BorrowPolicies)(nil)
+	_ data.LibraryHoursRepository           = (*LibraryHours)(nil)
+	_ data.LibraryClosureRepository         = (*LibraryClosures)(nil)
+	_ data.BookHoldRepository               = (*BookHolds)(nil)
+	_ data.RevokedTokenRepository           = (*RevokedTokens)(nil)
+	_ data.JobRunRepository                 = (*JobRuns)(nil)
+	_ data.NotificationPreferenceRepository = (*NotificationPreferences)(nil)
+	_ data.NotificationDeliveryRepository   = (*NotificationDeliveries)(nil)
+	_ data.OutboxEventRepository            = (*OutboxEvents)(nil)
+	_ data.BookRevisionRepository           = (*BookRevisions)(nil)
+	_ data.WebhookSubscriptionRepository    = (*WebhookSubscriptions)(nil)
+	_ data.WebhookDeliveryRepository        = (*WebhookDeliveries)(nil)
+	_ data.AuditEntryRepository             = (*AuditEntries)(nil)
+	_ data.StatsRepository                  = (*Stats)(nil)
+)
+
+// Units of work over the store. The records are restored to how they were before fn when
+// it fails, along with the changes other callers made in the meantime, so the fakes are
+// only atomic for tests that do not write concurrently.
+type UnitOfWork struct {
+	*Store
+}
+
+func (u *UnitOfWork) Transact(ctx context.Context, isolation sql.IsolationLevel, fn func(ctx context.Context) error) error {
+	u.mu.Lock()
+	snapshot := u.records.clone()
+	u.mu.Unlock()
+
+	ctx, run_hooks := data.WithCommitHooks(ctx)
+
+	err := fn(ctx)
+
+	if err != nil {
+		u.mu.Lock()
+		u.records = snapshot
+		u.mu.Unlock()
+		return err
+	}
+	run_hooks()
+	return nil
+}
+
+// The records are values, a shallow copy of the maps is a snapshot of them.
+func (r records) clone() records {
+	return records{
+		authors:           maps.Clone(r.authors),
+		books:             maps.Clone(r.books),
+		users:             maps.Clone(r.users),
+		lists:             maps.Clone(r.lists),
+		items:             maps.Clone(r.items),
+		reminders:         maps.Clone(r.reminders),
+		fines:             maps.Clone(r.fines),
+		policies:          maps.Clone(r.policies),
+		hours:             maps.Clone(r.hours),
+		closures:          maps.Clone(r.closures),
+		holds:             maps.Clone(r.holds),
+		revokedTokens:     maps.Clone(r.revokedTokens),
+		jobRuns:           maps.Clone(r.jobRuns),
+		preferences:       maps.Clone(r.preferences),
+		notifications:     maps.Clone(r.notifications),
+		events:            maps.Clone(r.events),
+		revisions:         maps.Clone(r.revisions),
+		subscriptions:     maps.Clone(r.subscriptions),
+		webhookDeliveries: maps.Clone(r.webhookDeliveries),
+		auditEntries:      maps.Clone(r.auditEntries),
+	}
+}
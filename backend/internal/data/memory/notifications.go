@@ -0,0 +1,151 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/data"
+)
+
+type preferenceKey struct {
+	userId  int
+	channel string
+}
+
+// Notification preferences kept in memory, one per user and channel.
+type NotificationPreferences struct {
+	*Store
+}
+
+func (p *NotificationPreferences) GetUserPreferences(ctx context.Context, user_id int) ([]*data.NotificationPreference, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	preferences := make([]*data.NotificationPreference, 0)
+
+	for key, preference := range p.preferences {
+		if key.userId == user_id {
+			preferences = append(preferences, &preference)
+		}
+	}
+
+	sort.Slice(preferences, func(i, j int) bool {
+		return preferences[i].Channel < preferences[j].Channel
+	})
+	return preferences, nil
+}
+
+func (p *NotificationPreferences) UpsertPreference(ctx context.Context, preference data.NotificationPreference) (*data.NotificationPreference, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, ok := p.users[preference.UserId]; !ok {
+		return nil, data.Conflict("invalid_reference", "The record refers to, or is referred by, a record that does not exist.")
+	}
+
+	saved_preference := preference
+	saved_preference.UpdatedAt = time.Now()
+	p.preferences[preferenceKey{preference.UserId, preference.Channel}] = saved_preference
+
+	return &saved_preference, nil
+}
+
+// Notification deliveries kept in memory.
+type NotificationDeliveries struct {
+	*Store
+}
+
+func (d *NotificationDeliveries) InsertDelivery(ctx context.Context, delivery data.NotificationDelivery) (*data.NotificationDelivery, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if _, ok := d.users[delivery.UserId]; !ok {
+		return nil, data.Conflict("invalid_reference", "The record refers to, or is referred by, a record that does not exist.")
+	}
+
+	now := time.Now()
+
+	inserted_delivery := delivery
+	inserted_delivery.ID = d.newId()
+	inserted_delivery.Status = data.DeliveryPending
+	inserted_delivery.Attempts = 0
+	inserted_delivery.LastError = ""
+	inserted_delivery.SentAt = nil
+	inserted_delivery.CreatedAt = now
+	inserted_delivery.UpdatedAt = now
+	d.notifications[inserted_delivery.ID] = inserted_delivery
+
+	return &inserted_delivery, nil
+}
+
+func (d *NotificationDeliveries) RecordAttempt(ctx context.Context, id int, status, last_error string, next_attempt_at *time.Time) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	delivery, ok := d.notifications[id]
+
+	if !ok {
+		return nil
+	}
+
+	now := time.Now()
+
+	delivery.Status = status
+	delivery.Attempts++
+	delivery.LastError = last_error
+	delivery.NextAttemptAt = next_attempt_at
+
+	if status == data.DeliverySent {
+		delivery.SentAt = &now
+	}
+	delivery.UpdatedAt = now
+	d.notifications[id] = delivery
+
+	return nil
+}
+
+func (d *NotificationDeliveries) ClaimDueDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*data.NotificationDelivery, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	due := make([]data.NotificationDelivery, 0)
+
+	for _, delivery := range d.notifications {
+		if delivery.Status == data.DeliveryPending && delivery.NextAttemptAt != nil && !delivery.NextAttemptAt.After(now) {
+			due = append(due, delivery)
+		}
+	}
+
+	sort.Slice(due, func(i, j int) bool {
+		return due[i].NextAttemptAt.Before(*due[j].NextAttemptAt)
+	})
+
+	leased_until := now.Add(lease)
+	deliveries := make([]*data.NotificationDelivery, 0, min(limit, len(due)))
+
+	for _, delivery := range due[:min(limit, len(due))] {
+		delivery.NextAttemptAt = &leased_until
+		d.notifications[delivery.ID] = delivery
+		deliveries = append(deliveries, &delivery)
+	}
+	return deliveries, nil
+}
+
+func (d *NotificationDeliveries) GetUserDeliveries(ctx context.Context, user_id int, limit int) ([]*data.NotificationDelivery, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	deliveries := make([]*data.NotificationDelivery, 0)
+
+	for _, delivery := range d.notifications {
+		if delivery.UserId == user_id {
+			deliveries = append(deliveries, &delivery)
+		}
+	}
+
+	sort.Slice(deliveries, func(i, j int) bool {
+		return newerFirst(deliveries[i].CreatedAt, deliveries[j].CreatedAt, deliveries[i].ID, deliveries[j].ID)
+	})
+	return deliveries[:min(limit, len(deliveries))], nil
+}
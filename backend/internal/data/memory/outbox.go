@@ -0,0 +1,77 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/data"
+)
+
+// The outbox kept in memory, holding the events the other fakes write.
+type OutboxEvents struct {
+	*Store
+}
+
+func (o *OutboxEvents) ClaimEvents(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*data.OutboxEvent, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	due := make([]data.OutboxEvent, 0)
+
+	for _, event := range o.events {
+		if event.PublishedAt == nil && !event.NextAttemptAt.After(now) {
+			due = append(due, event)
+		}
+	}
+
+	sort.Slice(due, func(i, j int) bool {
+		return due[i].ID < due[j].ID
+	})
+
+	events := make([]*data.OutboxEvent, 0, min(limit, len(due)))
+
+	for _, event := range due[:min(limit, len(due))] {
+		event.NextAttemptAt = now.Add(lease)
+		o.events[event.ID] = event
+		events = append(events, &event)
+	}
+	return events, nil
+}
+
+func (o *OutboxEvents) MarkPublished(ctx context.Context, id int64) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	event, ok := o.events[id]
+
+	if !ok {
+		return nil
+	}
+
+	published_at := time.Now()
+
+	event.PublishedAt = &published_at
+	event.LastError = ""
+	o.events[id] = event
+
+	return nil
+}
+
+func (o *OutboxEvents) RecordFailure(ctx context.Context, id int64, last_error string, next_attempt_at time.Time) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	event, ok := o.events[id]
+
+	if !ok {
+		return nil
+	}
+
+	event.Attempts++
+	event.LastError = last_error
+	event.NextAttemptAt = next_attempt_at
+	o.events[id] = event
+
+	return nil
+}
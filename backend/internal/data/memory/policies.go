@@ -0,0 +1,130 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/data"
+)
+
+// Borrow policies kept in memory. There is one rule per member type and category, like the
+// unique index of the table makes it. Categories are not checked, there is no category table.
+type BorrowPolicies struct {
+	*Store
+}
+
+func samePolicyRule(a, b data.BorrowPolicy) bool {
+	return valueOr(a.MemberType) == valueOr(b.MemberType) && valueOr(a.Category) == valueOr(b.Category)
+}
+
+func valueOr(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+func (p *BorrowPolicies) GetPolicies(ctx context.Context) ([]*data.BorrowPolicy, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	policies := make([]*data.BorrowPolicy, 0, len(p.policies))
+
+	for _, policy := range p.policies {
+		policies = append(policies, &policy)
+	}
+
+	// Nulls first, like the query orders them.
+	sort.Slice(policies, func(i, j int) bool {
+		if (policies[i].MemberType == nil) != (policies[j].MemberType == nil) {
+			return policies[i].MemberType == nil
+		}
+
+		if valueOr(policies[i].MemberType) != valueOr(policies[j].MemberType) {
+			return valueOr(policies[i].MemberType) < valueOr(policies[j].MemberType)
+		}
+
+		if (policies[i].Category == nil) != (policies[j].Category == nil) {
+			return policies[i].Category == nil
+		}
+		return valueOr(policies[i].Category) < valueOr(policies[j].Category)
+	})
+	return policies, nil
+}
+
+func (p *BorrowPolicies) GetPolicyWithId(ctx context.Context, id int) (*data.BorrowPolicy, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	policy, ok := p.policies[id]
+
+	if !ok {
+		return nil, data.NotFound("borrow_policy_not_found", "Borrow policy with id %d does not exist.", id)
+	}
+	return &policy, nil
+}
+
+func (p *BorrowPolicies) InsertPolicy(ctx context.Context, policy data.BorrowPolicy) (*data.BorrowPolicy, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.ruleTaken(policy, 0) {
+		return nil, data.Conflict("duplicate_record", "The record conflicts with an existing one.")
+	}
+
+	now := time.Now()
+
+	inserted_policy := policy
+	inserted_policy.ID = p.newId()
+	inserted_policy.CreatedAt = now
+	inserted_policy.UpdatedAt = now
+	p.policies[inserted_policy.ID] = inserted_policy
+
+	return &inserted_policy, nil
+}
+
+func (p *BorrowPolicies) UpdatePolicy(ctx context.Context, id int, policy data.BorrowPolicy) (*data.BorrowPolicy, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	existing_policy, ok := p.policies[id]
+
+	if !ok {
+		return nil, data.NotFound("borrow_policy_not_found", "Borrow policy with id %d does not exist.", id)
+	}
+
+	if p.ruleTaken(policy, id) {
+		return nil, data.Conflict("duplicate_record", "The record conflicts with an existing one.")
+	}
+
+	updated_policy := policy
+	updated_policy.ID = id
+	updated_policy.CreatedAt = existing_policy.CreatedAt
+	updated_policy.UpdatedAt = time.Now()
+	p.policies[id] = updated_policy
+
+	return &updated_policy, nil
+}
+
+func (p *BorrowPolicies) DeletePolicy(ctx context.Context, id int) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, ok := p.policies[id]; !ok {
+		return data.NotFound("borrow_policy_not_found", "Borrow policy with id %d does not exist.", id)
+	}
+	delete(p.policies, id)
+
+	return nil
+}
+
+// Must be called with the lock held.
+func (p *BorrowPolicies) ruleTaken(policy data.BorrowPolicy, except_id int) bool {
+	for id, existing_policy := range p.policies {
+		if id != except_id && samePolicyRule(existing_policy, policy) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,101 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/data"
+)
+
+type revisionKey struct {
+	bookId   int
+	revision int
+}
+
+// Record a revision of the book valid from valid_from, closing the current one, as the
+// book_history trigger does. Must be called with the lock held.
+func (s *Store) addRevision(book data.Book, valid_from time.Time) {
+	next_revision := 1
+
+	for key, revision := range s.revisions {
+		if key.bookId != book.ID {
+			continue
+		}
+
+		if revision.ValidTo == nil {
+			revision.ValidTo = &valid_from
+			s.revisions[key] = revision
+		}
+		next_revision = max(next_revision, key.revision+1)
+	}
+
+	s.revisions[revisionKey{book.ID, next_revision}] = data.BookRevision{
+		BookId:     book.ID,
+		Revision:   next_revision,
+		Title:      book.Title,
+		Category:   book.Category,
+		Publisher:  book.Publisher,
+		Price:      book.Price,
+		FinePerDay: book.FinePerDay,
+		AuthorId:   book.AuthorId,
+		Archive:    book.Archive,
+		ValidFrom:  valid_from,
+	}
+}
+
+// Whether the update changed the catalog record, the stock is not versioned.
+func revisedBook(before, after data.Book) bool {
+	before.BookCount, after.BookCount = 0, 0
+	before.UpdatedAt, after.UpdatedAt = time.Time{}, time.Time{}
+	before.Version, after.Version = 0, 0
+
+	return before != after
+}
+
+// Book revisions kept in memory, written by the book fakes.
+type BookRevisions struct {
+	*Store
+}
+
+func (r *BookRevisions) GetRevisions(ctx context.Context, book_id int) ([]*data.BookRevision, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	revisions := make([]*data.BookRevision, 0)
+
+	for key, revision := range r.revisions {
+		if key.bookId == book_id {
+			revisions = append(revisions, &revision)
+		}
+	}
+
+	sort.Slice(revisions, func(i, j int) bool {
+		return revisions[i].Revision > revisions[j].Revision
+	})
+	return revisions, nil
+}
+
+func (r *BookRevisions) GetRevisionAsOf(ctx context.Context, book_id int, at time.Time) (*data.BookRevision, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for key, revision := range r.revisions {
+		if key.bookId == book_id && !revision.ValidFrom.After(at) && (revision.ValidTo == nil || revision.ValidTo.After(at)) {
+			return &revision, nil
+		}
+	}
+	return nil, data.NotFound("book_revision_not_found", "Book with id %d did not exist on %s.", book_id, at.Format(time.RFC3339))
+}
+
+func (r *BookRevisions) GetRevision(ctx context.Context, book_id, revision_number int) (*data.BookRevision, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	revision, ok := r.revisions[revisionKey{book_id, revision_number}]
+
+	if !ok {
+		return nil, data.NotFound("book_revision_not_found", "Book with id %d has no revision %d.", book_id, revision_number)
+	}
+	return &revision, nil
+}
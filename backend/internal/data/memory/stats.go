@@ -0,0 +1,50 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/data"
+)
+
+// Library wide aggregates computed over the store.
+type Stats struct {
+	*Store
+}
+
+func (s *Stats) GetLibraryStats(ctx context.Context, now time.Time) (*data.LibraryStats, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var stats data.LibraryStats
+
+	for _, item := range s.items {
+		if item.Returned {
+			continue
+		}
+		stats.ActiveLoans++
+
+		if item.DueDate.Before(now) {
+			stats.OverdueLoans++
+		}
+	}
+
+	balances := make(map[int]float64)
+
+	for _, entry := range s.fines {
+		balances[entry.UserId] += float64(entry.BalanceEffect())
+	}
+
+	for _, balance := range balances {
+		if balance > 0 {
+			stats.OutstandingFines += balance
+		}
+	}
+
+	for _, book := range s.books {
+		if !book.Archive && book.BookCount == 0 {
+			stats.OutOfStockBooks++
+		}
+	}
+	return &stats, nil
+}
@@ -0,0 +1,52 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/data"
+)
+
+// Revoked tokens kept in memory.
+type RevokedTokens struct {
+	*Store
+}
+
+func (r *RevokedTokens) InsertRevokedToken(ctx context.Context, token data.RevokedToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.revokedTokens[token.TokenId]; ok {
+		return nil
+	}
+
+	revoked_token := token
+	revoked_token.RevokedAt = time.Now()
+	r.revokedTokens[token.TokenId] = revoked_token
+
+	return nil
+}
+
+func (r *RevokedTokens) IsTokenRevoked(ctx context.Context, token_id string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, ok := r.revokedTokens[token_id]
+
+	return ok, nil
+}
+
+func (r *RevokedTokens) PurgeExpiredTokens(ctx context.Context, now time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var purged int64
+
+	for token_id, token := range r.revokedTokens {
+		if token.ExpiresAt.Before(now) {
+			delete(r.revokedTokens, token_id)
+			purged++
+		}
+	}
+	return purged, nil
+}
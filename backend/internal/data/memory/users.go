@@ -0,0 +1,145 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/data"
+)
+
+// Users kept in memory. Emails are unique like the users table makes them.
+type Users struct {
+	*Store
+}
+
+func (u *Users) CreateUser(ctx context.Context, user data.User) (*data.User, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if _, ok := u.userWithEmail(user.Email); ok {
+		return nil, data.ErrEmailTaken
+	}
+
+	inserted_user := user
+	inserted_user.ID = u.newId()
+	inserted_user.MemberType = "standard"
+	inserted_user.CreatedAt = time.Now()
+	inserted_user.UpdatedAt = inserted_user.CreatedAt
+	inserted_user.Version = 1
+	u.users[inserted_user.ID] = inserted_user
+
+	// The password hash stays out of the event.
+	u.addEvent(data.EventUserRegistered, "user", inserted_user.ID, map[string]any{
+		"id":           inserted_user.ID,
+		"name":         inserted_user.Name,
+		"email":        inserted_user.Email,
+		"phone_number": inserted_user.PhoneNumber,
+		"member_type":  inserted_user.MemberType,
+		"is_active":    inserted_user.IsActive,
+		"created_at":   inserted_user.CreatedAt,
+	})
+
+	return &inserted_user, nil
+}
+
+func (u *Users) GetUserWithEmail(ctx context.Context, user data.User) (*data.User, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	existing_user, ok := u.userWithEmail(user.Email)
+
+	if !ok {
+		return nil, data.NotFound("user_not_found", "User does not exist.")
+	}
+	return &existing_user, nil
+}
+
+func (u *Users) GetUserWithId(ctx context.Context, id int) (*data.User, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	existing_user, ok := u.users[id]
+
+	if !ok {
+		return nil, data.NotFound("user_not_found", "User with id %d does not exist.", id)
+	}
+	return &existing_user, nil
+}
+
+func (u *Users) ActivateUser(ctx context.Context, id int) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	user, ok := u.users[id]
+
+	if !ok {
+		return data.NotFound("user_not_found", "User with id %d does not exist.", id)
+	}
+
+	if user.IsActive {
+		return data.Conflict("user_already_active", "User with id %d is already active.", id)
+	}
+
+	user.IsActive = true
+	user.UpdatedAt = time.Now()
+	user.Version++
+	u.users[id] = user
+
+	return nil
+}
+
+func (u *Users) UpdateMemberType(ctx context.Context, id int, member_type string) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	user, ok := u.users[id]
+
+	if !ok {
+		return data.NotFound("user_not_found", "User with id %d does not exist.", id)
+	}
+
+	user.MemberType = member_type
+	user.UpdatedAt = time.Now()
+	user.Version++
+	u.users[id] = user
+
+	return nil
+}
+
+func (u *Users) UpdateUser(ctx context.Context, id int, version int, user data.User) (*data.User, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	updated_user, ok := u.users[id]
+
+	if !ok {
+		return nil, data.NotFound("user_not_found", "User with id %d does not exist.", id)
+	}
+
+	if updated_user.Version != version {
+		return nil, data.ErrEditConflict
+	}
+
+	if other, ok := u.userWithEmail(user.Email); ok && other.ID != id {
+		return nil, data.Conflict("duplicate_record", "The record conflicts with an existing one.")
+	}
+
+	updated_user.Name = user.Name
+	updated_user.Email = user.Email
+	updated_user.PhoneNumber = user.PhoneNumber
+	updated_user.UpdatedAt = time.Now()
+	updated_user.Version++
+	u.users[id] = updated_user
+
+	return &updated_user, nil
+}
+
+// Must be called with the lock held.
+func (u *Users) userWithEmail(email string) (data.User, bool) {
+	for _, user := range u.users {
+		if user.Email == email {
+			return user, true
+		}
+	}
+	return data.User{}, false
+}
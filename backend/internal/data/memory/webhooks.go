@@ -0,0 +1,276 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/data"
+)
+
+// Webhook subscriptions kept in memory. Deleting one deletes its deliveries.
+type WebhookSubscriptions struct {
+	*Store
+}
+
+func (w *WebhookSubscriptions) InsertSubscription(ctx context.Context, subscription data.WebhookSubscription) (*data.WebhookSubscription, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	now := time.Now()
+
+	inserted_subscription := subscription
+	inserted_subscription.ID = w.newId()
+	inserted_subscription.EventTypes = eventTypesOf(subscription)
+	inserted_subscription.CreatedAt = now
+	inserted_subscription.UpdatedAt = now
+	w.subscriptions[inserted_subscription.ID] = inserted_subscription
+
+	return &inserted_subscription, nil
+}
+
+func (w *WebhookSubscriptions) UpdateSubscription(ctx context.Context, id int, subscription data.WebhookSubscription) (*data.WebhookSubscription, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	updated_subscription, ok := w.subscriptions[id]
+
+	if !ok {
+		return nil, data.NotFound("webhook_subscription_not_found", "Webhook subscription with id %d does not exist.", id)
+	}
+
+	updated_subscription.Name = subscription.Name
+	updated_subscription.URL = subscription.URL
+	updated_subscription.EventTypes = eventTypesOf(subscription)
+	updated_subscription.Active = subscription.Active
+	updated_subscription.UpdatedAt = time.Now()
+	w.subscriptions[id] = updated_subscription
+
+	return &updated_subscription, nil
+}
+
+func (w *WebhookSubscriptions) UpdateSecret(ctx context.Context, id int, secret string) (*data.WebhookSubscription, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	updated_subscription, ok := w.subscriptions[id]
+
+	if !ok {
+		return nil, data.NotFound("webhook_subscription_not_found", "Webhook subscription with id %d does not exist.", id)
+	}
+
+	updated_subscription.Secret = secret
+	updated_subscription.UpdatedAt = time.Now()
+	w.subscriptions[id] = updated_subscription
+
+	return &updated_subscription, nil
+}
+
+func (w *WebhookSubscriptions) DeleteSubscription(ctx context.Context, id int) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if _, ok := w.subscriptions[id]; !ok {
+		return data.NotFound("webhook_subscription_not_found", "Webhook subscription with id %d does not exist.", id)
+	}
+	delete(w.subscriptions, id)
+
+	for delivery_id, delivery := range w.webhookDeliveries {
+		if delivery.SubscriptionId == id {
+			delete(w.webhookDeliveries, delivery_id)
+		}
+	}
+	return nil
+}
+
+func (w *WebhookSubscriptions) GetSubscriptionWithId(ctx context.Context, id int) (*data.WebhookSubscription, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	subscription, ok := w.subscriptions[id]
+
+	if !ok {
+		return nil, data.NotFound("webhook_subscription_not_found", "Webhook subscription with id %d does not exist.", id)
+	}
+	return &subscription, nil
+}
+
+func (w *WebhookSubscriptions) GetSubscriptions(ctx context.Context, active_only bool) ([]*data.WebhookSubscription, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	subscriptions := make([]*data.WebhookSubscription, 0)
+
+	for _, subscription := range w.subscriptions {
+		if !active_only || subscription.Active {
+			subscriptions = append(subscriptions, &subscription)
+		}
+	}
+
+	sort.Slice(subscriptions, func(i, j int) bool {
+		return subscriptions[i].ID < subscriptions[j].ID
+	})
+	return subscriptions, nil
+}
+
+// The event types as stored, an empty list rather than none.
+func eventTypesOf(subscription data.WebhookSubscription) []string {
+	if subscription.EventTypes == nil {
+		return []string{}
+	}
+	return append([]string(nil), subscription.EventTypes...)
+}
+
+// Webhook deliveries kept in memory. An event is queued once per subscription, replays aside.
+type WebhookDeliveries struct {
+	*Store
+}
+
+func (w *WebhookDeliveries) InsertDelivery(ctx context.Context, subscription_id int, event_id int64, event_type string, payload []byte) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if _, ok := w.subscriptions[subscription_id]; !ok {
+		return data.Conflict("invalid_reference", "The record refers to, or is referred by, a record that does not exist.")
+	}
+
+	for _, delivery := range w.webhookDeliveries {
+		if delivery.SubscriptionId == subscription_id && delivery.EventId == event_id && delivery.ReplayOf == nil {
+			return nil
+		}
+	}
+
+	now := time.Now()
+
+	delivery := data.WebhookDelivery{
+		ID:             int64(w.newId()),
+		SubscriptionId: subscription_id,
+		EventId:        event_id,
+		EventType:      event_type,
+		Payload:        append([]byte(nil), payload...),
+		Status:         data.WebhookDeliveryPending,
+		NextAttemptAt:  &now,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	w.webhookDeliveries[delivery.ID] = delivery
+
+	return nil
+}
+
+func (w *WebhookDeliveries) ReplayDelivery(ctx context.Context, id int64) (*data.WebhookDelivery, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	original, ok := w.webhookDeliveries[id]
+
+	if !ok {
+		return nil, data.NotFound("webhook_delivery_not_found", "Webhook delivery with id %d does not exist.", id)
+	}
+
+	now := time.Now()
+
+	delivery := data.WebhookDelivery{
+		ID:             int64(w.newId()),
+		SubscriptionId: original.SubscriptionId,
+		EventId:        original.EventId,
+		EventType:      original.EventType,
+		Payload:        original.Payload,
+		Status:         data.WebhookDeliveryPending,
+		NextAttemptAt:  &now,
+		ReplayOf:       &original.ID,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	w.webhookDeliveries[delivery.ID] = delivery
+
+	return &delivery, nil
+}
+
+func (w *WebhookDeliveries) GetDeliveryWithId(ctx context.Context, id int64) (*data.WebhookDelivery, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	delivery, ok := w.webhookDeliveries[id]
+
+	if !ok {
+		return nil, data.NotFound("webhook_delivery_not_found", "Webhook delivery with id %d does not exist.", id)
+	}
+	return &delivery, nil
+}
+
+func (w *WebhookDeliveries) GetSubscriptionDeliveries(ctx context.Context, subscription_id int, status string, limit int) ([]*data.WebhookDelivery, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	deliveries := make([]*data.WebhookDelivery, 0)
+
+	for _, delivery := range w.webhookDeliveries {
+		if delivery.SubscriptionId == subscription_id && (status == "" || delivery.Status == status) {
+			deliveries = append(deliveries, &delivery)
+		}
+	}
+
+	sort.Slice(deliveries, func(i, j int) bool {
+		return deliveries[i].ID > deliveries[j].ID
+	})
+	return deliveries[:min(limit, len(deliveries))], nil
+}
+
+func (w *WebhookDeliveries) ClaimDueDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*data.WebhookDelivery, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	due := make([]data.WebhookDelivery, 0)
+
+	for _, delivery := range w.webhookDeliveries {
+		if delivery.Status != data.WebhookDeliveryPending || delivery.NextAttemptAt == nil || delivery.NextAttemptAt.After(now) {
+			continue
+		}
+
+		if subscription, ok := w.subscriptions[delivery.SubscriptionId]; ok && subscription.Active {
+			due = append(due, delivery)
+		}
+	}
+
+	sort.Slice(due, func(i, j int) bool {
+		return due[i].NextAttemptAt.Before(*due[j].NextAttemptAt)
+	})
+
+	leased_until := now.Add(lease)
+	deliveries := make([]*data.WebhookDelivery, 0, min(limit, len(due)))
+
+	for _, delivery := range due[:min(limit, len(due))] {
+		delivery.NextAttemptAt = &leased_until
+		w.webhookDeliveries[delivery.ID] = delivery
+		deliveries = append(deliveries, &delivery)
+	}
+	return deliveries, nil
+}
+
+func (w *WebhookDeliveries) RecordAttempt(ctx context.Context, id int64, status string, response_status *int, last_error string, next_attempt_at *time.Time) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	delivery, ok := w.webhookDeliveries[id]
+
+	if !ok {
+		return nil
+	}
+
+	now := time.Now()
+
+	delivery.Status = status
+	delivery.Attempts++
+	delivery.ResponseStatus = response_status
+	delivery.LastError = last_error
+	delivery.NextAttemptAt = next_attempt_at
+
+	if status == data.WebhookDeliverySucceeded {
+		delivery.DeliveredAt = &now
+	}
+	delivery.UpdatedAt = now
+	w.webhookDeliveries[id] = delivery
+
+	return nil
+}
@@ -3,33 +3,81 @@ package data
 import (
 	"context"
 	"database/sql"
-	"errors"
 	"fmt"
+	"log/slog"
 	"strings"
 	"time"
 
 	"github.com/sanggonlee/gosq"
 )
 
-var db *sql.DB
+// Connections and timeouts the Postgres stores share.
+type pgStore struct {
+	db *sql.DB
+	// Read replica of db, nil when there is none.
+	replica  *sql.DB
+	timeouts Timeouts
+}
+
+// Returned when a record was changed since the version the caller read.
+var ErrEditConflict = Conflict("edit_conflict", "The record was modified since it was read, reload it and try again.")
 
-const dbTimeout = time.Second * 3
+// Returned when registering an email that is taken. It is not shown to the client, which is
+// answered as for a new email so registering does not tell which emails are registered.
+var ErrEmailTaken = Conflict("email_taken", "The email can not be registered.")
+
+// The Postgres stores of every model, sharing the pool. The read only catalogue queries go
+// to the replica pool, unless it is nil.
+func New(dbPool *sql.DB, replicaPool *sql.DB, db_timeouts Timeouts, tx_config TxConfig) Models {
+	store := pgStore{db: dbPool, replica: replicaPool, timeouts: db_timeouts}
 
-func New(dbPool *sql.DB) Models {
-	db = dbPool
 	return Models{
-		Author:         &Author{},
-		Book:           &Book{},
-		User:           &User{},
-		BookBorrowList: &BookBorrowList{},
+		Tx:                     &TxStore{store, tx_config},
+		Author:                 &AuthorStore{store},
+		Book:                   &BookStore{store},
+		User:                   &UserStore{store},
+		BookBorrowList:         &LoanStore{store},
+		FineEntry:              &FineEntryStore{store},
+		BorrowPolicy:           &BorrowPolicyStore{store},
+		LibraryHours:           &LibraryHoursStore{store},
+		LibraryClosure:         &LibraryClosureStore{store},
+		BookHold:               &BookHoldStore{store},
+		RevokedToken:           &RevokedTokenStore{store},
+		JobRun:                 &JobRunStore{store},
+		NotificationPreference: &NotificationPreferenceStore{store},
+		NotificationDelivery:   &NotificationDeliveryStore{store},
+		OutboxEvent:            &OutboxEventStore{store},
+		BookRevision:           &BookRevisionStore{store},
+		WebhookSubscription:    &WebhookSubscriptionStore{store},
+		WebhookDelivery:        &WebhookDeliveryStore{store},
+		AuditEntry:             &AuditEntryStore{store},
+		Stats:                  &StatsStore{store},
 	}
 }
 
+// The repositories the services work with. They are behind interfaces so they can be swapped,
+// e.g. for the in-memory fakes of package memory.
 type Models struct {
-	Author         *Author
-	Book           *Book
-	User           *User
-	BookBorrowList *BookBorrowList
+	Tx                     UnitOfWork
+	Author                 AuthorRepository
+	Book                   BookRepository
+	User                   UserRepository
+	BookBorrowList         LoanRepository
+	FineEntry              FineEntryRepository
+	BorrowPolicy           BorrowPolicyRepository
+	LibraryHours           LibraryHoursRepository
+	LibraryClosure         LibraryClosureRepository
+	BookHold               BookHoldRepository
+	RevokedToken           RevokedTokenRepository
+	JobRun                 JobRunRepository
+	NotificationPreference NotificationPreferenceRepository
+	NotificationDelivery   NotificationDeliveryRepository
+	OutboxEvent            OutboxEventRepository
+	BookRevision           BookRevisionRepository
+	WebhookSubscription    WebhookSubscriptionRepository
+	WebhookDelivery        WebhookDeliveryRepository
+	AuditEntry             AuditEntryRepository
+	Stats                  StatsRepository
 }
 
 type Author struct {
@@ -38,6 +86,7 @@ type Author struct {
 	About     string    `json:"about"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+	Version   int       `json:"version"`
 }
 
 type Book struct {
@@ -52,18 +101,26 @@ type Book struct {
 	CreatedAt  time.Time `json:"created_at"`
 	UpdatedAt  time.Time `json:"updated_at"`
 	Archive    bool      `json:"archive"`
+	Version    int       `json:"version"`
 }
 
 type User struct {
 	ID          int       `json:"int"`
 	Name        string    `json:"name"`
 	Email       string    `json:"email"`
-	Password    string    `json:"password"`
+	Password    string    `json:"password,omitempty"`
 	PhoneNumber string    `json:"phone_number"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
 	IsActive    bool      `json:"is_active"`
 	IsAdmin     bool      `json:"is_admin"`
+	MemberType  string    `json:"member_type"`
+	Version     int       `json:"version"`
+}
+
+// Users are logged without their password hash or contact details.
+func (u User) LogValue() slog.Value {
+	return slog.GroupValue(slog.Int("id", u.ID), slog.String("member_type", u.MemberType), slog.Bool("is_admin", u.IsAdmin))
 }
 
 type Book_with_name struct {
@@ -79,34 +136,64 @@ type Book_with_name struct {
 	UpdatedAt  time.Time `json:"updated_at"`
 }
 
+// Filters of the book search, matched as case insensitive substrings. Empty fields are not filtered on.
+type BookFilter struct {
+	Title      string
+	Category   string
+	Publisher  string
+	AuthorName string
+}
+
+// Fields of a book to update, nil fields are left as they are.
+type BookUpdate struct {
+	Title      *string
+	Category   *string
+	Publisher  *string
+	BookCount  *int
+	Price      *float32
+	FinePerDay *float32
+	AuthorId   *int
+}
+
 type BookBorrowList struct {
 	ID        int            `json:"id"`
 	DueDate   time.Time      `json:"due_date"`
 	UserId    int            `json:"user_id"`
 	Closed    bool           `json:"closed"`
-	FinePaid  float32        `json:"fine_paid"`
 	CreateAt  time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
 	BookList  []*BookBorrorw `json:"lended_books"`
 }
 
 type BookBorrorw struct {
-	ID       int  `json:"id"`
-	BookId   int  `json:"book_id"`
-	ListId   int  `json:"list_id"`
-	Returned bool `json:"returned"`
-	Extended bool `json:"extended"`
+	ID           int        `json:"id"`
+	BookId       int        `json:"book_id"`
+	ListId       int        `json:"list_id"`
+	Returned     bool       `json:"returned"`
+	Extended     bool       `json:"extended"`
+	DueDate      time.Time  `json:"due_date"`
+	RenewCount   int        `json:"renew_count"`
+	ReturnedAt   *time.Time `json:"returned_at"`
+	FinePerDay   float32    `json:"fine_per_day"`
+	MaxFine      *float32   `json:"max_fine"`
+	Overdue      bool       `json:"overdue"`
+	BookRevision *int       `json:"book_revision"`
+}
+
+// Authors stored in Postgres.
+type AuthorStore struct {
+	pgStore
 }
 
 // Get author with id
-func (a *Author) GetAuthorWithId(id int) (Author, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+func (a *AuthorStore) GetAuthorWithId(ctx context.Context, id int) (Author, error) {
+	ctx, cancel := context.WithTimeout(ctx, a.timeouts.Read)
 	defer cancel()
 
 	var author Author
 
-	query := `select * from author where id = $1;`
-	row := db.QueryRowContext(ctx, query, id)
+	query := `select id, name, about, created_at, updated_at, version from author where id = $1;`
+	row := a.conn(ctx).QueryRowContext(ctx, query, id)
 
 	err := row.Scan(
 		&author.ID,
@@ -114,8 +201,13 @@ func (a *Author) GetAuthorWithId(id int) (Author, error) {
 		&author.About,
 		&author.CreatedAt,
 		&author.UpdatedAt,
+		&author.Version,
 	)
 
+	if err == sql.ErrNoRows {
+		return author, NotFound("author_not_found", "Author with id %d does not exist.", id)
+	}
+
 	if err != nil {
 		return author, err
 	}
@@ -123,54 +215,50 @@ func (a *Author) GetAuthorWithId(id int) (Author, error) {
 }
 
 // Get authors details with name and about details
-func (a *Author) GetAuthorWithDetails(name string) ([]Author, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 3*dbTimeout)
+func (a *AuthorStore) GetAuthorWithDetails(ctx context.Context, name string) ([]Author, error) {
+	ctx, cancel := context.WithTimeout(ctx, a.timeouts.List)
 
 	defer cancel()
 
-	var authors []Author
+	stmt := `select id, name, about, created_at, updated_at, version from author order by created_at desc;`
+	var args []any
 
 	if name != "" {
-		stmt := `select * from author where name ilike $1 order by created_at desc;`
-		name_param := "%" + strings.ToLower(name) + "%"
-		rows, err := db.QueryContext(ctx, stmt, name_param)
+		stmt = `select id, name, about, created_at, updated_at, version from author where name ilike $1 order by created_at desc;`
+		args = append(args, "%"+strings.ToLower(name)+"%")
+	}
+
+	rows, err := a.replicaConn(ctx).QueryContext(ctx, stmt, args...)
+
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var authors []Author
+
+	for rows.Next() {
+		var author Author
+		err = rows.Scan(&author.ID, &author.Name, &author.About, &author.CreatedAt, &author.UpdatedAt, &author.Version)
 		if err != nil {
 			return nil, err
 		}
-		for rows.Next() {
-			var author Author
-			err = rows.Scan(&author.ID, &author.Name, &author.About, &author.CreatedAt, &author.UpdatedAt)
-			if err != nil {
-				return nil, err
-			}
-			authors = append(authors, author)
-		}
-	} else {
-		stmt := `select * from author order by created_at desc;`
-		rows, err := db.QueryContext(ctx, stmt)
-		for rows.Next() {
-			var author Author
-			err = rows.Scan(&author.ID, &author.Name, &author.About, &author.CreatedAt, &author.UpdatedAt)
-			if err != nil {
-				return nil, err
-			}
-			authors = append(authors, author)
-		}
+		authors = append(authors, author)
 	}
-	return authors, nil
+	return authors, rows.Err()
 }
 
 // Create author
-func (a *Author) InsertAuthor(author Author) (*Author, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+func (a *AuthorStore) InsertAuthor(ctx context.Context, author Author) (*Author, error) {
+	ctx, cancel := context.WithTimeout(ctx, a.timeouts.Write)
 
 	defer cancel()
 
 	var addedAuthor Author
 
-	stmt := `insert into author (name, about, created_at, updated_at) values ($1, $2, $3, $4) returning id, name, about, created_at, updated_at;`
+	stmt := `insert into author (name, about, created_at, updated_at) values ($1, $2, $3, $4) returning id, name, about, created_at, updated_at, version;`
 
-	row := db.QueryRowContext(ctx, stmt, author.Name, author.About, time.Now(), time.Now())
+	row := a.conn(ctx).QueryRowContext(ctx, stmt, author.Name, author.About, time.Now(), time.Now())
 
 	err := row.Scan(
 		&addedAuthor.ID,
@@ -178,6 +266,7 @@ func (a *Author) InsertAuthor(author Author) (*Author, error) {
 		&addedAuthor.About,
 		&addedAuthor.CreatedAt,
 		&addedAuthor.UpdatedAt,
+		&addedAuthor.Version,
 	)
 
 	if err != nil {
@@ -186,32 +275,80 @@ func (a *Author) InsertAuthor(author Author) (*Author, error) {
 	return &addedAuthor, nil
 }
 
+// Update author, provided it is still at the version the caller read
+func (a *AuthorStore) UpdateAuthor(ctx context.Context, id int, version int, author Author) (*Author, error) {
+	ctx, cancel := context.WithTimeout(ctx, a.timeouts.Write)
+
+	defer cancel()
+
+	var updated_author Author
+
+	stmt := `update author set name = $1, about = $2, updated_at = $3, version = version + 1 where id = $4 and version = $5 returning id, name, about, created_at, updated_at, version;`
+
+	row := a.conn(ctx).QueryRowContext(ctx, stmt, author.Name, author.About, time.Now(), id, version)
+
+	err := row.Scan(
+		&updated_author.ID,
+		&updated_author.Name,
+		&updated_author.About,
+		&updated_author.CreatedAt,
+		&updated_author.UpdatedAt,
+		&updated_author.Version,
+	)
+
+	if err == sql.ErrNoRows {
+		_, get_err := a.GetAuthorWithId(ctx, id)
+
+		if get_err != nil {
+			return nil, get_err
+		}
+		return nil, ErrEditConflict
+	}
+
+	if err != nil {
+		return nil, err
+	}
+	return &updated_author, nil
+}
+
+// Books stored in Postgres.
+type BookStore struct {
+	pgStore
+}
+
 // Create a book
-func (b *Book) InsertBook(book Book) (*Book, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout*2)
+func (b *BookStore) InsertBook(ctx context.Context, book Book) (*Book, error) {
+	ctx, cancel := context.WithTimeout(ctx, b.timeouts.Transaction)
 
 	defer cancel()
 
+	tx, err := b.begin(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
 	//Category check for the book
 	var category_exists bool
 	category_check_query := `select case when count(*) > 0 then True else False end from category where category_name = $1;`
 
-	row := db.QueryRowContext(ctx, category_check_query, book.Category)
-	err := row.Scan(&category_exists)
+	row := tx.QueryRowContext(ctx, category_check_query, book.Category)
+	err = row.Scan(&category_exists)
 
 	if err != nil {
 		return nil, err
 	}
 
 	if !category_exists {
-		return nil, errors.New(fmt.Sprintf("%v this category does not exists.", book.Category))
+		return nil, Invalid("unknown_category", "Category %s does not exist.", book.Category)
 	}
 
 	//author id check for the book
 	var author_id_exists bool
 	author_id_check_query := `select case when count(*) > 0 then True else False end from author where id = $1;`
 
-	row = db.QueryRowContext(ctx, author_id_check_query, book.AuthorId)
+	row = tx.QueryRowContext(ctx, author_id_check_query, book.AuthorId)
 	err = row.Scan(&author_id_exists)
 
 	if err != nil {
@@ -219,14 +356,14 @@ func (b *Book) InsertBook(book Book) (*Book, error) {
 	}
 
 	if !author_id_exists {
-		return nil, errors.New(fmt.Sprintf("%v this author_id does not exists.", book.AuthorId))
+		return nil, Invalid("unknown_author", "Author with id %d does not exist.", book.AuthorId)
 	}
 
-	// Inserting book into the db.
+	// Inserting book into the b.db.
 	var inserted_book Book
-	stmt := `insert into book (title, category, publisher, book_count, price, fine_per_day, created_at, updated_at, author_id) values ($1, $2, $3, $4, $5, $6, $7, $8, $9) returning id, title, category,  publisher, book_count, price, fine_per_day, created_at, updated_at, author_id;`
+	stmt := `insert into book (title, category, publisher, book_count, price, fine_per_day, created_at, updated_at, author_id) values ($1, $2, $3, $4, $5, $6, $7, $8, $9) returning id, title, category,  publisher, book_count, price, fine_per_day, created_at, updated_at, author_id, version;`
 
-	row = db.QueryRowContext(ctx, stmt, book.Title, book.Category, book.Publisher, book.BookCount, book.Price,
+	row = tx.QueryRowContext(ctx, stmt, book.Title, book.Category, book.Publisher, book.BookCount, book.Price,
 		book.FinePerDay, time.Now(), time.Now(), book.AuthorId)
 	// id, title, category,  publisher, book_count, price, fine_per_day, created_at, updated_at, author_id;
 	err = row.Scan(
@@ -240,17 +377,30 @@ func (b *Book) InsertBook(book Book) (*Book, error) {
 		&inserted_book.CreatedAt,
 		&inserted_book.UpdatedAt,
 		&inserted_book.AuthorId,
+		&inserted_book.Version,
 	)
 
+	if err != nil {
+		return nil, err
+	}
+
+	err = insertOutboxEvent(ctx, tx, EventBookCreated, "book", inserted_book.ID, inserted_book)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+
 	if err != nil {
 		return nil, err
 	}
 	return &inserted_book, nil
 }
 
-// Update book
-func (b *Book) UpdateBook(book_id int, input_json map[string]any) (*Book, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout*3)
+// Update book, provided it is still at the version the caller read
+func (b *BookStore) UpdateBook(ctx context.Context, book_id int, version int, update BookUpdate) (*Book, error) {
+	ctx, cancel := context.WithTimeout(ctx, b.timeouts.Write)
 	defer cancel()
 
 	type fields struct {
@@ -269,22 +419,22 @@ func (b *Book) UpdateBook(book_id int, input_json map[string]any) (*Book, error)
 
 	query_args := make([]any, 0, 7)
 
-	if title_value, ok := input_json["title"]; ok {
+	if update.Title != nil {
 		field.Title = true
 		query_count++
-		query_args = append(query_args, title_value)
+		query_args = append(query_args, *update.Title)
 	}
 
-	if category_value, ok := input_json["category"]; ok {
+	if update.Category != nil {
 		field.Category = true
 		query_count++
-		query_args = append(query_args, category_value)
+		query_args = append(query_args, *update.Category)
 
 		//Category check for the book
 		var category_exists bool
 		category_check_query := `select case when count(*) > 0 then True else False end from category where category_name = $1;`
 
-		row := db.QueryRowContext(ctx, category_check_query, category_value.(string))
+		row := b.conn(ctx).QueryRowContext(ctx, category_check_query, *update.Category)
 		err := row.Scan(&category_exists)
 
 		if err != nil {
@@ -292,48 +442,49 @@ func (b *Book) UpdateBook(book_id int, input_json map[string]any) (*Book, error)
 		}
 
 		if !category_exists {
-			return nil, errors.New(fmt.Sprintf("%v this category does not exists.", category_value))
+			return nil, Invalid("unknown_category", "Category %s does not exist.", *update.Category)
 		}
 
 	}
 
-	if publisher_value, ok := input_json["publisher"]; ok {
+	if update.Publisher != nil {
 		field.Publisher = true
 		query_count++
-		query_args = append(query_args, publisher_value)
+		query_args = append(query_args, *update.Publisher)
 	}
 
-	if book_count_value, ok := input_json["book_count"]; ok {
+	if update.BookCount != nil {
 		field.Book_count = true
 		query_count++
-		query_args = append(query_args, int(book_count_value.(float64)))
+		query_args = append(query_args, *update.BookCount)
 	}
 
-	if price_value, ok := input_json["price"]; ok {
+	if update.Price != nil {
 		field.Price = true
 		query_count++
-		query_args = append(query_args, float32(price_value.(float64)))
+		query_args = append(query_args, *update.Price)
 	}
 
-	if fine_per_day_value, ok := input_json["fine_per_day"]; ok {
+	if update.FinePerDay != nil {
 		field.Fine_per_day = true
 		query_count++
-		query_args = append(query_args, float32(fine_per_day_value.(float64)))
+		query_args = append(query_args, *update.FinePerDay)
 	}
 
-	if author_id_value, ok := input_json["author_id"]; ok {
+	if update.AuthorId != nil {
 		field.Author_id = true
 		query_count++
-		query_args = append(query_args, int(author_id_value.(float64)))
+		query_args = append(query_args, *update.AuthorId)
 	}
 
 	if !(field.Title || field.Category || field.Publisher || field.Book_count || field.Price || field.Fine_per_day || field.Author_id) {
-		return nil, errors.New(fmt.Sprintf("Nonthing to update for book with book_id :: %v", book_id))
+		return nil, Invalid("empty_update", "Nothing to update for book with id %d.", book_id)
 	}
 
 	query_args = append(query_args, time.Now())
 	query_args = append(query_args, book_id)
-	query_count = query_count + 2
+	query_args = append(query_args, version)
+	query_count = query_count + 3
 
 	annotation_list := make([]any, 0, query_count)
 
@@ -350,13 +501,13 @@ func (b *Book) UpdateBook(book_id int, input_json map[string]any) (*Book, error)
 				{{ [if] .Price [then] price = $%d, }}
 				{{ [if] .Fine_per_day [then] fine_per_day = $%d, }} 
 				{{ [if] .Author_id [then] author_id = $%d, }}  
-				updated_at = $%d where id = $%d 
+				updated_at = $%d, version = version + 1 where id = $%d and version = $%d
 				returning id, title, category,  publisher, book_count, 
-				price, fine_per_day, created_at, updated_at, author_id;
+				price, fine_per_day, created_at, updated_at, author_id, version;
 				`, field)
 
 	query = fmt.Sprintf(query, annotation_list...)
-	row := db.QueryRowContext(ctx, query, query_args...)
+	row := b.conn(ctx).QueryRowContext(ctx, query, query_args...)
 
 	var inserted_book Book
 
@@ -371,8 +522,19 @@ func (b *Book) UpdateBook(book_id int, input_json map[string]any) (*Book, error)
 		&inserted_book.CreatedAt,
 		&inserted_book.UpdatedAt,
 		&inserted_book.AuthorId,
+		&inserted_book.Version,
 	)
 
+	if err == sql.ErrNoRows {
+		// Either the book is gone or someone else updated it first.
+		_, get_err := b.GetBookWithId(ctx, book_id)
+
+		if get_err != nil {
+			return nil, get_err
+		}
+		return nil, ErrEditConflict
+	}
+
 	if err != nil {
 		return nil, err
 	}
@@ -380,15 +542,15 @@ func (b *Book) UpdateBook(book_id int, input_json map[string]any) (*Book, error)
 }
 
 // Get a book with id
-func (b *Book) GetBookWithId(id int) (*Book, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+func (b *BookStore) GetBookWithId(ctx context.Context, id int) (*Book, error) {
+	ctx, cancel := context.WithTimeout(ctx, b.timeouts.Read)
 	defer cancel()
 
 	var book Book
 
-	query := `select * from book where id = $1;`
+	query := `select id, title, category, publisher, book_count, price, fine_per_day, author_id, created_at, updated_at, archive, version from book where id = $1;`
 
-	row := db.QueryRowContext(ctx, query, id)
+	row := b.conn(ctx).QueryRowContext(ctx, query, id)
 
 	err := row.Scan(
 		&book.ID,
@@ -402,16 +564,21 @@ func (b *Book) GetBookWithId(id int) (*Book, error) {
 		&book.CreatedAt,
 		&book.UpdatedAt,
 		&book.Archive,
+		&book.Version,
 	)
 
+	if err == sql.ErrNoRows {
+		return nil, NotFound("book_not_found", "Book with id %d does not exist.", id)
+	}
+
 	if err != nil {
 		return nil, err
 	}
 	return &book, nil
 }
 
-func (b *Book) GetBook(input_json map[string]any) ([]*Book_with_name, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout*3)
+func (b *BookStore) GetBook(ctx context.Context, filter BookFilter) ([]*Book_with_name, error) {
+	ctx, cancel := context.WithTimeout(ctx, b.timeouts.List)
 	defer cancel()
 
 	type fields struct {
@@ -420,7 +587,7 @@ func (b *Book) GetBook(input_json map[string]any) ([]*Book_with_name, error) {
 		Publisher  bool
 		AuthorName bool
 	}
-	fmt.Println("here is 1")
+
 	var query string
 	var err error
 
@@ -429,36 +596,35 @@ func (b *Book) GetBook(input_json map[string]any) ([]*Book_with_name, error) {
 	query_args := make([]any, 0, 4)
 	results := make([]*Book_with_name, 0)
 
-	if title_value, ok := input_json["title"]; ok {
+	if filter.Title != "" {
 		field.Title = true
 		query_count++
-		query_args = append(query_args, "%"+title_value.(string)+"%")
+		query_args = append(query_args, "%"+filter.Title+"%")
 	}
 
-	if category_value, ok := input_json["category"]; ok {
+	if filter.Category != "" {
 		field.Category = true
 		query_count++
-		query_args = append(query_args, "%"+category_value.(string)+"%")
+		query_args = append(query_args, "%"+filter.Category+"%")
 
 	}
 
-	if publisher_value, ok := input_json["publisher"]; ok {
+	if filter.Publisher != "" {
 		field.Publisher = true
 		query_count++
-		query_args = append(query_args, "%"+publisher_value.(string)+"%")
+		query_args = append(query_args, "%"+filter.Publisher+"%")
 	}
 
-	if author_id_value, ok := input_json["author_name"]; ok {
+	if filter.AuthorName != "" {
 		field.AuthorName = true
 		query_count++
-		query_args = append(query_args, "%"+author_id_value.(string)+"%")
+		query_args = append(query_args, "%"+filter.AuthorName+"%")
 	}
-	fmt.Println("here is 2")
+
 	if !(field.Title || field.Category || field.Publisher || field.AuthorName) {
 		query = `select t1.id, t1.title, t1.category, t1.publisher, t1.price, t1.fine_per_day,
 					t1.book_count, t2.name, t1.created_at, t1.updated_at 
 					from book as t1 inner join author as t2 on t1.author_id = t2.id order by t1.created_at desc;`
-		fmt.Println(query, "inside the block")
 	} else {
 		annotation_list := make([]any, 0, query_count)
 
@@ -480,12 +646,14 @@ func (b *Book) GetBook(input_json map[string]any) ([]*Book_with_name, error) {
 		}
 		query = fmt.Sprintf(query, annotation_list...)
 	}
-	fmt.Println(query, "outside the block")
-	rows, err := db.QueryContext(ctx, query, query_args...)
+
+	rows, err := b.replicaConn(ctx).QueryContext(ctx, query, query_args...)
 
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
+
 	for rows.Next() {
 		var output_book Book_with_name
 
@@ -507,36 +675,47 @@ func (b *Book) GetBook(input_json map[string]any) ([]*Book_with_name, error) {
 		}
 		results = append(results, &output_book)
 	}
+	return results, rows.Err()
+}
 
-	return results, nil
+// Users stored in Postgres.
+type UserStore struct {
+	pgStore
 }
 
-func (u *User) CreateUser(user User) (*User, error) {
+func (u *UserStore) CreateUser(ctx context.Context, user User) (*User, error) {
 
-	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout*2)
+	ctx, cancel := context.WithTimeout(ctx, u.timeouts.Transaction)
 
 	defer cancel()
 
+	tx, err := u.begin(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
 	// User exists check
 	var user_exists bool
 
 	user_exists_query := `select case when count(*) > 0 then True else False end from users where email = $1;`
-	row := db.QueryRowContext(ctx, user_exists_query, user.Email)
-	err := row.Scan(&user_exists)
+	row := tx.QueryRowContext(ctx, user_exists_query, user.Email)
+	err = row.Scan(&user_exists)
 
 	if err != nil {
 		return nil, err
 	}
 
 	if user_exists {
-		return nil, errors.New(fmt.Sprintf("User with email: %s already exists", user.Email))
+		return nil, ErrEmailTaken
 	}
 
 	// Inserting the user into db
 	var inserted_user User
-	insert_stmt := `insert into users (name, email, password, phone_number, created_at, updated_at, is_active, is_admin) values ($1, $2, $3, $4, $5, $6, $7, $8) returning id, name, email, password, phone_number, created_at, updated_at, is_active, is_admin;`
+	insert_stmt := `insert into users (name, email, password, phone_number, created_at, updated_at, is_active, is_admin) values ($1, $2, $3, $4, $5, $6, $7, $8) returning id, name, email, password, phone_number, created_at, updated_at, is_active, is_admin, member_type, version;`
 
-	row = db.QueryRowContext(ctx, insert_stmt,
+	row = tx.QueryRowContext(ctx, insert_stmt,
 		user.Name,
 		user.Email,
 		user.Password,
@@ -556,25 +735,48 @@ func (u *User) CreateUser(user User) (*User, error) {
 		&inserted_user.UpdatedAt,
 		&inserted_user.IsActive,
 		&inserted_user.IsAdmin,
+		&inserted_user.MemberType,
+		&inserted_user.Version,
 	)
 
+	if err != nil {
+		return nil, err
+	}
+
+	// The password hash stays out of the event.
+	err = insertOutboxEvent(ctx, tx, EventUserRegistered, "user", inserted_user.ID, map[string]any{
+		"id":           inserted_user.ID,
+		"name":         inserted_user.Name,
+		"email":        inserted_user.Email,
+		"phone_number": inserted_user.PhoneNumber,
+		"member_type":  inserted_user.MemberType,
+		"is_active":    inserted_user.IsActive,
+		"created_at":   inserted_user.CreatedAt,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+
 	if err != nil {
 		return nil, err
 	}
 	return &inserted_user, nil
 }
 
-func (u *User) GetUserWithEmail(user User) (*User, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+func (u *UserStore) GetUserWithEmail(ctx context.Context, user User) (*User, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.timeouts.Read)
 
 	defer cancel()
 
 	// User exists check
 	var existing_user User
 
-	get_user_query := `select * from users where email = $1;`
+	get_user_query := `select id, name, email, password, phone_number, created_at, updated_at, is_active, is_admin, member_type, version from users where email = $1;`
 
-	row := db.QueryRowContext(ctx, get_user_query, user.Email)
+	row := u.conn(ctx).QueryRowContext(ctx, get_user_query, user.Email)
 
 	err := row.Scan(
 		&existing_user.ID,
@@ -586,15 +788,619 @@ func (u *User) GetUserWithEmail(user User) (*User, error) {
 		&existing_user.UpdatedAt,
 		&existing_user.IsActive,
 		&existing_user.IsAdmin,
+		&existing_user.MemberType,
+		&existing_user.Version,
 	)
 
 	if err == sql.ErrNoRows {
-		return nil, errors.New(fmt.Sprintf("User with email %s does not exist.", user.Email))
+		return nil, NotFound("user_not_found", "User does not exist.")
 	}
 
+	if err != nil {
+		return nil, err
+	}
 	return &existing_user, nil
 }
 
-func (b *BookBorrowList) CreateBookBorrowList(input_json map[string]any) (*BookBorrowList, error) {
-	return nil, nil
+// Get user with id
+func (u *UserStore) GetUserWithId(ctx context.Context, id int) (*User, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.timeouts.Read)
+
+	defer cancel()
+
+	var existing_user User
+
+	get_user_query := `select id, name, email, password, phone_number, created_at, updated_at, is_active, is_admin, member_type, version from users where id = $1;`
+
+	row := u.conn(ctx).QueryRowContext(ctx, get_user_query, id)
+
+	err := row.Scan(
+		&existing_user.ID,
+		&existing_user.Name,
+		&existing_user.Email,
+		&existing_user.Password,
+		&existing_user.PhoneNumber,
+		&existing_user.CreatedAt,
+		&existing_user.UpdatedAt,
+		&existing_user.IsActive,
+		&existing_user.IsAdmin,
+		&existing_user.MemberType,
+		&existing_user.Version,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, NotFound("user_not_found", "User with id %d does not exist.", id)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+	return &existing_user, nil
+}
+
+// Activate the account of a user
+func (u *UserStore) ActivateUser(ctx context.Context, id int) error {
+	ctx, cancel := context.WithTimeout(ctx, u.timeouts.Write)
+
+	defer cancel()
+
+	stmt := `update users set is_active = true, updated_at = $1, version = version + 1 where id = $2 and is_active = false;`
+
+	result, err := u.conn(ctx).ExecContext(ctx, stmt, time.Now(), id)
+
+	if err != nil {
+		return err
+	}
+
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		_, get_err := u.GetUserWithId(ctx, id)
+
+		if get_err != nil {
+			return get_err
+		}
+		return Conflict("user_already_active", "User with id %d is already active.", id)
+	}
+	return nil
+}
+
+// Update the member type of a user
+func (u *UserStore) UpdateMemberType(ctx context.Context, id int, member_type string) error {
+	ctx, cancel := context.WithTimeout(ctx, u.timeouts.Write)
+
+	defer cancel()
+
+	stmt := `update users set member_type = $1, updated_at = $2, version = version + 1 where id = $3;`
+
+	result, err := u.conn(ctx).ExecContext(ctx, stmt, member_type, time.Now(), id)
+
+	if err != nil {
+		return err
+	}
+
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return NotFound("user_not_found", "User with id %d does not exist.", id)
+	}
+	return nil
+}
+
+// Update the contact details of a user, provided it is still at the version the caller read
+func (u *UserStore) UpdateUser(ctx context.Context, id int, version int, user User) (*User, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.timeouts.Write)
+
+	defer cancel()
+
+	var updated_user User
+
+	stmt := `update users set name = $1, email = $2, phone_number = $3, updated_at = $4, version = version + 1 where id = $5 and version = $6
+				returning id, name, email, password, phone_number, created_at, updated_at, is_active, is_admin, member_type, version;`
+
+	row := u.conn(ctx).QueryRowContext(ctx, stmt, user.Name, user.Email, user.PhoneNumber, time.Now(), id, version)
+
+	err := row.Scan(
+		&updated_user.ID,
+		&updated_user.Name,
+		&updated_user.Email,
+		&updated_user.Password,
+		&updated_user.PhoneNumber,
+		&updated_user.CreatedAt,
+		&updated_user.UpdatedAt,
+		&updated_user.IsActive,
+		&updated_user.IsAdmin,
+		&updated_user.MemberType,
+		&updated_user.Version,
+	)
+
+	if err == sql.ErrNoRows {
+		_, get_err := u.GetUserWithId(ctx, id)
+
+		if get_err != nil {
+			return nil, get_err
+		}
+		return nil, ErrEditConflict
+	}
+
+	if err != nil {
+		return nil, err
+	}
+	return &updated_user, nil
+}
+
+// Loans, the borrow lists and their items, stored in Postgres.
+type LoanStore struct {
+	pgStore
+}
+
+// Create a borrow list with its items and take the books out of stock
+func (b *LoanStore) CreateBookBorrowList(ctx context.Context, borrow_list BookBorrowList) (*BookBorrowList, error) {
+	ctx, cancel := context.WithTimeout(ctx, b.timeouts.Transaction)
+	defer cancel()
+
+	tx, err := b.begin(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var created_list BookBorrowList
+
+	list_stmt := `insert into book_borrow_list (due_date, user_id, closed, created_at, updated_at) values ($1, $2, false, $3, $4) returning id, due_date, user_id, closed, created_at, updated_at;`
+
+	row := tx.QueryRowContext(ctx, list_stmt, borrow_list.DueDate, borrow_list.UserId, time.Now(), time.Now())
+
+	err = row.Scan(
+		&created_list.ID,
+		&created_list.DueDate,
+		&created_list.UserId,
+		&created_list.Closed,
+		&created_list.CreateAt,
+		&created_list.UpdatedAt,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	stock_stmt := `update book set book_count = book_count - 1 where id = $1 and book_count > 0 and archive = false;`
+	item_stmt := `insert into book_borrow (book_id, list_id, due_date, fine_per_day, max_fine, book_revision) values ($1, $2, $3, $4, $5, $6) returning id, book_id, list_id, returned, extended, due_date, renew_count, returned_at, fine_per_day, max_fine, overdue, book_revision;`
+
+	for _, item := range borrow_list.BookList {
+		result, err := tx.ExecContext(ctx, stock_stmt, item.BookId)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if affected, _ := result.RowsAffected(); affected == 0 {
+			return nil, Conflict("book_unavailable", "Book with id %d is not available for lending.", item.BookId)
+		}
+
+		var created_item BookBorrorw
+
+		row = tx.QueryRowContext(ctx, item_stmt, item.BookId, created_list.ID, item.DueDate, item.FinePerDay, item.MaxFine, item.BookRevision)
+
+		err = row.Scan(
+			&created_item.ID,
+			&created_item.BookId,
+			&created_item.ListId,
+			&created_item.Returned,
+			&created_item.Extended,
+			&created_item.DueDate,
+			&created_item.RenewCount,
+			&created_item.ReturnedAt,
+			&created_item.FinePerDay,
+			&created_item.MaxFine,
+			&created_item.Overdue,
+			&created_item.BookRevision,
+		)
+
+		if err != nil {
+			return nil, err
+		}
+		created_list.BookList = append(created_list.BookList, &created_item)
+
+		// A hold waiting at the desk for this member is collected with the loan.
+		hold_stmt := `update book_hold set status = 'collected', updated_at = $1 where user_id = $2 and book_id = $3 and status = 'ready';`
+
+		_, err = tx.ExecContext(ctx, hold_stmt, time.Now(), created_list.UserId, item.BookId)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = insertOutboxEvent(ctx, tx, EventLoanCreated, "loan", created_list.ID, created_list)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+
+	if err != nil {
+		return nil, err
+	}
+	return &created_list, nil
+}
+
+// Get a borrowed item with id
+func (b *LoanStore) GetBookBorrow(ctx context.Context, id int) (*BookBorrorw, error) {
+	ctx, cancel := context.WithTimeout(ctx, b.timeouts.Read)
+	defer cancel()
+
+	var item BookBorrorw
+
+	query := `select id, book_id, list_id, returned, extended, due_date, renew_count, returned_at, fine_per_day, max_fine, overdue, book_revision from book_borrow where id = $1;`
+
+	row := b.conn(ctx).QueryRowContext(ctx, query, id)
+
+	err := row.Scan(
+		&item.ID,
+		&item.BookId,
+		&item.ListId,
+		&item.Returned,
+		&item.Extended,
+		&item.DueDate,
+		&item.RenewCount,
+		&item.ReturnedAt,
+		&item.FinePerDay,
+		&item.MaxFine,
+		&item.Overdue,
+		&item.BookRevision,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, NotFound("loan_not_found", "Borrowed item with id %d does not exist.", id)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// Get the borrow list with id without its items
+func (b *LoanStore) GetBookBorrowList(ctx context.Context, id int) (*BookBorrowList, error) {
+	ctx, cancel := context.WithTimeout(ctx, b.timeouts.Read)
+	defer cancel()
+
+	var borrow_list BookBorrowList
+
+	query := `select id, due_date, user_id, closed, created_at, updated_at from book_borrow_list where id = $1;`
+
+	row := b.conn(ctx).QueryRowContext(ctx, query, id)
+
+	err := row.Scan(
+		&borrow_list.ID,
+		&borrow_list.DueDate,
+		&borrow_list.UserId,
+		&borrow_list.Closed,
+		&borrow_list.CreateAt,
+		&borrow_list.UpdatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, NotFound("loan_not_found", "Borrow list with id %d does not exist.", id)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+	return &borrow_list, nil
+}
+
+// Count the books a user has not yet returned, per category
+func (b *LoanStore) GetOpenLoanCounts(ctx context.Context, user_id int) (map[string]int, error) {
+	ctx, cancel := context.WithTimeout(ctx, b.timeouts.List)
+	defer cancel()
+
+	query := `select t3.category, count(*) from book_borrow as t1
+				inner join book_borrow_list as t2 on t1.list_id = t2.id
+				inner join book as t3 on t1.book_id = t3.id
+				where t2.user_id = $1 and t1.returned = false group by t3.category;`
+
+	rows, err := b.conn(ctx).QueryContext(ctx, query, user_id)
+
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+
+	for rows.Next() {
+		var category string
+		var count int
+
+		err = rows.Scan(&category, &count)
+
+		if err != nil {
+			return nil, err
+		}
+		counts[category] = count
+	}
+	return counts, rows.Err()
+}
+
+// Mark a borrowed item returned, put the book back in stock and close the list once everything is back
+func (b *LoanStore) ReturnBookBorrow(ctx context.Context, id int, returned_at time.Time) (*BookBorrorw, error) {
+	ctx, cancel := context.WithTimeout(ctx, b.timeouts.Transaction)
+	defer cancel()
+
+	tx, err := b.begin(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var item BookBorrorw
+
+	stmt := `update book_borrow set returned = true, returned_at = $1 where id = $2 and returned = false returning id, book_id, list_id, returned, extended, due_date, renew_count, returned_at, fine_per_day, max_fine, overdue, book_revision;`
+
+	row := tx.QueryRowContext(ctx, stmt, returned_at, id)
+
+	err = row.Scan(
+		&item.ID,
+		&item.BookId,
+		&item.ListId,
+		&item.Returned,
+		&item.Extended,
+		&item.DueDate,
+		&item.RenewCount,
+		&item.ReturnedAt,
+		&item.FinePerDay,
+		&item.MaxFine,
+		&item.Overdue,
+		&item.BookRevision,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, Conflict("loan_already_returned", "Borrowed item with id %d does not exist or is already returned.", id)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.ExecContext(ctx, `update book set book_count = book_count + 1 where id = $1;`, item.BookId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	close_stmt := `update book_borrow_list set closed = true, updated_at = $1 where id = $2
+					and not exists (select 1 from book_borrow where list_id = $2 and returned = false);`
+
+	_, err = tx.ExecContext(ctx, close_stmt, returned_at, item.ListId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = insertLoanItemEvent(ctx, tx, EventLoanReturned, &item)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+
+	if err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// Push the due date of a borrowed item for a renewal
+func (b *LoanStore) RenewBookBorrow(ctx context.Context, id int, due_date time.Time) (*BookBorrorw, error) {
+	ctx, cancel := context.WithTimeout(ctx, b.timeouts.Transaction)
+	defer cancel()
+
+	tx, err := b.begin(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var item BookBorrorw
+
+	stmt := `update book_borrow set due_date = $1, renew_count = renew_count + 1, extended = true where id = $2 and returned = false returning id, book_id, list_id, returned, extended, due_date, renew_count, returned_at, fine_per_day, max_fine, overdue, book_revision;`
+
+	row := tx.QueryRowContext(ctx, stmt, due_date, id)
+
+	err = row.Scan(
+		&item.ID,
+		&item.BookId,
+		&item.ListId,
+		&item.Returned,
+		&item.Extended,
+		&item.DueDate,
+		&item.RenewCount,
+		&item.ReturnedAt,
+		&item.FinePerDay,
+		&item.MaxFine,
+		&item.Overdue,
+		&item.BookRevision,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, Conflict("loan_already_returned", "Borrowed item with id %d does not exist or is already returned.", id)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	list_stmt := `update book_borrow_list set due_date = greatest(due_date, $1), updated_at = $2 where id = $3;`
+
+	_, err = tx.ExecContext(ctx, list_stmt, item.DueDate, time.Now(), item.ListId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = insertLoanItemEvent(ctx, tx, EventLoanRenewed, &item)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+
+	if err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// Borrowed item due soon along with the details needed to remind the member
+type DueSoonLoan struct {
+	BorrowId    int       `json:"borrow_id"`
+	BookId      int       `json:"book_id"`
+	Title       string    `json:"title"`
+	DueDate     time.Time `json:"due_date"`
+	UserId      int       `json:"user_id"`
+	Name        string    `json:"name"`
+	Email       string    `json:"email"`
+	PhoneNumber string    `json:"phone_number"`
+}
+
+// Flag the items not returned by their due date as overdue
+func (b *LoanStore) MarkOverdueLoans(ctx context.Context, now time.Time) ([]*BookBorrorw, error) {
+	ctx, cancel := context.WithTimeout(ctx, b.timeouts.Transaction)
+	defer cancel()
+
+	tx, err := b.begin(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	stmt := `update book_borrow set overdue = true where returned = false and overdue = false and due_date < $1 returning id, book_id, list_id, returned, extended, due_date, renew_count, returned_at, fine_per_day, max_fine, overdue, book_revision;`
+
+	rows, err := tx.QueryContext(ctx, stmt, now)
+
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := make([]*BookBorrorw, 0)
+
+	for rows.Next() {
+		var item BookBorrorw
+
+		err = rows.Scan(
+			&item.ID,
+			&item.BookId,
+			&item.ListId,
+			&item.Returned,
+			&item.Extended,
+			&item.DueDate,
+			&item.RenewCount,
+			&item.ReturnedAt,
+			&item.FinePerDay,
+			&item.MaxFine,
+			&item.Overdue,
+			&item.BookRevision,
+		)
+
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, &item)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, item := range items {
+		err = insertLoanItemEvent(ctx, tx, EventLoanOverdue, item)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = tx.Commit()
+
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// Get the items due before the given time for which no reminder has been sent
+func (b *LoanStore) GetDueSoonLoans(ctx context.Context, now, due_before time.Time) ([]*DueSoonLoan, error) {
+	ctx, cancel := context.WithTimeout(ctx, b.timeouts.List)
+	defer cancel()
+
+	query := `select t1.id, t1.book_id, t3.title, t1.due_date, t4.id, t4.name, t4.email, t4.phone_number
+				from book_borrow as t1
+				inner join book_borrow_list as t2 on t1.list_id = t2.id
+				inner join book as t3 on t1.book_id = t3.id
+				inner join users as t4 on t2.user_id = t4.id
+				where t1.returned = false and t1.reminder_sent_at is null and t1.due_date >= $1 and t1.due_date < $2
+				order by t1.due_date;`
+
+	rows, err := b.conn(ctx).QueryContext(ctx, query, now, due_before)
+
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	loans := make([]*DueSoonLoan, 0)
+
+	for rows.Next() {
+		var loan DueSoonLoan
+
+		err = rows.Scan(
+			&loan.BorrowId,
+			&loan.BookId,
+			&loan.Title,
+			&loan.DueDate,
+			&loan.UserId,
+			&loan.Name,
+			&loan.Email,
+			&loan.PhoneNumber,
+		)
+
+		if err != nil {
+			return nil, err
+		}
+		loans = append(loans, &loan)
+	}
+	return loans, rows.Err()
+}
+
+// Record that the due soon reminder of a borrowed item went out
+func (b *LoanStore) MarkReminderSent(ctx context.Context, id int, sent_at time.Time) error {
+	ctx, cancel := context.WithTimeout(ctx, b.timeouts.Write)
+	defer cancel()
+
+	_, err := b.conn(ctx).ExecContext(ctx, `update book_borrow set reminder_sent_at = $1 where id = $2;`, sent_at, id)
+
+	return err
+}
+
+// Borrowed item as published in the loan events, along with the member holding it
+type LoanItemEvent struct {
+	UserId int `json:"user_id"`
+	*BookBorrorw
+}
+
+func insertLoanItemEvent(ctx context.Context, tx querier, event_type string, item *BookBorrorw) error {
+	var user_id int
+
+	err := tx.QueryRowContext(ctx, `select user_id from book_borrow_list where id = $1;`, item.ListId).Scan(&user_id)
+
+	if err != nil {
+		return err
+	}
+	return insertOutboxEvent(ctx, tx, event_type, "loan_item", item.ID, LoanItemEvent{UserId: user_id, BookBorrorw: item})
 }
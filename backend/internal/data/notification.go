@@ -0,0 +1,202 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+const (
+	DeliveryPending = "pending"
+	DeliverySent    = "sent"
+	DeliveryFailed  = "failed"
+)
+
+type NotificationPreference struct {
+	UserId    int       `json:"user_id"`
+	Channel   string    `json:"channel"`
+	Enabled   bool      `json:"enabled"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type NotificationDelivery struct {
+	ID            int        `json:"id"`
+	UserId        int        `json:"user_id"`
+	Event         string     `json:"event"`
+	Channel       string     `json:"channel"`
+	Recipient     string     `json:"recipient"`
+	Subject       string     `json:"subject"`
+	Body          string     `json:"body"`
+	Status        string     `json:"status"`
+	Attempts      int        `json:"attempts"`
+	LastError     string     `json:"last_error"`
+	NextAttemptAt *time.Time `json:"next_attempt_at"`
+	SentAt        *time.Time `json:"sent_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+const notificationDeliveryColumns = `id, user_id, event, channel, recipient, subject, body, status, attempts, last_error, next_attempt_at, sent_at, created_at, updated_at`
+
+func scanNotificationDelivery(row interface{ Scan(...any) error }) (*NotificationDelivery, error) {
+	var delivery NotificationDelivery
+
+	err := row.Scan(
+		&delivery.ID,
+		&delivery.UserId,
+		&delivery.Event,
+		&delivery.Channel,
+		&delivery.Recipient,
+		&delivery.Subject,
+		&delivery.Body,
+		&delivery.Status,
+		&delivery.Attempts,
+		&delivery.LastError,
+		&delivery.NextAttemptAt,
+		&delivery.SentAt,
+		&delivery.CreatedAt,
+		&delivery.UpdatedAt,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+func scanNotificationDeliveries(rows *sql.Rows) ([]*NotificationDelivery, error) {
+	defer rows.Close()
+
+	deliveries := make([]*NotificationDelivery, 0)
+
+	for rows.Next() {
+		delivery, err := scanNotificationDelivery(rows)
+
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	return deliveries, rows.Err()
+}
+
+// Notification preferences stored in Postgres.
+type NotificationPreferenceStore struct {
+	pgStore
+}
+
+// Get the channel preferences a user has set
+func (p *NotificationPreferenceStore) GetUserPreferences(ctx context.Context, user_id int) ([]*NotificationPreference, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.timeouts.List)
+	defer cancel()
+
+	query := `select user_id, channel, enabled, updated_at from notification_preference where user_id = $1 order by channel;`
+
+	rows, err := p.conn(ctx).QueryContext(ctx, query, user_id)
+
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	preferences := make([]*NotificationPreference, 0)
+
+	for rows.Next() {
+		var preference NotificationPreference
+
+		err = rows.Scan(&preference.UserId, &preference.Channel, &preference.Enabled, &preference.UpdatedAt)
+
+		if err != nil {
+			return nil, err
+		}
+		preferences = append(preferences, &preference)
+	}
+	return preferences, rows.Err()
+}
+
+// Enable or disable a channel for a user
+func (p *NotificationPreferenceStore) UpsertPreference(ctx context.Context, preference NotificationPreference) (*NotificationPreference, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.timeouts.Write)
+	defer cancel()
+
+	var saved_preference NotificationPreference
+
+	stmt := `insert into notification_preference (user_id, channel, enabled, updated_at) values ($1, $2, $3, $4)
+				on conflict (user_id, channel) do update set enabled = excluded.enabled, updated_at = excluded.updated_at
+				returning user_id, channel, enabled, updated_at;`
+
+	row := p.conn(ctx).QueryRowContext(ctx, stmt, preference.UserId, preference.Channel, preference.Enabled, time.Now())
+
+	err := row.Scan(&saved_preference.UserId, &saved_preference.Channel, &saved_preference.Enabled, &saved_preference.UpdatedAt)
+
+	if err != nil {
+		return nil, err
+	}
+	return &saved_preference, nil
+}
+
+// Notification deliveries stored in Postgres.
+type NotificationDeliveryStore struct {
+	pgStore
+}
+
+// Queue a notification for delivery
+func (d *NotificationDeliveryStore) InsertDelivery(ctx context.Context, delivery NotificationDelivery) (*NotificationDelivery, error) {
+	ctx, cancel := context.WithTimeout(ctx, d.timeouts.Write)
+	defer cancel()
+
+	stmt := `insert into notification_delivery (user_id, event, channel, recipient, subject, body, status, next_attempt_at, created_at, updated_at)
+				values ($1, $2, $3, $4, $5, $6, 'pending', $7, $8, $8) returning ` + notificationDeliveryColumns + `;`
+
+	row := d.conn(ctx).QueryRowContext(ctx, stmt, delivery.UserId, delivery.Event, delivery.Channel, delivery.Recipient,
+		delivery.Subject, delivery.Body, delivery.NextAttemptAt, time.Now())
+
+	return scanNotificationDelivery(row)
+}
+
+// Record the outcome of a delivery attempt
+func (d *NotificationDeliveryStore) RecordAttempt(ctx context.Context, id int, status, last_error string, next_attempt_at *time.Time) error {
+	ctx, cancel := context.WithTimeout(ctx, d.timeouts.Write)
+	defer cancel()
+
+	stmt := `update notification_delivery set status = $1, attempts = attempts + 1, last_error = $2, next_attempt_at = $3,
+				sent_at = case when $1 = 'sent' then $4 else sent_at end, updated_at = $4 where id = $5;`
+
+	_, err := d.conn(ctx).ExecContext(ctx, stmt, status, last_error, next_attempt_at, time.Now(), id)
+
+	return err
+}
+
+// Claim the pending deliveries due for an attempt, pushing their next attempt out by lease so
+// that no other instance picks them up while they are being sent
+func (d *NotificationDeliveryStore) ClaimDueDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*NotificationDelivery, error) {
+	ctx, cancel := context.WithTimeout(ctx, d.timeouts.Batch)
+	defer cancel()
+
+	stmt := `update notification_delivery set next_attempt_at = $1 where id in (
+				select id from notification_delivery where status = 'pending' and next_attempt_at <= $2
+				order by next_attempt_at limit $3 for update skip locked)
+				returning ` + notificationDeliveryColumns + `;`
+
+	rows, err := d.conn(ctx).QueryContext(ctx, stmt, now.Add(lease), now, limit)
+
+	if err != nil {
+		return nil, err
+	}
+	return scanNotificationDeliveries(rows)
+}
+
+// Get the latest deliveries to a user
+func (d *NotificationDeliveryStore) GetUserDeliveries(ctx context.Context, user_id int, limit int) ([]*NotificationDelivery, error) {
+	ctx, cancel := context.WithTimeout(ctx, d.timeouts.List)
+	defer cancel()
+
+	query := `select ` + notificationDeliveryColumns + ` from notification_delivery where user_id = $1 order by created_at desc limit $2;`
+
+	rows, err := d.conn(ctx).QueryContext(ctx, query, user_id, limit)
+
+	if err != nil {
+		return nil, err
+	}
+	return scanNotificationDeliveries(rows)
+}
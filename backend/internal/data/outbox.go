@@ -0,0 +1,147 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"sort"
+	"time"
+)
+
+const (
+	EventBookCreated    = "book.created"
+	EventUserRegistered = "user.registered"
+	EventLoanCreated    = "loan.created"
+	EventLoanReturned   = "loan.returned"
+	EventLoanRenewed    = "loan.renewed"
+	EventLoanOverdue    = "loan.overdue"
+	EventFineCharged    = "fine.charged"
+	EventFinePaid       = "fine.paid"
+	EventFineWaived     = "fine.waived"
+	EventFineRefunded   = "fine.refunded"
+)
+
+// Every event type written to the outbox.
+var EventTypes = []string{
+	EventBookCreated,
+	EventUserRegistered,
+	EventLoanCreated,
+	EventLoanReturned,
+	EventLoanRenewed,
+	EventLoanOverdue,
+	EventFineCharged,
+	EventFinePaid,
+	EventFineWaived,
+	EventFineRefunded,
+}
+
+type OutboxEvent struct {
+	ID            int64           `json:"id"`
+	EventType     string          `json:"event_type"`
+	AggregateType string          `json:"aggregate_type"`
+	AggregateId   int             `json:"aggregate_id"`
+	Payload       json.RawMessage `json:"payload"`
+	Attempts      int             `json:"attempts"`
+	LastError     string          `json:"last_error"`
+	NextAttemptAt time.Time       `json:"next_attempt_at"`
+	PublishedAt   *time.Time      `json:"published_at"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
+
+// Satisfied by both *sql.DB and *sql.Tx.
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// Write an event to the outbox. It is meant to be called with the transaction making the
+// change the event describes, so the event is stored if and only if the change is.
+func insertOutboxEvent(ctx context.Context, tx execer, event_type, aggregate_type string, aggregate_id int, payload any) error {
+	encoded_payload, err := json.Marshal(payload)
+
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	stmt := `insert into outbox_event (event_type, aggregate_type, aggregate_id, payload, next_attempt_at, created_at) values ($1, $2, $3, $4, $5, $6);`
+
+	_, err = tx.ExecContext(ctx, stmt, event_type, aggregate_type, aggregate_id, string(encoded_payload), now, now)
+
+	return err
+}
+
+// The outbox stored in Postgres.
+type OutboxEventStore struct {
+	pgStore
+}
+
+// Claim the unpublished events due for publishing, oldest first, pushing their next attempt
+// out by lease so that no other instance picks them up while they are being published
+func (o *OutboxEventStore) ClaimEvents(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*OutboxEvent, error) {
+	ctx, cancel := context.WithTimeout(ctx, o.timeouts.Batch)
+	defer cancel()
+
+	stmt := `update outbox_event set next_attempt_at = $1 where id in (
+				select id from outbox_event where published_at is null and next_attempt_at <= $2
+				order by id limit $3 for update skip locked)
+				returning id, event_type, aggregate_type, aggregate_id, payload, attempts, last_error, next_attempt_at, published_at, created_at;`
+
+	rows, err := o.conn(ctx).QueryContext(ctx, stmt, now.Add(lease), now, limit)
+
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := make([]*OutboxEvent, 0)
+
+	for rows.Next() {
+		var event OutboxEvent
+		var payload []byte
+
+		err = rows.Scan(
+			&event.ID,
+			&event.EventType,
+			&event.AggregateType,
+			&event.AggregateId,
+			&payload,
+			&event.Attempts,
+			&event.LastError,
+			&event.NextAttemptAt,
+			&event.PublishedAt,
+			&event.CreatedAt,
+		)
+
+		if err != nil {
+			return nil, err
+		}
+		event.Payload = payload
+		events = append(events, &event)
+	}
+
+	// The update returns rows in no particular order.
+	sort.Slice(events, func(i, j int) bool { return events[i].ID < events[j].ID })
+	return events, rows.Err()
+}
+
+// Mark an event published
+func (o *OutboxEventStore) MarkPublished(ctx context.Context, id int64) error {
+	ctx, cancel := context.WithTimeout(ctx, o.timeouts.Write)
+	defer cancel()
+
+	_, err := o.conn(ctx).ExecContext(ctx, `update outbox_event set published_at = $1, last_error = '' where id = $2;`, time.Now(), id)
+
+	return err
+}
+
+// Record a failed publish and when to try again
+func (o *OutboxEventStore) RecordFailure(ctx context.Context, id int64, last_error string, next_attempt_at time.Time) error {
+	ctx, cancel := context.WithTimeout(ctx, o.timeouts.Write)
+	defer cancel()
+
+	stmt := `update outbox_event set attempts = attempts + 1, last_error = $1, next_attempt_at = $2 where id = $3;`
+
+	_, err := o.conn(ctx).ExecContext(ctx, stmt, last_error, next_attempt_at, id)
+
+	return err
+}
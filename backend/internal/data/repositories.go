@@ -0,0 +1,180 @@
+package data
+
+import (
+	"context"
+	"time"
+)
+
+type AuthorRepository interface {
+	GetAuthorWithId(ctx context.Context, id int) (Author, error)
+	// Authors whose name contains name, every author when it is empty.
+	GetAuthorWithDetails(ctx context.Context, name string) ([]Author, error)
+	InsertAuthor(ctx context.Context, author Author) (*Author, error)
+	// Update the author if it is still at version, ErrEditConflict otherwise.
+	UpdateAuthor(ctx context.Context, id int, version int, author Author) (*Author, error)
+}
+
+type BookRepository interface {
+	InsertBook(ctx context.Context, book Book) (*Book, error)
+	// Update the fields set in update if the book is still at version, ErrEditConflict otherwise.
+	UpdateBook(ctx context.Context, book_id int, version int, update BookUpdate) (*Book, error)
+	GetBookWithId(ctx context.Context, id int) (*Book, error)
+	GetBook(ctx context.Context, filter BookFilter) ([]*Book_with_name, error)
+}
+
+type UserRepository interface {
+	CreateUser(ctx context.Context, user User) (*User, error)
+	GetUserWithEmail(ctx context.Context, user User) (*User, error)
+	GetUserWithId(ctx context.Context, id int) (*User, error)
+	ActivateUser(ctx context.Context, id int) error
+	UpdateMemberType(ctx context.Context, id int, member_type string) error
+	// Update the contact details if the user is still at version, ErrEditConflict otherwise.
+	UpdateUser(ctx context.Context, id int, version int, user User) (*User, error)
+}
+
+type LoanRepository interface {
+	// Create the borrow list with its items, taking the books out of stock.
+	CreateBookBorrowList(ctx context.Context, borrow_list BookBorrowList) (*BookBorrowList, error)
+	GetBookBorrow(ctx context.Context, id int) (*BookBorrorw, error)
+	GetBookBorrowList(ctx context.Context, id int) (*BookBorrowList, error)
+	// Number of books the user has on loan, by category.
+	GetOpenLoanCounts(ctx context.Context, user_id int) (map[string]int, error)
+	// Close the item and put the book back in stock.
+	ReturnBookBorrow(ctx context.Context, id int, returned_at time.Time) (*BookBorrorw, error)
+	RenewBookBorrow(ctx context.Context, id int, due_date time.Time) (*BookBorrorw, error)
+	// Flag the open items due before now as overdue, returning the ones newly flagged.
+	MarkOverdueLoans(ctx context.Context, now time.Time) ([]*BookBorrorw, error)
+	// Open items due between now and due_before that have not been reminded of yet.
+	GetDueSoonLoans(ctx context.Context, now, due_before time.Time) ([]*DueSoonLoan, error)
+	MarkReminderSent(ctx context.Context, id int, sent_at time.Time) error
+}
+
+type FineEntryRepository interface {
+	InsertFineEntry(ctx context.Context, entry FineEntry) (*FineEntry, error)
+	// Amount the user owes, charges and refunds less payments and waivers.
+	GetUserBalance(ctx context.Context, user_id int) (float32, error)
+	// Amount the user paid less the refunds.
+	GetUserNetPaid(ctx context.Context, user_id int) (float32, error)
+	GetUserEntries(ctx context.Context, user_id int) ([]*FineEntry, error)
+}
+
+type BorrowPolicyRepository interface {
+	GetPolicies(ctx context.Context) ([]*BorrowPolicy, error)
+	GetPolicyWithId(ctx context.Context, id int) (*BorrowPolicy, error)
+	InsertPolicy(ctx context.Context, policy BorrowPolicy) (*BorrowPolicy, error)
+	UpdatePolicy(ctx context.Context, id int, policy BorrowPolicy) (*BorrowPolicy, error)
+	DeletePolicy(ctx context.Context, id int) error
+}
+
+type LibraryHoursRepository interface {
+	// The hours of the seven weekdays, starting on Sunday.
+	GetHours(ctx context.Context) ([]*LibraryHours, error)
+	UpdateHours(ctx context.Context, day LibraryHours) (*LibraryHours, error)
+}
+
+type LibraryClosureRepository interface {
+	GetClosures(ctx context.Context) ([]*LibraryClosure, error)
+	GetClosureWithId(ctx context.Context, id int) (*LibraryClosure, error)
+	InsertClosure(ctx context.Context, closure LibraryClosure) (*LibraryClosure, error)
+	DeleteClosure(ctx context.Context, id int) error
+}
+
+type BookHoldRepository interface {
+	InsertHold(ctx context.Context, book_id, user_id int) (*BookHold, error)
+	GetHoldWithId(ctx context.Context, id int) (*BookHold, error)
+	GetUserHolds(ctx context.Context, user_id int) ([]*BookHold, error)
+	// Move the hold from from_status to to_status, a Conflict when it is not in from_status.
+	UpdateHoldStatus(ctx context.Context, id int, from_status, to_status string, ready_at, expires_at *time.Time) (*BookHold, error)
+	ExpireHolds(ctx context.Context, now time.Time) ([]*BookHold, error)
+}
+
+type RevokedTokenRepository interface {
+	InsertRevokedToken(ctx context.Context, token RevokedToken) error
+	IsTokenRevoked(ctx context.Context, token_id string) (bool, error)
+	PurgeExpiredTokens(ctx context.Context, now time.Time) (int64, error)
+}
+
+type JobRunRepository interface {
+	// Start the run of the slot, ErrJobRunClaimed when it already has one.
+	InsertJobRun(ctx context.Context, job_name, instance string, scheduled_at time.Time) (*JobRun, error)
+	FinishJobRun(ctx context.Context, id int, status, error_message string) error
+	GetJobRuns(ctx context.Context, job_name string, limit int) ([]*JobRun, error)
+}
+
+type NotificationPreferenceRepository interface {
+	GetUserPreferences(ctx context.Context, user_id int) ([]*NotificationPreference, error)
+	UpsertPreference(ctx context.Context, preference NotificationPreference) (*NotificationPreference, error)
+}
+
+type NotificationDeliveryRepository interface {
+	InsertDelivery(ctx context.Context, delivery NotificationDelivery) (*NotificationDelivery, error)
+	RecordAttempt(ctx context.Context, id int, status, last_error string, next_attempt_at *time.Time) error
+	// Claim the due pending deliveries, pushing their next attempt out by lease.
+	ClaimDueDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*NotificationDelivery, error)
+	GetUserDeliveries(ctx context.Context, user_id int, limit int) ([]*NotificationDelivery, error)
+}
+
+type OutboxEventRepository interface {
+	// Claim the due unpublished events, oldest first, pushing their next attempt out by lease.
+	ClaimEvents(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*OutboxEvent, error)
+	MarkPublished(ctx context.Context, id int64) error
+	RecordFailure(ctx context.Context, id int64, last_error string, next_attempt_at time.Time) error
+}
+
+type BookRevisionRepository interface {
+	GetRevisions(ctx context.Context, book_id int) ([]*BookRevision, error)
+	GetRevisionAsOf(ctx context.Context, book_id int, at time.Time) (*BookRevision, error)
+	GetRevision(ctx context.Context, book_id, revision_number int) (*BookRevision, error)
+}
+
+type WebhookSubscriptionRepository interface {
+	InsertSubscription(ctx context.Context, subscription WebhookSubscription) (*WebhookSubscription, error)
+	// Update everything but the secret.
+	UpdateSubscription(ctx context.Context, id int, subscription WebhookSubscription) (*WebhookSubscription, error)
+	UpdateSecret(ctx context.Context, id int, secret string) (*WebhookSubscription, error)
+	DeleteSubscription(ctx context.Context, id int) error
+	GetSubscriptionWithId(ctx context.Context, id int) (*WebhookSubscription, error)
+	GetSubscriptions(ctx context.Context, active_only bool) ([]*WebhookSubscription, error)
+}
+
+type WebhookDeliveryRepository interface {
+	// Queue the event for the subscription, unless it already is.
+	InsertDelivery(ctx context.Context, subscription_id int, event_id int64, event_type string, payload []byte) error
+	ReplayDelivery(ctx context.Context, id int64) (*WebhookDelivery, error)
+	GetDeliveryWithId(ctx context.Context, id int64) (*WebhookDelivery, error)
+	GetSubscriptionDeliveries(ctx context.Context, subscription_id int, status string, limit int) ([]*WebhookDelivery, error)
+	// Claim the due pending deliveries of the active subscriptions, pushing their next attempt out by lease.
+	ClaimDueDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*WebhookDelivery, error)
+	RecordAttempt(ctx context.Context, id int64, status string, response_status *int, last_error string, next_attempt_at *time.Time) error
+}
+
+type AuditEntryRepository interface {
+	InsertEntry(ctx context.Context, entry AuditEntry) error
+	QueryEntries(ctx context.Context, filter AuditFilter) ([]*AuditEntry, error)
+}
+
+type StatsRepository interface {
+	GetLibraryStats(ctx context.Context, now time.Time) (*LibraryStats, error)
+}
+
+var (
+	_ AuthorRepository                 = (*AuthorStore)(nil)
+	_ BookRepository                   = (*BookStore)(nil)
+	_ UserRepository                   = (*UserStore)(nil)
+	_ LoanRepository                   = (*LoanStore)(nil)
+	_ FineEntryRepository              = (*FineEntryStore)(nil)
+	_ BorrowPolicyRepository           = (*BorrowPolicyStore)(nil)
+	_ LibraryHoursRepository           = (*LibraryHoursStore)(nil)
+	_ LibraryClosureRepository         = (*LibraryClosureStore)(nil)
+	_ BookHoldRepository               = (*BookHoldStore)(nil)
+	_ RevokedTokenRepository           = (*RevokedTokenStore)(nil)
+	_ JobRunRepository                 = (*JobRunStore)(nil)
+	_ NotificationPreferenceRepository = (*NotificationPreferenceStore)(nil)
+	_ NotificationDeliveryRepository   = (*NotificationDeliveryStore)(nil)
+	_ OutboxEventRepository            = (*OutboxEventStore)(nil)
+	_ BookRevisionRepository           = (*BookRevisionStore)(nil)
+	_ WebhookSubscriptionRepository    = (*WebhookSubscriptionStore)(nil)
+	_ WebhookDeliveryRepository        = (*WebhookDeliveryStore)(nil)
+	_ AuditEntryRepository             = (*AuditEntryStore)(nil)
+	_ StatsRepository                  = (*StatsStore)(nil)
+)
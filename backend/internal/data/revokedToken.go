@@ -0,0 +1,60 @@
+package data
+
+import (
+	"context"
+	"time"
+)
+
+type RevokedToken struct {
+	TokenId   string    `json:"token_id"`
+	UserId    int       `json:"user_id"`
+	ExpiresAt time.Time `json:"expires_at"`
+	RevokedAt time.Time `json:"revoked_at"`
+}
+
+// Revoked tokens stored in Postgres.
+type RevokedTokenStore struct {
+	pgStore
+}
+
+// Revoke a token until it expires
+func (r *RevokedTokenStore) InsertRevokedToken(ctx context.Context, token RevokedToken) error {
+	ctx, cancel := context.WithTimeout(ctx, r.timeouts.Write)
+	defer cancel()
+
+	stmt := `insert into revoked_token (token_id, user_id, expires_at, revoked_at) values ($1, $2, $3, $4) on conflict (token_id) do nothing;`
+
+	_, err := r.conn(ctx).ExecContext(ctx, stmt, token.TokenId, token.UserId, token.ExpiresAt, time.Now())
+
+	return err
+}
+
+// Check if a token has been revoked
+func (r *RevokedTokenStore) IsTokenRevoked(ctx context.Context, token_id string) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeouts.Read)
+	defer cancel()
+
+	var revoked bool
+
+	query := `select exists (select 1 from revoked_token where token_id = $1);`
+
+	err := r.conn(ctx).QueryRowContext(ctx, query, token_id).Scan(&revoked)
+
+	if err != nil {
+		return false, err
+	}
+	return revoked, nil
+}
+
+// Delete the revoked tokens that have expired anyway
+func (r *RevokedTokenStore) PurgeExpiredTokens(ctx context.Context, now time.Time) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeouts.Batch)
+	defer cancel()
+
+	result, err := r.conn(ctx).ExecContext(ctx, `delete from revoked_token where expires_at < $1;`, now)
+
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
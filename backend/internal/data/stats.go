@@ -0,0 +1,44 @@
+package data
+
+import (
+	"context"
+	"time"
+)
+
+// Figures on the state of the library, exported as metrics.
+type LibraryStats struct {
+	ActiveLoans      int     `json:"active_loans"`
+	OverdueLoans     int     `json:"overdue_loans"`
+	OutstandingFines float64 `json:"outstanding_fines"`
+	OutOfStockBooks  int     `json:"out_of_stock_books"`
+}
+
+// Library wide aggregates computed in Postgres.
+type StatsStore struct {
+	pgStore
+}
+
+// Count the loans out, those past their due date at now, the fines users owe and the books
+// that are not archived but have no copy left to lend.
+func (s *StatsStore) GetLibraryStats(ctx context.Context, now time.Time) (*LibraryStats, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeouts.Read)
+	defer cancel()
+
+	var stats LibraryStats
+
+	query := `select
+				(select count(*) from book_borrow where returned = false),
+				(select count(*) from book_borrow where returned = false and due_date < $1),
+				(select coalesce(sum(balance), 0) from (
+					select sum(case when entry_type in ('charge', 'refund') then amount else -amount end) as balance
+					from fine_ledger group by user_id
+				) as balances where balance > 0),
+				(select count(*) from book where archive = false and coalesce(book_count, 0) = 0);`
+
+	err := s.conn(ctx).QueryRowContext(ctx, query, now).Scan(&stats.ActiveLoans, &stats.OverdueLoans, &stats.OutstandingFines, &stats.OutOfStockBooks)
+
+	if err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
@@ -0,0 +1,28 @@
+package data
+
+import "time"
+
+// How long each kind of database operation may run. The deadline of the request an operation
+// serves still applies, whichever comes first cancels the query.
+type Timeouts struct {
+	// Lookups of a single record.
+	Read time.Duration
+	// Queries returning a list of records.
+	List time.Duration
+	// Single statements changing records.
+	Write time.Duration
+	// Transactions spanning several statements.
+	Transaction time.Duration
+	// Maintenance statements of the background jobs touching many records at once.
+	Batch time.Duration
+}
+
+func DefaultTimeouts() Timeouts {
+	return Timeouts{
+		Read:        3 * time.Second,
+		List:        9 * time.Second,
+		Write:       3 * time.Second,
+		Transaction: 9 * time.Second,
+		Batch:       30 * time.Second,
+	}
+}
@@ -0,0 +1,241 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgconn"
+)
+
+const (
+	pgSerializationFailure = "40001"
+	txRetryBaseDelay       = 10 * time.Millisecond
+	txRetryMaxDelay        = 500 * time.Millisecond
+)
+
+// Runs several repository calls atomically. The calls made with the context handed to fn
+// share one transaction, committed when fn returns nil and rolled back otherwise. A unit
+// started inside another one joins it. fn may run more than once, so it must not have
+// effects outside of the database, such as sending notifications, those are registered
+// with AfterCommit.
+type UnitOfWork interface {
+	// Run fn in a transaction at the isolation level, sql.LevelDefault for the configured one.
+	Transact(ctx context.Context, isolation sql.IsolationLevel, fn func(ctx context.Context) error) error
+}
+
+// How units of work run their transactions.
+type TxConfig struct {
+	// Isolation of the units that do not ask for one.
+	Isolation sql.IsolationLevel
+	// Times a unit is run again after a serialization failure.
+	MaxRetries int
+}
+
+func DefaultTxConfig() TxConfig {
+	return TxConfig{
+		Isolation:  sql.LevelReadCommitted,
+		MaxRetries: 3,
+	}
+}
+
+// Isolation level named read_committed, repeatable_read or serializable.
+func ParseIsolation(value string) (sql.IsolationLevel, error) {
+	name := strings.ReplaceAll(strings.ToLower(strings.TrimSpace(value)), "_", " ")
+
+	for _, isolation := range []sql.IsolationLevel{sql.LevelReadCommitted, sql.LevelRepeatableRead, sql.LevelSerializable} {
+		if strings.ToLower(isolation.String()) == name {
+			return isolation, nil
+		}
+	}
+	return sql.LevelDefault, fmt.Errorf("unknown isolation level %q", value)
+}
+
+// Statements of the stores, satisfied by both *sql.DB and *sql.Tx.
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+type txKey struct{}
+
+type commitHooksKey struct{}
+
+// Functions to run once the outermost unit of work commits.
+type commitHooks struct {
+	hooks []func()
+}
+
+// Run fn once the unit of work ctx belongs to has committed, right away when ctx is not in
+// one. The functions of a unit that rolls back are dropped, as are those of an attempt that
+// is retried. For the effects outside of the database, such as dropping cache entries, that
+// must not run before the change is visible to other transactions.
+func AfterCommit(ctx context.Context, fn func()) {
+	if hooks, ok := ctx.Value(commitHooksKey{}).(*commitHooks); ok {
+		hooks.hooks = append(hooks.hooks, fn)
+		return
+	}
+	fn()
+}
+
+// Context collecting the AfterCommit functions of an outermost unit of work, and the function
+// running them once it committed. A unit started inside another one gets ctx back, its
+// functions run when the outer one commits.
+func WithCommitHooks(ctx context.Context) (context.Context, func()) {
+	if _, ok := ctx.Value(commitHooksKey{}).(*commitHooks); ok {
+		return ctx, func() {}
+	}
+
+	hooks := &commitHooks{}
+
+	return context.WithValue(ctx, commitHooksKey{}, hooks), func() {
+		for _, hook := range hooks.hooks {
+			hook()
+		}
+	}
+}
+
+type primaryKey struct{}
+
+// Send the reads of ctx meant for the replica to the primary, for reads that must not be behind.
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryKey{}, true)
+}
+
+// Transaction of the unit of work ctx belongs to, if any.
+func txFromContext(ctx context.Context) (*sql.Tx, bool) {
+	tx, ok := ctx.Value(txKey{}).(*sql.Tx)
+	return tx, ok
+}
+
+// The unit of work's transaction when there is one, the pool otherwise.
+func (s pgStore) conn(ctx context.Context) querier {
+	if tx, ok := txFromContext(ctx); ok {
+		return tx
+	}
+	return s.db
+}
+
+// The replica for the read only queries that can be a little behind, such as browsing the
+// catalogue, unless ctx asks for the primary. Inside a unit of work it is the unit's
+// transaction, as the unit may read its own writes.
+func (s pgStore) replicaConn(ctx context.Context) querier {
+	if tx, ok := txFromContext(ctx); ok {
+		return tx
+	}
+
+	if primary, _ := ctx.Value(primaryKey{}).(bool); s.replica != nil && !primary {
+		return s.replica
+	}
+	return s.db
+}
+
+// Transaction of a store method spanning several statements. Inside a unit of work it is
+// the unit's transaction, which only the unit commits or rolls back.
+type storeTx struct {
+	querier
+	tx *sql.Tx
+}
+
+func (t storeTx) Commit() error {
+	if t.tx == nil {
+		return nil
+	}
+	return t.tx.Commit()
+}
+
+func (t storeTx) Rollback() error {
+	if t.tx == nil {
+		return nil
+	}
+	return t.tx.Rollback()
+}
+
+func (s pgStore) begin(ctx context.Context) (storeTx, error) {
+	if tx, ok := txFromContext(ctx); ok {
+		return storeTx{querier: tx}, nil
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+
+	if err != nil {
+		return storeTx{}, err
+	}
+	return storeTx{querier: tx, tx: tx}, nil
+}
+
+// Units of work run in Postgres transactions.
+type TxStore struct {
+	pgStore
+	config TxConfig
+}
+
+func (t *TxStore) Transact(ctx context.Context, isolation sql.IsolationLevel, fn func(ctx context.Context) error) error {
+	if _, ok := txFromContext(ctx); ok {
+		return fn(ctx)
+	}
+
+	if isolation == sql.LevelDefault {
+		isolation = t.config.Isolation
+	}
+
+	for attempt := 0; ; attempt++ {
+		err := t.transactOnce(ctx, isolation, fn)
+
+		if err == nil || !isSerializationFailure(err) || attempt >= t.config.MaxRetries {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(txRetryDelay(attempt)):
+		}
+	}
+}
+
+func (t *TxStore) transactOnce(ctx context.Context, isolation sql.IsolationLevel, fn func(ctx context.Context) error) error {
+	ctx, cancel := context.WithTimeout(ctx, t.timeouts.Transaction)
+	defer cancel()
+
+	tx, err := t.db.BeginTx(ctx, &sql.TxOptions{Isolation: isolation})
+
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	ctx, run_hooks := WithCommitHooks(ctx)
+
+	err = fn(context.WithValue(ctx, txKey{}, tx))
+
+	if err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+	run_hooks()
+	return nil
+}
+
+// Backoff with jitter, so the transactions that conflicted do not run into each other again.
+func txRetryDelay(attempt int) time.Duration {
+	delay := txRetryBaseDelay << attempt
+
+	if delay > txRetryMaxDelay || delay <= 0 {
+		delay = txRetryMaxDelay
+	}
+	return delay/2 + rand.N(delay/2)
+}
+
+func isSerializationFailure(err error) bool {
+	var pg_error *pgconn.PgError
+	return errors.As(err, &pg_error) && pg_error.Code == pgSerializationFailure
+}
@@ -0,0 +1,312 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgconn"
+)
+
+// Counts the transactions a fakeConn begins and ends.
+type txLog struct {
+	begins     int
+	commits    int
+	rollbacks  int
+	isolations []driver.IsolationLevel
+	// Errors the statements fail with, one per statement until they run out.
+	execErrors []error
+}
+
+// A database/sql driver connection running no SQL, failing the statements with the errors
+// of its log.
+type fakeConn struct {
+	log *txLog
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("prepared statements are not supported")
+}
+
+func (c *fakeConn) Close() error {
+	return nil
+}
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *fakeConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	c.log.begins++
+	c.log.isolations = append(c.log.isolations, opts.Isolation)
+	return &fakeTx{log: c.log}, nil
+}
+
+func (c *fakeConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if len(c.log.execErrors) > 0 {
+		err := c.log.execErrors[0]
+		c.log.execErrors = c.log.execErrors[1:]
+
+		if err != nil {
+			return nil, err
+		}
+	}
+	return driver.RowsAffected(1), nil
+}
+
+type fakeTx struct {
+	log *txLog
+}
+
+func (t *fakeTx) Commit() error {
+	t.log.commits++
+	return nil
+}
+
+func (t *fakeTx) Rollback() error {
+	t.log.rollbacks++
+	return nil
+}
+
+type fakeConnector struct {
+	log *txLog
+}
+
+func (c fakeConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	return &fakeConn{log: c.log}, nil
+}
+
+func (c fakeConnector) Driver() driver.Driver {
+	return fakeDriver{}
+}
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(name string) (driver.Conn, error) {
+	return nil, errors.New("open the fake database with sql.OpenDB")
+}
+
+func newTestTxStore(t *testing.T, log *txLog, config TxConfig) *TxStore {
+	t.Helper()
+
+	db := sql.OpenDB(fakeConnector{log: log})
+	t.Cleanup(func() { db.Close() })
+
+	return &TxStore{pgStore{db: db, timeouts: Timeouts{Transaction: time.Second}}, config}
+}
+
+var errSerialization = &pgconn.PgError{Code: pgSerializationFailure, Message: "could not serialize access"}
+
+func TestTransact(t *testing.T) {
+	errOther := errors.New("connection reset")
+
+	tests := []struct {
+		name       string
+		maxRetries int
+		execErrors []error
+		wantErr    error
+		wantRuns   int
+		wantLog    txLog
+	}{
+		{"commits a unit", 3, nil, nil, 1, txLog{begins: 1, commits: 1}},
+		{"retries a serialization failure", 3, []error{errSerialization}, nil, 2, txLog{begins: 2, commits: 1, rollbacks: 1}},
+		{"retries up to the max retries", 2, []error{errSerialization, errSerialization, errSerialization}, errSerialization, 3, txLog{begins: 3, rollbacks: 3}},
+		{"does not retry without retries", 0, []error{errSerialization}, errSerialization, 1, txLog{begins: 1, rollbacks: 1}},
+		{"does not retry other errors", 3, []error{errOther}, errOther, 1, txLog{begins: 1, rollbacks: 1}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			log := &txLog{execErrors: test.execErrors}
+			store := newTestTxStore(t, log, TxConfig{Isolation: sql.LevelSerializable, MaxRetries: test.maxRetries})
+			runs := 0
+
+			err := store.Transact(context.Background(), sql.LevelDefault, func(ctx context.Context) error {
+				runs++
+				_, err := store.conn(ctx).ExecContext(ctx, "UPDATE book SET book_count = book_count - 1")
+				return err
+			})
+
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("Transact() error = %v, want %v", err, test.wantErr)
+			}
+
+			if runs != test.wantRuns {
+				t.Errorf("Transact() ran the unit %d times, want %d", runs, test.wantRuns)
+			}
+
+			if log.begins != test.wantLog.begins || log.commits != test.wantLog.commits || log.rollbacks != test.wantLog.rollbacks {
+				t.Errorf("Transact() began %d, committed %d and rolled back %d, want %d, %d and %d",
+					log.begins, log.commits, log.rollbacks, test.wantLog.begins, test.wantLog.commits, test.wantLog.rollbacks)
+			}
+
+			for _, isolation := range log.isolations {
+				if sql.IsolationLevel(isolation) != sql.LevelSerializable {
+					t.Errorf("Transact() began a transaction at %v, want the configured %v", sql.IsolationLevel(isolation), sql.LevelSerializable)
+				}
+			}
+		})
+	}
+}
+
+func TestTransactNested(t *testing.T) {
+	errUnavailable := errors.New("book unavailable")
+
+	tests := []struct {
+		name string
+		// Error the nested unit fails with the first time it runs.
+		innerErr  error
+		wantErr   error
+		wantInner int
+		wantLog   txLog
+	}{
+		{"joins the outer transaction", nil, nil, 1, txLog{begins: 1, commits: 1}},
+		{"rolls back the outer transaction", errUnavailable, errUnavailable, 1, txLog{begins: 1, rollbacks: 1}},
+		// The outer unit is retried as a whole, the nested one does not retry on its own.
+		{"retries the outer unit", errSerialization, nil, 2, txLog{begins: 2, commits: 1, rollbacks: 1}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			log := &txLog{}
+			store := newTestTxStore(t, log, DefaultTxConfig())
+			inner_runs := 0
+
+			err := store.Transact(context.Background(), sql.LevelDefault, func(ctx context.Context) error {
+				outer_tx, _ := txFromContext(ctx)
+
+				return store.Transact(ctx, sql.LevelSerializable, func(ctx context.Context) error {
+					inner_runs++
+
+					if inner_tx, _ := txFromContext(ctx); inner_tx != outer_tx {
+						t.Error("the nested unit did not get the transaction of the outer one")
+					}
+
+					// The store methods join the unit's transaction instead of committing their own.
+					store_tx, err := store.begin(ctx)
+
+					if err != nil {
+						return err
+					}
+					defer store_tx.Rollback()
+
+					if store_tx.querier != outer_tx {
+						t.Error("the store transaction is not the unit's")
+					}
+
+					if err = store_tx.Commit(); err != nil {
+						return err
+					}
+
+					if inner_runs == 1 {
+						return test.innerErr
+					}
+					return nil
+				})
+			})
+
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("Transact() error = %v, want %v", err, test.wantErr)
+			}
+
+			if inner_runs != test.wantInner {
+				t.Errorf("ran the nested unit %d times, want %d", inner_runs, test.wantInner)
+			}
+
+			if log.begins != test.wantLog.begins || log.commits != test.wantLog.commits || log.rollbacks != test.wantLog.rollbacks {
+				t.Errorf("Transact() began %d, committed %d and rolled back %d, want %d, %d and %d",
+					log.begins, log.commits, log.rollbacks, test.wantLog.begins, test.wantLog.commits, test.wantLog.rollbacks)
+			}
+
+			// Only the outer unit chooses the isolation.
+			for _, isolation := range log.isolations {
+				if sql.IsolationLevel(isolation) != sql.LevelReadCommitted {
+					t.Errorf("began a transaction at %v, want the outer unit's %v", sql.IsolationLevel(isolation), sql.LevelReadCommitted)
+				}
+			}
+		})
+	}
+}
+
+func TestStoreTxOutsideUnit(t *testing.T) {
+	log := &txLog{}
+	store := newTestTxStore(t, log, DefaultTxConfig())
+
+	store_tx, err := store.begin(context.Background())
+
+	if err != nil {
+		t.Fatalf("begin() error = %v", err)
+	}
+
+	if err = store_tx.Commit(); err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+
+	if log.begins != 1 || log.commits != 1 {
+		t.Errorf("began %d and committed %d transactions, want the store to commit its own", log.begins, log.commits)
+	}
+}
+
+func TestAfterCommit(t *testing.T) {
+	errUnavailable := errors.New("book unavailable")
+
+	tests := []struct {
+		name string
+		// Whether the hook is registered in a unit nested in the outer one.
+		nested     bool
+		execErrors []error
+		wantErr    error
+		// Commits the hook sees when it runs, one entry per run.
+		wantRuns []int
+	}{
+		{"runs after the commit", false, nil, nil, []int{1}},
+		{"runs after the outer unit commits", true, nil, nil, []int{1}},
+		{"runs once for a unit retried", false, []error{errSerialization}, nil, []int{1}},
+		{"does not run for a unit rolled back", false, []error{errUnavailable}, errUnavailable, nil},
+		{"does not run for a nested unit rolled back", true, []error{errUnavailable}, errUnavailable, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			log := &txLog{execErrors: test.execErrors}
+			store := newTestTxStore(t, log, DefaultTxConfig())
+			runs := make([]int, 0)
+
+			unit := func(ctx context.Context) error {
+				AfterCommit(ctx, func() { runs = append(runs, log.commits) })
+
+				_, err := store.conn(ctx).ExecContext(ctx, "UPDATE book SET book_count = book_count - 1")
+				return err
+			}
+
+			err := store.Transact(context.Background(), sql.LevelDefault, func(ctx context.Context) error {
+				if test.nested {
+					return store.Transact(ctx, sql.LevelDefault, unit)
+				}
+				return unit(ctx)
+			})
+
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("Transact() error = %v, want %v", err, test.wantErr)
+			}
+
+			if !slices.Equal(runs, test.wantRuns) {
+				t.Errorf("the hook ran after %v commits, want %v", runs, test.wantRuns)
+			}
+		})
+	}
+}
+
+func TestAfterCommitOutsideUnit(t *testing.T) {
+	ran := false
+
+	AfterCommit(context.Background(), func() { ran = true })
+
+	if !ran {
+		t.Error("AfterCommit() outside of a unit of work did not run the function")
+	}
+}
@@ -0,0 +1,374 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryDead      = "dead"
+)
+
+type WebhookSubscription struct {
+	ID         int       `json:"id"`
+	Name       string    `json:"name"`
+	URL        string    `json:"url"`
+	Secret     string    `json:"secret,omitempty"`
+	EventTypes []string  `json:"event_types"`
+	Active     bool      `json:"active"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+type WebhookDelivery struct {
+	ID             int64           `json:"id"`
+	SubscriptionId int             `json:"subscription_id"`
+	EventId        int64           `json:"event_id"`
+	EventType      string          `json:"event_type"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	LastError      string          `json:"last_error"`
+	ResponseStatus *int            `json:"response_status"`
+	NextAttemptAt  *time.Time      `json:"next_attempt_at"`
+	DeliveredAt    *time.Time      `json:"delivered_at"`
+	ReplayOf       *int64          `json:"replay_of"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+}
+
+// Check if the subscription wants an event type. No event types means every event,
+// and a "loan.*" pattern matches every loan event.
+func (s *WebhookSubscription) Matches(event_type string) bool {
+	if len(s.EventTypes) == 0 {
+		return true
+	}
+
+	for _, pattern := range s.EventTypes {
+		if pattern == event_type || pattern == "*" {
+			return true
+		}
+
+		if prefix, ok := strings.CutSuffix(pattern, ".*"); ok && strings.HasPrefix(event_type, prefix+".") {
+			return true
+		}
+	}
+	return false
+}
+
+const webhookSubscriptionColumns = `id, name, url, secret, event_types, active, created_at, updated_at`
+
+func scanWebhookSubscription(row interface{ Scan(...any) error }) (*WebhookSubscription, error) {
+	var subscription WebhookSubscription
+	var event_types []byte
+
+	err := row.Scan(
+		&subscription.ID,
+		&subscription.Name,
+		&subscription.URL,
+		&subscription.Secret,
+		&event_types,
+		&subscription.Active,
+		&subscription.CreatedAt,
+		&subscription.UpdatedAt,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(event_types, &subscription.EventTypes)
+
+	if err != nil {
+		return nil, err
+	}
+	return &subscription, nil
+}
+
+func encodeEventTypes(event_types []string) (string, error) {
+	if event_types == nil {
+		event_types = []string{}
+	}
+
+	encoded, err := json.Marshal(event_types)
+
+	if err != nil {
+		return "", err
+	}
+	return string(encoded), nil
+}
+
+// Webhook subscriptions stored in Postgres.
+type WebhookSubscriptionStore struct {
+	pgStore
+}
+
+// Create a webhook subscription
+func (w *WebhookSubscriptionStore) InsertSubscription(ctx context.Context, subscription WebhookSubscription) (*WebhookSubscription, error) {
+	ctx, cancel := context.WithTimeout(ctx, w.timeouts.Write)
+	defer cancel()
+
+	event_types, err := encodeEventTypes(subscription.EventTypes)
+
+	if err != nil {
+		return nil, err
+	}
+
+	stmt := `insert into webhook_subscription (name, url, secret, event_types, active, created_at, updated_at) values ($1, $2, $3, $4, $5, $6, $6) returning ` + webhookSubscriptionColumns + `;`
+
+	row := w.conn(ctx).QueryRowContext(ctx, stmt, subscription.Name, subscription.URL, subscription.Secret, event_types, subscription.Active, time.Now())
+
+	return scanWebhookSubscription(row)
+}
+
+// Update the name, url, event types and state of a webhook subscription
+func (w *WebhookSubscriptionStore) UpdateSubscription(ctx context.Context, id int, subscription WebhookSubscription) (*WebhookSubscription, error) {
+	ctx, cancel := context.WithTimeout(ctx, w.timeouts.Write)
+	defer cancel()
+
+	event_types, err := encodeEventTypes(subscription.EventTypes)
+
+	if err != nil {
+		return nil, err
+	}
+
+	stmt := `update webhook_subscription set name = $1, url = $2, event_types = $3, active = $4, updated_at = $5 where id = $6 returning ` + webhookSubscriptionColumns + `;`
+
+	row := w.conn(ctx).QueryRowContext(ctx, stmt, subscription.Name, subscription.URL, event_types, subscription.Active, time.Now(), id)
+
+	updated_subscription, err := scanWebhookSubscription(row)
+
+	if err == sql.ErrNoRows {
+		return nil, NotFound("webhook_subscription_not_found", "Webhook subscription with id %d does not exist.", id)
+	}
+	return updated_subscription, err
+}
+
+// Replace the signing secret of a webhook subscription
+func (w *WebhookSubscriptionStore) UpdateSecret(ctx context.Context, id int, secret string) (*WebhookSubscription, error) {
+	ctx, cancel := context.WithTimeout(ctx, w.timeouts.Write)
+	defer cancel()
+
+	stmt := `update webhook_subscription set secret = $1, updated_at = $2 where id = $3 returning ` + webhookSubscriptionColumns + `;`
+
+	row := w.conn(ctx).QueryRowContext(ctx, stmt, secret, time.Now(), id)
+
+	updated_subscription, err := scanWebhookSubscription(row)
+
+	if err == sql.ErrNoRows {
+		return nil, NotFound("webhook_subscription_not_found", "Webhook subscription with id %d does not exist.", id)
+	}
+	return updated_subscription, err
+}
+
+// Delete a webhook subscription along with its deliveries
+func (w *WebhookSubscriptionStore) DeleteSubscription(ctx context.Context, id int) error {
+	ctx, cancel := context.WithTimeout(ctx, w.timeouts.Write)
+	defer cancel()
+
+	result, err := w.conn(ctx).ExecContext(ctx, `delete from webhook_subscription where id = $1;`, id)
+
+	if err != nil {
+		return err
+	}
+
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return NotFound("webhook_subscription_not_found", "Webhook subscription with id %d does not exist.", id)
+	}
+	return nil
+}
+
+// Get a webhook subscription with id
+func (w *WebhookSubscriptionStore) GetSubscriptionWithId(ctx context.Context, id int) (*WebhookSubscription, error) {
+	ctx, cancel := context.WithTimeout(ctx, w.timeouts.Read)
+	defer cancel()
+
+	row := w.conn(ctx).QueryRowContext(ctx, `select `+webhookSubscriptionColumns+` from webhook_subscription where id = $1;`, id)
+
+	subscription, err := scanWebhookSubscription(row)
+
+	if err == sql.ErrNoRows {
+		return nil, NotFound("webhook_subscription_not_found", "Webhook subscription with id %d does not exist.", id)
+	}
+	return subscription, err
+}
+
+// Get the webhook subscriptions, only the active ones when active_only is set
+func (w *WebhookSubscriptionStore) GetSubscriptions(ctx context.Context, active_only bool) ([]*WebhookSubscription, error) {
+	ctx, cancel := context.WithTimeout(ctx, w.timeouts.List)
+	defer cancel()
+
+	query := `select ` + webhookSubscriptionColumns + ` from webhook_subscription where ($1::boolean = false or active = true) order by id;`
+
+	rows, err := w.conn(ctx).QueryContext(ctx, query, active_only)
+
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	subscriptions := make([]*WebhookSubscription, 0)
+
+	for rows.Next() {
+		subscription, err := scanWebhookSubscription(rows)
+
+		if err != nil {
+			return nil, err
+		}
+		subscriptions = append(subscriptions, subscription)
+	}
+	return subscriptions, rows.Err()
+}
+
+const webhookDeliveryColumns = `id, subscription_id, event_id, event_type, payload, status, attempts, last_error, response_status, next_attempt_at, delivered_at, replay_of, created_at, updated_at`
+
+func scanWebhookDelivery(row interface{ Scan(...any) error }) (*WebhookDelivery, error) {
+	var delivery WebhookDelivery
+	var payload []byte
+
+	err := row.Scan(
+		&delivery.ID,
+		&delivery.SubscriptionId,
+		&delivery.EventId,
+		&delivery.EventType,
+		&payload,
+		&delivery.Status,
+		&delivery.Attempts,
+		&delivery.LastError,
+		&delivery.ResponseStatus,
+		&delivery.NextAttemptAt,
+		&delivery.DeliveredAt,
+		&delivery.ReplayOf,
+		&delivery.CreatedAt,
+		&delivery.UpdatedAt,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+	delivery.Payload = payload
+	return &delivery, nil
+}
+
+func scanWebhookDeliveries(rows *sql.Rows) ([]*WebhookDelivery, error) {
+	defer rows.Close()
+
+	deliveries := make([]*WebhookDelivery, 0)
+
+	for rows.Next() {
+		delivery, err := scanWebhookDelivery(rows)
+
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	return deliveries, rows.Err()
+}
+
+// Webhook deliveries stored in Postgres.
+type WebhookDeliveryStore struct {
+	pgStore
+}
+
+// Queue an event for a subscription, an event already queued for it is left alone
+func (w *WebhookDeliveryStore) InsertDelivery(ctx context.Context, subscription_id int, event_id int64, event_type string, payload []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, w.timeouts.Write)
+	defer cancel()
+
+	stmt := `insert into webhook_delivery (subscription_id, event_id, event_type, payload, status, next_attempt_at, created_at, updated_at)
+				values ($1, $2, $3, $4, 'pending', $5, $5, $5) on conflict (subscription_id, event_id) where replay_of is null do nothing;`
+
+	_, err := w.conn(ctx).ExecContext(ctx, stmt, subscription_id, event_id, event_type, string(payload), time.Now())
+
+	return err
+}
+
+// Queue a past delivery again as a new delivery
+func (w *WebhookDeliveryStore) ReplayDelivery(ctx context.Context, id int64) (*WebhookDelivery, error) {
+	ctx, cancel := context.WithTimeout(ctx, w.timeouts.Write)
+	defer cancel()
+
+	stmt := `insert into webhook_delivery (subscription_id, event_id, event_type, payload, status, next_attempt_at, replay_of, created_at, updated_at)
+				select subscription_id, event_id, event_type, payload, 'pending', $1, id, $1, $1 from webhook_delivery where id = $2
+				returning ` + webhookDeliveryColumns + `;`
+
+	row := w.conn(ctx).QueryRowContext(ctx, stmt, time.Now(), id)
+
+	delivery, err := scanWebhookDelivery(row)
+
+	if err == sql.ErrNoRows {
+		return nil, NotFound("webhook_delivery_not_found", "Webhook delivery with id %d does not exist.", id)
+	}
+	return delivery, err
+}
+
+// Get a webhook delivery with id
+func (w *WebhookDeliveryStore) GetDeliveryWithId(ctx context.Context, id int64) (*WebhookDelivery, error) {
+	ctx, cancel := context.WithTimeout(ctx, w.timeouts.Read)
+	defer cancel()
+
+	row := w.conn(ctx).QueryRowContext(ctx, `select `+webhookDeliveryColumns+` from webhook_delivery where id = $1;`, id)
+
+	delivery, err := scanWebhookDelivery(row)
+
+	if err == sql.ErrNoRows {
+		return nil, NotFound("webhook_delivery_not_found", "Webhook delivery with id %d does not exist.", id)
+	}
+	return delivery, err
+}
+
+// Get the latest deliveries of a subscription, optionally only those in a status
+func (w *WebhookDeliveryStore) GetSubscriptionDeliveries(ctx context.Context, subscription_id int, status string, limit int) ([]*WebhookDelivery, error) {
+	ctx, cancel := context.WithTimeout(ctx, w.timeouts.List)
+	defer cancel()
+
+	query := `select ` + webhookDeliveryColumns + ` from webhook_delivery
+				where subscription_id = $1 and ($2::text = '' or status = $2) order by id desc limit $3;`
+
+	rows, err := w.conn(ctx).QueryContext(ctx, query, subscription_id, status, limit)
+
+	if err != nil {
+		return nil, err
+	}
+	return scanWebhookDeliveries(rows)
+}
+
+// Claim the pending deliveries of active subscriptions due for an attempt, pushing their next
+// attempt out by lease so that no other instance picks them up while they are being sent.
+// Deliveries of an inactive subscription wait until it is activated again.
+func (w *WebhookDeliveryStore) ClaimDueDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*WebhookDelivery, error) {
+	ctx, cancel := context.WithTimeout(ctx, w.timeouts.Batch)
+	defer cancel()
+
+	stmt := `update webhook_delivery set next_attempt_at = $1 where id in (
+				select id from webhook_delivery where status = 'pending' and next_attempt_at <= $2
+				and subscription_id in (select id from webhook_subscription where active = true)
+				order by next_attempt_at limit $3 for update skip locked)
+				returning ` + webhookDeliveryColumns + `;`
+
+	rows, err := w.conn(ctx).QueryContext(ctx, stmt, now.Add(lease), now, limit)
+
+	if err != nil {
+		return nil, err
+	}
+	return scanWebhookDeliveries(rows)
+}
+
+// Record the outcome of a delivery attempt
+func (w *WebhookDeliveryStore) RecordAttempt(ctx context.Context, id int64, status string, response_status *int, last_error string, next_attempt_at *time.Time) error {
+	ctx, cancel := context.WithTimeout(ctx, w.timeouts.Write)
+	defer cancel()
+
+	stmt := `update webhook_delivery set status = $1, attempts = attempts + 1, response_status = $2, last_error = $3, next_attempt_at = $4,
+				delivered_at = case when $1 = 'succeeded' then $5 else delivered_at end, updated_at = $5 where id = $6;`
+
+	_, err := w.conn(ctx).ExecContext(ctx, stmt, status, response_status, last_error, next_attempt_at, time.Now(), id)
+
+	return err
+}
@@ -1,61 +1,115 @@
 package db
 
 import (
+	"context"
 	"database/sql"
+	"database/sql/driver"
 	"fmt"
-	"log"
-	"os"
+	"log/slog"
+	"math/rand/v2"
 	"time"
 
+	"github.com/XSAM/otelsql"
 	_ "github.com/jackc/pgconn"
-	_ "github.com/jackc/pgx/v4"
-	_ "github.com/jackc/pgx/v4/stdlib"
-	"github.com/rs/zerolog"
-	sqldblogger "github.com/simukti/sqldb-logger"
-	"github.com/simukti/sqldb-logger/logadapter/zerologadapter"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/stdlib"
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/logging"
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/tracing"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
 )
 
-var counts int64
+const (
+	connectBaseDelay = 250 * time.Millisecond
+	connectMaxDelay  = 10 * time.Second
+)
+
+// Every statement run for a request or a job is traced as a span of its trace. The statements
+// run outside of any trace, like the polls of the outbox, are not.
+var sqlSpanOptions = otelsql.SpanOptions{
+	OmitConnResetSession: true,
+	OmitConnectorConnect: true,
+	OmitRows:             true,
+	SpanFilter: func(ctx context.Context, method otelsql.Method, query string, args []driver.NamedValue) bool {
+		return tracing.InTrace(ctx)
+	},
+}
+
+// Limits of the connection pool, see the matching setters of sql.DB. Zero leaves a limit unset.
+type PoolConfig struct {
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+}
+
+type Options struct {
+	Pool PoolConfig
+	// How long to keep trying to connect before giving up.
+	StartupTimeout time.Duration
+	QueryLog       QueryLogConfig
+}
+
+func openDB(ctx context.Context, dsn string, options Options) (*sql.DB, error) {
+	config, err := pgx.ParseConfig(dsn)
 
-func openDB(dsn string) (*sql.DB, error) {
-	db, err := sql.Open("pgx", dsn)
-	loggerAdapter := zerologadapter.New(zerolog.New(os.Stdout))
-	db = sqldblogger.OpenDriver(dsn, db.Driver(), loggerAdapter, sqldblogger.WithSQLQueryAsMessage(true))
 	if err != nil {
 		return nil, err
 	}
 
-	err = db.Ping()
+	if options.QueryLog.Mode != QueryLogOff {
+		config.Logger = queryLogger{options.QueryLog}
+		config.LogLevel = pgx.LogLevelInfo
+	}
+
+	db := otelsql.OpenDB(stdlib.GetConnector(*config), otelsql.WithAttributes(semconv.DBSystemPostgreSQL), otelsql.WithSpanOptions(sqlSpanOptions))
+
+	db.SetMaxOpenConns(options.Pool.MaxOpenConns)
+	db.SetMaxIdleConns(options.Pool.MaxIdleConns)
+	db.SetConnMaxLifetime(options.Pool.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(options.Pool.ConnMaxIdleTime)
+
+	err = db.PingContext(ctx)
 
 	if err != nil {
+		db.Close()
 		return nil, err
 	}
 
 	return db, nil
 }
 
-func InitDB() (*sql.DB, error) {
-	dsn := os.Getenv("DSN")
-
-	for {
-		connection, err := openDB(dsn)
-		fmt.Printf("%s is the dsn string \n", dsn)
-		if err != nil {
-			log.Println("Postgress not yet ready...")
-			counts++
-		} else {
-			log.Println("Connected to postgress...")
+// Connect to Postgres, retrying with backoff until it is up or the startup timeout runs out.
+func InitDB(dsn string, options Options) (*sql.DB, error) {
+	database := logging.RedactDSN(dsn)
+
+	ctx, cancel := context.WithTimeout(context.Background(), options.StartupTimeout)
+	defer cancel()
+
+	for attempt := 0; ; attempt++ {
+		connection, err := openDB(ctx, dsn, options)
+
+		if err == nil {
+			slog.Info("Connected to Postgres", "database", database)
 			return connection, nil
 		}
 
-		if counts > 10 {
-			log.Println(err)
-			return nil, err
-		}
+		delay := connectDelay(attempt)
+		slog.Warn("Postgres not yet ready", "database", database, "attempt", attempt+1, "retry_in", delay, "error", err)
 
-		log.Println("Backing off for 2 seconds....")
-		time.Sleep(2 * time.Second)
-		continue
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("could not connect to Postgres within %s: %w", options.StartupTimeout, err)
+		case <-time.After(delay):
+		}
 	}
+}
 
+// Exponential backoff with full jitter, so the instances started together do not retry in step.
+func connectDelay(attempt int) time.Duration {
+	delay := connectBaseDelay << min(attempt, 16)
+
+	if delay > connectMaxDelay || delay <= 0 {
+		delay = connectMaxDelay
+	}
+	return rand.N(delay) + time.Millisecond
 }
@@ -0,0 +1,57 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/health"
+)
+
+// The pool can reach Postgres. Reports the usage of the pool.
+func CheckConnection(db *sql.DB) health.CheckFunc {
+	return func(ctx context.Context) (any, error) {
+		stats := db.Stats()
+
+		details := map[string]int{
+			"open_connections": stats.OpenConnections,
+			"in_use":           stats.InUse,
+			"idle":             stats.Idle,
+		}
+		return details, db.PingContext(ctx)
+	}
+}
+
+// The schema is at the newest migration and no migration was left half applied.
+func CheckMigrations(db *sql.DB, expected_version uint) health.CheckFunc {
+	return func(ctx context.Context) (any, error) {
+		var version uint
+		var dirty bool
+
+		err := db.QueryRowContext(ctx, `select version, dirty from schema_migrations limit 1;`).Scan(&version, &dirty)
+
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("no migration has been applied")
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		details := map[string]any{
+			"version":          version,
+			"expected_version": expected_version,
+			"dirty":            dirty,
+		}
+
+		if dirty {
+			return details, fmt.Errorf("migration %d failed part way, fix it by hand then force the version with migrate force", version)
+		}
+
+		if version != expected_version {
+			return details, fmt.Errorf("schema is at version %d, expected %d", version, expected_version)
+		}
+		return details, nil
+	}
+}
@@ -2,39 +2,190 @@ package db
 
 import (
 	"database/sql"
+	"embed"
 	"errors"
 	"fmt"
-	"os"
-	"path/filepath"
+	"io/fs"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
 
 	"github.com/golang-migrate/migrate/v4"
 	"github.com/golang-migrate/migrate/v4/database/pgx"
-	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
 )
 
-func RunMigrations(db *sql.DB) error {
+// The migrations are built into the binary, so it runs them from any directory.
+//
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+const migrationDir = "migrations"
+
+// State of the schema against the migrations built in.
+type MigrationStatus struct {
+	// Version of the last migration applied, 0 when none was.
+	Version uint `json:"version"`
+	// A migration failed part way, it has to be fixed by hand and the version forced.
+	Dirty   bool   `json:"dirty"`
+	Latest  uint   `json:"latest"`
+	Pending []uint `json:"pending"`
+}
+
+// Applies and rolls back the migrations built in. Closing it closes the db it was made with.
+type Migrator struct {
+	migrate *migrate.Migrate
+}
+
+func NewMigrator(db *sql.DB) (*Migrator, error) {
+	source, err := iofs.New(migrationFiles, migrationDir)
+
+	if err != nil {
+		return nil, fmt.Errorf("could not read the migrations: %w", err)
+	}
+
 	driver, err := pgx.WithInstance(db, &pgx.Config{})
+
+	if err != nil {
+		return nil, fmt.Errorf("could not create migration driver: %w", err)
+	}
+
+	m, err := migrate.NewWithInstance("iofs", source, "pgx", driver)
+
 	if err != nil {
-		return fmt.Errorf("could not create migration driver: %w", err)
+		return nil, fmt.Errorf("could not create migration instance: %w", err)
+	}
+
+	m.Log = migrateLogger{}
+
+	return &Migrator{migrate: m}, nil
+}
+
+// Apply every pending migration.
+func (m *Migrator) Up() error {
+	return ignoreNoChange(m.migrate.Up())
+}
+
+// Roll back the last steps migrations applied.
+func (m *Migrator) Down(steps int) error {
+	if steps <= 0 {
+		return fmt.Errorf("the number of migrations to roll back must be positive, got %d", steps)
 	}
+	return ignoreNoChange(m.migrate.Steps(-steps))
+}
+
+// Migrate up or down to the version.
+func (m *Migrator) Goto(version uint) error {
+	return ignoreNoChange(m.migrate.Migrate(version))
+}
 
-	migrationPath := filepath.Join("../../internal", "db", "migrations")
-	if _, err := os.Stat(migrationPath); os.IsNotExist(err) {
-		return fmt.Errorf("migrations directory does not exist: %s", migrationPath)
+// Set the version without running any migration and clear the dirty flag, once a failed
+// migration was fixed by hand. -1 records that no migration is applied.
+func (m *Migrator) Force(version int) error {
+	if version < -1 {
+		return fmt.Errorf("the version to force must be -1 or more, got %d", version)
 	}
+	return m.migrate.Force(version)
+}
+
+func (m *Migrator) Status() (*MigrationStatus, error) {
+	versions, err := migrationVersions()
 
-	m, err := migrate.NewWithDatabaseInstance(
-		"file://../../internal/db/migrations",
-		"pgx", driver)
 	if err != nil {
-		return fmt.Errorf("could not create migration instance: %w", err)
+		return nil, err
+	}
+
+	var status MigrationStatus
+
+	version, dirty, err := m.migrate.Version()
+
+	if err != nil && !errors.Is(err, migrate.ErrNilVersion) {
+		return nil, err
 	}
 
-	if err := m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
-		return fmt.Errorf("could not run migrations: %w", err)
+	status.Version = version
+	status.Dirty = dirty
+	status.Pending = make([]uint, 0)
+
+	for _, available := range versions {
+		if available > version {
+			status.Pending = append(status.Pending, available)
+		}
 	}
-	// if err := m.Steps(4); err != nil && !errors.Is(err, migrate.ErrNoChange) {
-	// 	return fmt.Errorf("could not run down migrations: %w", err)
-	// }
-	return nil
+
+	if len(versions) > 0 {
+		status.Latest = versions[len(versions)-1]
+	}
+	return &status, nil
+}
+
+// Stop after the migration being applied, leaving the schema at a clean version.
+func (m *Migrator) Stop() {
+	select {
+	case m.migrate.GracefulStop <- true:
+	default:
+	}
+}
+
+func (m *Migrator) Close() error {
+	source_err, database_err := m.migrate.Close()
+	return errors.Join(source_err, database_err)
+}
+
+func ignoreNoChange(err error) error {
+	if errors.Is(err, migrate.ErrNoChange) {
+		return nil
+	}
+	return err
+}
+
+// Versions of the migrations built in, oldest first.
+func migrationVersions() ([]uint, error) {
+	entries, err := fs.ReadDir(migrationFiles, migrationDir)
+
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make([]uint, 0, len(entries)/2)
+
+	for _, entry := range entries {
+		prefix, _, found := strings.Cut(entry.Name(), "_")
+
+		if !found || !strings.HasSuffix(entry.Name(), ".up.sql") {
+			continue
+		}
+
+		version, err := strconv.ParseUint(prefix, 10, 64)
+
+		if err != nil {
+			continue
+		}
+		versions = append(versions, uint(version))
+	}
+	slices.Sort(versions)
+
+	return versions, nil
+}
+
+// Version of the newest migration built in.
+func LatestMigrationVersion() (uint, error) {
+	versions, err := migrationVersions()
+
+	if err != nil || len(versions) == 0 {
+		return 0, err
+	}
+	return versions[len(versions)-1], nil
+}
+
+// Logs the migrations as they are applied.
+type migrateLogger struct{}
+
+func (migrateLogger) Printf(format string, v ...any) {
+	slog.Info(strings.TrimSpace(fmt.Sprintf(format, v...)))
+}
+
+func (migrateLogger) Verbose() bool {
+	return false
 }
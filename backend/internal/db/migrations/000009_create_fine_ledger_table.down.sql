@@ -0,0 +1,7 @@
+ALTER TABLE book_borrow_list ADD COLUMN fine_paid NUMERIC(6, 2) DEFAULT 0;
+
+UPDATE book_borrow_list as l SET fine_paid = coalesce((
+    select sum(f.amount) from fine_ledger as f inner join book_borrow as b on f.borrow_id = b.id
+    where b.list_id = l.id and f.entry_type = 'payment'), 0);
+
+DROP TABLE IF EXISTS fine_ledger;
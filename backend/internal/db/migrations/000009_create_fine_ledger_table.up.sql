@@ -0,0 +1,24 @@
+CREATE TABLE fine_ledger (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL,
+    borrow_id INTEGER,
+    entry_type VARCHAR(16) NOT NULL CHECK (entry_type IN ('charge', 'payment', 'waiver', 'refund')),
+    amount NUMERIC(10, 2) NOT NULL CHECK (amount > 0),
+    note TEXT NOT NULL DEFAULT '',
+    recorded_by INTEGER,
+    created_at TIMESTAMP NOT NULL,
+    FOREIGN KEY (user_id) REFERENCES users(id),
+    FOREIGN KEY (borrow_id) REFERENCES book_borrow(id),
+    FOREIGN KEY (recorded_by) REFERENCES users(id)
+);
+
+CREATE INDEX fine_ledger_user_id ON fine_ledger (user_id);
+
+-- Carrying the old per list fine_paid amounts over as a settled charge and payment pair.
+INSERT INTO fine_ledger (user_id, borrow_id, entry_type, amount, note, created_at)
+SELECT l.user_id, (select min(b.id) from book_borrow as b where b.list_id = l.id), e.entry_type, l.fine_paid,
+       'migrated from book_borrow_list.fine_paid', l.updated_at
+FROM book_borrow_list as l CROSS JOIN (VALUES ('charge'), ('payment')) as e(entry_type)
+WHERE l.fine_paid > 0;
+
+ALTER TABLE book_borrow_list DROP COLUMN fine_paid;
@@ -0,0 +1,10 @@
+ALTER TABLE book_borrow
+    DROP COLUMN due_date,
+    DROP COLUMN renew_count,
+    DROP COLUMN returned_at,
+    DROP COLUMN fine_per_day,
+    DROP COLUMN max_fine;
+
+DROP TABLE IF EXISTS borrow_policy;
+
+ALTER TABLE users DROP COLUMN member_type;
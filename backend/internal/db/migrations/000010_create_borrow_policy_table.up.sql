@@ -0,0 +1,33 @@
+ALTER TABLE users ADD COLUMN member_type VARCHAR(32) NOT NULL DEFAULT 'standard';
+
+-- A null member_type or category matches any member type or category.
+CREATE TABLE borrow_policy (
+    id SERIAL PRIMARY KEY,
+    member_type VARCHAR(32),
+    category VARCHAR(256),
+    loan_days INTEGER NOT NULL CHECK (loan_days > 0),
+    max_renewals INTEGER NOT NULL DEFAULT 0 CHECK (max_renewals >= 0),
+    max_loans INTEGER NOT NULL CHECK (max_loans > 0),
+    fine_per_day NUMERIC(6, 2),
+    max_fine NUMERIC(10, 2),
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    FOREIGN KEY (category) REFERENCES category(category_name)
+);
+
+CREATE UNIQUE INDEX borrow_policy_rule ON borrow_policy (coalesce(member_type, ''), coalesce(category, ''));
+
+INSERT INTO borrow_policy (member_type, category, loan_days, max_renewals, max_loans, created_at, updated_at)
+VALUES (NULL, NULL, 14, 1, 5, now(), now());
+
+ALTER TABLE book_borrow
+    ADD COLUMN due_date TIMESTAMP,
+    ADD COLUMN renew_count INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN returned_at TIMESTAMP,
+    ADD COLUMN fine_per_day NUMERIC(6, 2) NOT NULL DEFAULT 0,
+    ADD COLUMN max_fine NUMERIC(10, 2);
+
+UPDATE book_borrow as b SET due_date = l.due_date, fine_per_day = coalesce(bk.fine_per_day, 0)
+FROM book_borrow_list as l, book as bk WHERE b.list_id = l.id and b.book_id = bk.id;
+
+ALTER TABLE book_borrow ALTER COLUMN due_date SET NOT NULL;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS library_closure;
+DROP TABLE IF EXISTS library_hours;
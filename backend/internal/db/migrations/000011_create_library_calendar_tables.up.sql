@@ -0,0 +1,27 @@
+-- Weekly opening hours, weekday follows Go's time.Weekday (0 is Sunday).
+CREATE TABLE library_hours (
+    weekday SMALLINT PRIMARY KEY CHECK (weekday BETWEEN 0 AND 6),
+    opens_at TIME,
+    closes_at TIME,
+    is_closed BOOLEAN NOT NULL DEFAULT false,
+    updated_at TIMESTAMP NOT NULL,
+    CHECK (is_closed OR (opens_at IS NOT NULL AND closes_at IS NOT NULL AND opens_at < closes_at))
+);
+
+INSERT INTO library_hours (weekday, opens_at, closes_at, is_closed, updated_at) VALUES
+    (0, NULL, NULL, true, now()),
+    (1, '09:00', '18:00', false, now()),
+    (2, '09:00', '18:00', false, now()),
+    (3, '09:00', '18:00', false, now()),
+    (4, '09:00', '18:00', false, now()),
+    (5, '09:00', '18:00', false, now()),
+    (6, '09:00', '18:00', false, now());
+
+-- One off holidays, or closures repeating on the same day every year when recurring is set.
+CREATE TABLE library_closure (
+    id SERIAL PRIMARY KEY,
+    closure_date DATE NOT NULL UNIQUE,
+    recurring BOOLEAN NOT NULL DEFAULT false,
+    description TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL
+);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS book_borrow_open_due_date;
+
+ALTER TABLE book_borrow
+    DROP COLUMN overdue,
+    DROP COLUMN reminder_sent_at;
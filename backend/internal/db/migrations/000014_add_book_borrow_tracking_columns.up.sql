@@ -0,0 +1,5 @@
+ALTER TABLE book_borrow
+    ADD COLUMN overdue BOOLEAN NOT NULL DEFAULT false,
+    ADD COLUMN reminder_sent_at TIMESTAMP;
+
+CREATE INDEX book_borrow_open_due_date ON book_borrow (due_date) WHERE returned = false;
@@ -0,0 +1,15 @@
+CREATE TABLE job_run (
+    id SERIAL PRIMARY KEY,
+    job_name VARCHAR(64) NOT NULL,
+    instance VARCHAR(128) NOT NULL,
+    status VARCHAR(16) NOT NULL CHECK (status IN ('running', 'succeeded', 'failed')),
+    error TEXT NOT NULL DEFAULT '',
+    scheduled_at TIMESTAMP,
+    started_at TIMESTAMP NOT NULL,
+    finished_at TIMESTAMP
+);
+
+CREATE INDEX job_run_job_name_started_at ON job_run (job_name, started_at DESC);
+
+-- One run per scheduled slot of a job, whichever instance claims it first.
+CREATE UNIQUE INDEX job_run_job_name_scheduled_at ON job_run (job_name, scheduled_at);
@@ -0,0 +1,35 @@
+-- An empty event_types list subscribes to every event, "loan.*" to every loan event.
+CREATE TABLE webhook_subscription (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(128) NOT NULL,
+    url TEXT NOT NULL,
+    secret VARCHAR(128) NOT NULL,
+    event_types JSONB NOT NULL DEFAULT '[]',
+    active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+CREATE TABLE webhook_delivery (
+    id BIGSERIAL PRIMARY KEY,
+    subscription_id INTEGER NOT NULL,
+    event_id BIGINT NOT NULL,
+    event_type VARCHAR(64) NOT NULL,
+    payload JSONB NOT NULL,
+    status VARCHAR(16) NOT NULL CHECK (status IN ('pending', 'succeeded', 'dead')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    response_status INTEGER,
+    next_attempt_at TIMESTAMP,
+    delivered_at TIMESTAMP,
+    replay_of BIGINT,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    FOREIGN KEY (subscription_id) REFERENCES webhook_subscription(id) ON DELETE CASCADE,
+    FOREIGN KEY (event_id) REFERENCES outbox_event(id),
+    FOREIGN KEY (replay_of) REFERENCES webhook_delivery(id)
+);
+
+-- The outbox publishes at least once, an event is only queued once per subscription.
+CREATE UNIQUE INDEX webhook_delivery_event ON webhook_delivery (subscription_id, event_id) WHERE replay_of IS NULL;
+CREATE INDEX webhook_delivery_pending ON webhook_delivery (next_attempt_at) WHERE status = 'pending';
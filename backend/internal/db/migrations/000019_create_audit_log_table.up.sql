@@ -0,0 +1,22 @@
+CREATE TABLE audit_log (
+    id BIGSERIAL PRIMARY KEY,
+    actor_id INTEGER,
+    actor_email VARCHAR(256) NOT NULL DEFAULT '',
+    action VARCHAR(256) NOT NULL,
+    path TEXT NOT NULL,
+    entity_type VARCHAR(64) NOT NULL DEFAULT '',
+    entity_id VARCHAR(64) NOT NULL DEFAULT '',
+    before JSONB,
+    after JSONB,
+    diff JSONB,
+    ip_address VARCHAR(64) NOT NULL DEFAULT '',
+    request_id VARCHAR(128) NOT NULL DEFAULT '',
+    status_code INTEGER NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    FOREIGN KEY (actor_id) REFERENCES users(id) ON DELETE SET NULL
+);
+
+CREATE INDEX audit_log_created_at ON audit_log (created_at);
+CREATE INDEX audit_log_actor ON audit_log (actor_id, created_at);
+CREATE INDEX audit_log_entity ON audit_log (entity_type, entity_id, created_at);
+CREATE INDEX audit_log_request ON audit_log (request_id);
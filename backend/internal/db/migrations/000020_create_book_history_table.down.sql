@@ -0,0 +1,7 @@
+ALTER TABLE book_borrow DROP COLUMN IF EXISTS book_revision;
+
+DROP TRIGGER IF EXISTS book_revision_update ON book;
+DROP TRIGGER IF EXISTS book_revision_insert ON book;
+DROP FUNCTION IF EXISTS record_book_revision();
+
+DROP TABLE IF EXISTS book_history;
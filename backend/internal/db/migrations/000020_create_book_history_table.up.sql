@@ -0,0 +1,56 @@
+-- Every revision of a book's catalog record, valid from valid_from until valid_to (null for
+-- the current revision). Stock moves through book_count on every loan and is not versioned.
+CREATE TABLE book_history (
+    book_id INTEGER NOT NULL,
+    revision INTEGER NOT NULL,
+    title VARCHAR(256) NOT NULL,
+    category VARCHAR(64) NOT NULL,
+    publisher VARCHAR(64) NOT NULL,
+    price NUMERIC(10, 2),
+    fine_per_day NUMERIC(6, 2),
+    author_id INTEGER NOT NULL,
+    archive BOOLEAN,
+    valid_from TIMESTAMP NOT NULL,
+    valid_to TIMESTAMP,
+    PRIMARY KEY (book_id, revision),
+    FOREIGN KEY (book_id) REFERENCES book(id)
+);
+
+CREATE UNIQUE INDEX book_history_current ON book_history (book_id) WHERE valid_to IS NULL;
+
+CREATE OR REPLACE FUNCTION record_book_revision() RETURNS TRIGGER AS $$
+DECLARE
+    next_revision INTEGER;
+BEGIN
+    UPDATE book_history SET valid_to = NEW.updated_at
+    WHERE book_id = NEW.id AND valid_to IS NULL;
+
+    SELECT coalesce(max(revision), 0) + 1 INTO next_revision FROM book_history WHERE book_id = NEW.id;
+
+    INSERT INTO book_history (book_id, revision, title, category, publisher, price, fine_per_day, author_id, archive, valid_from)
+    VALUES (NEW.id, next_revision, NEW.title, NEW.category, NEW.publisher, NEW.price, NEW.fine_per_day, NEW.author_id, NEW.archive,
+            CASE WHEN TG_OP = 'INSERT' THEN NEW.created_at ELSE NEW.updated_at END);
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER book_revision_insert AFTER INSERT ON book
+FOR EACH ROW EXECUTE FUNCTION record_book_revision();
+
+CREATE TRIGGER book_revision_update AFTER UPDATE ON book
+FOR EACH ROW WHEN (
+    (OLD.title, OLD.category, OLD.publisher, OLD.price, OLD.fine_per_day, OLD.author_id, OLD.archive)
+    IS DISTINCT FROM
+    (NEW.title, NEW.category, NEW.publisher, NEW.price, NEW.fine_per_day, NEW.author_id, NEW.archive)
+) EXECUTE FUNCTION record_book_revision();
+
+-- Earlier changes were not kept, the current record is the best known first revision.
+INSERT INTO book_history (book_id, revision, title, category, publisher, price, fine_per_day, author_id, archive, valid_from)
+SELECT id, 1, title, category, publisher, price, fine_per_day, author_id, archive, created_at FROM book;
+
+ALTER TABLE book_borrow ADD COLUMN book_revision INTEGER;
+
+UPDATE book_borrow SET book_revision = 1;
+
+ALTER TABLE book_borrow ADD FOREIGN KEY (book_id, book_revision) REFERENCES book_history(book_id, revision);
@@ -0,0 +1,3 @@
+ALTER TABLE users DROP COLUMN IF EXISTS version;
+ALTER TABLE author DROP COLUMN IF EXISTS version;
+ALTER TABLE book DROP COLUMN IF EXISTS version;
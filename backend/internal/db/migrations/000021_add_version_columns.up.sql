@@ -0,0 +1,4 @@
+-- Bumped on every edit, the version is the ETag clients send back in If-Match.
+ALTER TABLE book ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE author ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE users ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
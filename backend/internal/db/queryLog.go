@@ -0,0 +1,62 @@
+package db
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+)
+
+const (
+	QueryLogOff  = "off"
+	QueryLogSlow = "slow"
+	QueryLogAll  = "all"
+)
+
+type QueryLogConfig struct {
+	// off, slow for the statements taking SlowThreshold or longer, or all.
+	Mode          string
+	SlowThreshold time.Duration
+}
+
+// Logs the statements pgx runs with their duration. The arguments are left out, they may
+// hold password hashes and tokens.
+type queryLogger struct {
+	config QueryLogConfig
+}
+
+func (q queryLogger) Log(ctx context.Context, level pgx.LogLevel, msg string, data map[string]interface{}) {
+	elapsed, ok := data["time"].(time.Duration)
+
+	// Only the statements are timed, not the connection events.
+	if !ok {
+		return
+	}
+
+	slow := elapsed >= q.config.SlowThreshold
+
+	if !slow && q.config.Mode != QueryLogAll {
+		return
+	}
+
+	attrs := []slog.Attr{slog.String("operation", msg), slog.Duration("duration", elapsed)}
+
+	if statement, ok := data["sql"].(string); ok {
+		attrs = append(attrs, slog.String("sql", statement))
+	}
+
+	if rows, ok := data["rowCount"].(int); ok {
+		attrs = append(attrs, slog.Int("rows", rows))
+	}
+
+	if err, ok := data["err"].(error); ok {
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+
+	if slow {
+		slog.LogAttrs(ctx, slog.LevelWarn, "Slow SQL statement", attrs...)
+	} else {
+		slog.LogAttrs(ctx, slog.LevelInfo, "SQL statement", attrs...)
+	}
+}
@@ -0,0 +1,100 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	StatusUp   = "up"
+	StatusDown = "down"
+
+	defaultCheckTimeout = 2 * time.Second
+)
+
+// Checks a dependency, returning details worth reporting along with its status. The
+// dependency is down when it returns an error.
+type CheckFunc func(ctx context.Context) (any, error)
+
+type CheckResult struct {
+	Status     string `json:"status"`
+	Error      string `json:"error,omitempty"`
+	Details    any    `json:"details,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+type Report struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks"`
+}
+
+type namedCheck struct {
+	name  string
+	check CheckFunc
+}
+
+// Runs the dependency checks of the readiness probe. The checks run concurrently, each with
+// a timeout on its context so a hanging dependency does not hang the probe.
+type Checker struct {
+	checks  []namedCheck
+	timeout time.Duration
+}
+
+func NewChecker() *Checker {
+	return &Checker{timeout: defaultCheckTimeout}
+}
+
+func (c *Checker) Add(name string, check CheckFunc) {
+	c.checks = append(c.checks, namedCheck{name: name, check: check})
+}
+
+// Run every check, the report is up only when all of them are.
+func (c *Checker) Run(ctx context.Context) Report {
+	report := Report{Status: StatusUp, Checks: make(map[string]CheckResult, len(c.checks))}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for _, named := range c.checks {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			result := c.runCheck(ctx, named.check)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			report.Checks[named.name] = result
+
+			if result.Status != StatusUp {
+				report.Status = StatusDown
+			}
+		}()
+	}
+	wg.Wait()
+
+	return report
+}
+
+func (c *Checker) runCheck(ctx context.Context, check CheckFunc) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	started_at := time.Now()
+	details, err := check(ctx)
+
+	result := CheckResult{
+		Status:     StatusUp,
+		Details:    details,
+		DurationMs: time.Since(started_at).Milliseconds(),
+	}
+
+	if err != nil {
+		result.Status = StatusDown
+		result.Error = err.Error()
+	}
+	return result
+}
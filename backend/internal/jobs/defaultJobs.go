@@ -0,0 +1,66 @@
+package jobs
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/services"
+)
+
+// Default cron schedule of each job RegisterDefaultJobs registers, by job name.
+var DefaultSchedules = map[string]string{
+	"mark-overdue-loans":       "*/15 * * * *",
+	"send-due-soon-reminders":  "0 8 * * *",
+	"expire-uncollected-holds": "*/30 * * * *",
+	"retry-notifications":      "*/5 * * * *",
+	"purge-expired-tokens":     "0 3 * * *",
+}
+
+// Register the overdue, due-soon reminder, hold expiry, notification retry and token purge jobs.
+func RegisterDefaultJobs(scheduler *Scheduler, libService *services.LibraryService, schedules map[string]string) error {
+	default_jobs := []struct {
+		name string
+		run  JobFunc
+	}{
+		{"mark-overdue-loans", func(ctx context.Context) error {
+			marked, err := libService.MarkOverdueLoans(ctx)
+			slog.InfoContext(ctx, "Marked loans overdue", "count", marked)
+			return err
+		}},
+		{"send-due-soon-reminders", func(ctx context.Context) error {
+			sent, err := libService.SendDueSoonReminders(ctx)
+			slog.InfoContext(ctx, "Sent due soon reminders", "count", sent)
+			return err
+		}},
+		{"expire-uncollected-holds", func(ctx context.Context) error {
+			expired, err := libService.ExpireUncollectedHolds(ctx)
+			slog.InfoContext(ctx, "Expired uncollected holds", "count", expired)
+			return err
+		}},
+		{"retry-notifications", func(ctx context.Context) error {
+			retried, err := libService.RetryNotifications(ctx)
+			slog.InfoContext(ctx, "Retried notifications", "count", retried)
+			return err
+		}},
+		{"purge-expired-tokens", func(ctx context.Context) error {
+			purged, err := libService.PurgeExpiredTokens(ctx)
+			slog.InfoContext(ctx, "Purged expired tokens", "count", purged)
+			return err
+		}},
+	}
+
+	for _, job := range default_jobs {
+		spec := DefaultSchedules[job.name]
+
+		if schedule := schedules[job.name]; schedule != "" {
+			spec = schedule
+		}
+
+		err := scheduler.Register(job.name, spec, job.run)
+
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
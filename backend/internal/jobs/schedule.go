@@ -0,0 +1,173 @@
+package jobs
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Cron like schedule with the five standard fields: minute, hour, day of month,
+// month and day of week. Fields take *, lists, ranges and steps, e.g. "*/15 8-18 * * 1-5".
+type Schedule struct {
+	minute      uint64
+	hour        uint64
+	day         uint64
+	month       uint64
+	weekday     uint64
+	any_day     bool
+	any_weekday bool
+}
+
+type scheduleField struct {
+	min int
+	max int
+}
+
+var scheduleFields = []scheduleField{
+	{0, 59}, // minute
+	{0, 23}, // hour
+	{1, 31}, // day of month
+	{1, 12}, // month
+	{0, 6},  // day of week, 0 is Sunday
+}
+
+var scheduleShortcuts = map[string]string{
+	"@hourly":  "0 * * * *",
+	"@daily":   "0 0 * * *",
+	"@weekly":  "0 0 * * 0",
+	"@monthly": "0 0 1 * *",
+}
+
+func ParseSchedule(spec string) (*Schedule, error) {
+	if shortcut, ok := scheduleShortcuts[spec]; ok {
+		spec = shortcut
+	}
+
+	parts := strings.Fields(spec)
+
+	if len(parts) != len(scheduleFields) {
+		return nil, errors.New(fmt.Sprintf("Schedule %q must have 5 fields.", spec))
+	}
+
+	bits := make([]uint64, len(parts))
+
+	for i, part := range parts {
+		field_bits, err := parseScheduleField(part, scheduleFields[i])
+
+		if err != nil {
+			return nil, fmt.Errorf("schedule %q: %w", spec, err)
+		}
+		bits[i] = field_bits
+	}
+
+	return &Schedule{
+		minute:      bits[0],
+		hour:        bits[1],
+		day:         bits[2],
+		month:       bits[3],
+		weekday:     bits[4],
+		any_day:     parts[2] == "*",
+		any_weekday: parts[4] == "*",
+	}, nil
+}
+
+func parseScheduleField(field string, bounds scheduleField) (uint64, error) {
+	var bits uint64
+
+	for _, item := range strings.Split(field, ",") {
+		value_range, step_value, has_step := strings.Cut(item, "/")
+		step := 1
+
+		if has_step {
+			parsed_step, err := strconv.Atoi(step_value)
+
+			if err != nil || parsed_step <= 0 {
+				return 0, errors.New(fmt.Sprintf("invalid step %q", item))
+			}
+			step = parsed_step
+		}
+
+		start, end := bounds.min, bounds.max
+
+		if value_range != "*" {
+			low, high, is_range := strings.Cut(value_range, "-")
+
+			parsed_low, err := strconv.Atoi(low)
+
+			if err != nil {
+				return 0, errors.New(fmt.Sprintf("invalid value %q", item))
+			}
+			start, end = parsed_low, parsed_low
+
+			if is_range {
+				parsed_high, err := strconv.Atoi(high)
+
+				if err != nil {
+					return 0, errors.New(fmt.Sprintf("invalid range %q", item))
+				}
+				end = parsed_high
+			} else if has_step {
+				end = bounds.max
+			}
+		}
+
+		if start < bounds.min || end > bounds.max || start > end {
+			return 0, errors.New(fmt.Sprintf("%q is out of range %d-%d", item, bounds.min, bounds.max))
+		}
+
+		for value := start; value <= end; value += step {
+			bits |= 1 << uint(value)
+		}
+	}
+	return bits, nil
+}
+
+func (s *Schedule) matchesDay(t time.Time) bool {
+	day_match := s.day&(1<<uint(t.Day())) != 0
+	weekday_match := s.weekday&(1<<uint(t.Weekday())) != 0
+
+	// Like cron, when both day fields are restricted either of them may match.
+	switch {
+	case s.any_day && s.any_weekday:
+		return true
+	case s.any_day:
+		return weekday_match
+	case s.any_weekday:
+		return day_match
+	default:
+		return day_match || weekday_match
+	}
+}
+
+// First time strictly after the given time that matches the schedule.
+func (s *Schedule) Next(after time.Time) time.Time {
+	t := after.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+
+		if !s.matchesDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	// Schedules like "0 0 31 2 *" never match.
+	return time.Time{}
+}
@@ -0,0 +1,107 @@
+package jobs
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseSchedule(t *testing.T) {
+	tests := []struct {
+		spec    string
+		wantErr bool
+	}{
+		{"* * * * *", false},
+		{"*/15 8-18 * * 1-5", false},
+		{"0,30 9 1,15 * *", false},
+		{"5/10 * * * *", false},
+		{"0 0 * 1-12/3 0", false},
+		{"@hourly", false},
+		{"@daily", false},
+		{"@weekly", false},
+		{"@monthly", false},
+		{"", true},
+		{"* * * *", true},
+		{"* * * * * *", true},
+		{"@yearly", true},
+		{"60 * * * *", true},
+		{"* 24 * * *", true},
+		{"* * 0 * *", true},
+		{"* * * 13 *", true},
+		{"* * * * 7", true},
+		{"10-5 * * * *", true},
+		{"*/0 * * * *", true},
+		{"*/-1 * * * *", true},
+		{"a * * * *", true},
+		{"1-b * * * *", true},
+		{"1,,2 * * * *", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.spec, func(t *testing.T) {
+			_, err := ParseSchedule(test.spec)
+
+			if (err != nil) != test.wantErr {
+				t.Errorf("ParseSchedule(%q) error = %v, want an error %v", test.spec, err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestScheduleNext(t *testing.T) {
+	at := func(value string) time.Time {
+		parsed, err := time.Parse("2006-01-02 15:04:05", value)
+
+		if err != nil {
+			t.Fatalf("invalid time %q: %v", value, err)
+		}
+		return parsed
+	}
+
+	tests := []struct {
+		name  string
+		spec  string
+		after string
+		want  string
+	}{
+		{"every minute", "* * * * *", "2025-03-03 10:30:00", "2025-03-03 10:31:00"},
+		{"drops the seconds", "* * * * *", "2025-03-03 10:30:59", "2025-03-03 10:31:00"},
+		{"strictly after a match", "30 10 * * *", "2025-03-03 10:30:00", "2025-03-04 10:30:00"},
+		{"steps of minutes", "*/15 * * * *", "2025-03-03 10:31:00", "2025-03-03 10:45:00"},
+		{"steps from a start", "5/20 * * * *", "2025-03-03 10:26:00", "2025-03-03 10:45:00"},
+		{"lists", "0,30 9 * * *", "2025-03-03 09:10:00", "2025-03-03 09:30:00"},
+		{"next hour", "0 * * * *", "2025-03-03 10:30:00", "2025-03-03 11:00:00"},
+		{"next day", "@daily", "2025-03-03 10:30:00", "2025-03-04 00:00:00"},
+		{"next month", "@monthly", "2025-03-03 10:30:00", "2025-04-01 00:00:00"},
+		{"next year", "0 0 1 1 *", "2025-03-03 10:30:00", "2026-01-01 00:00:00"},
+		{"weekdays only", "0 9 * * 1-5", "2025-03-07 10:00:00", "2025-03-10 09:00:00"},
+		{"sunday for weekly", "@weekly", "2025-03-03 10:30:00", "2025-03-09 00:00:00"},
+		{"either day field", "0 0 15 * 1", "2025-03-04 00:00:00", "2025-03-10 00:00:00"},
+		{"day of month alone", "0 0 15 * *", "2025-03-04 00:00:00", "2025-03-15 00:00:00"},
+		{"skips short months", "0 0 31 * *", "2025-04-01 00:00:00", "2025-05-31 00:00:00"},
+		{"leap day", "0 0 29 2 *", "2025-03-01 00:00:00", "2028-02-29 00:00:00"},
+		{"end of the year", "59 23 31 12 *", "2025-12-31 23:59:00", "2026-12-31 23:59:00"},
+		{"never", "0 0 31 2 *", "2025-03-03 10:30:00", ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			schedule, err := ParseSchedule(test.spec)
+
+			if err != nil {
+				t.Fatalf("ParseSchedule(%q) error = %v", test.spec, err)
+			}
+
+			var want time.Time
+
+			if test.want != "" {
+				want = at(test.want)
+			}
+
+			got := schedule.Next(at(test.after))
+
+			if !got.Equal(want) {
+				t.Errorf("Next(%s) of %q = %v, want %v", test.after, test.spec, got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,281 @@
+package jobs
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/data"
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer(tracing.TracerName)
+
+// Job loops beat every interval and are considered stuck when they miss a few beats.
+const (
+	heartbeatInterval = 15 * time.Second
+	heartbeatTimeout  = 3 * heartbeatInterval
+)
+
+type JobFunc func(ctx context.Context) error
+
+type Job struct {
+	Name     string
+	Schedule *Schedule
+	Run      JobFunc
+}
+
+// Records the history of job runs.
+type RunRecorder interface {
+	// Claim the slot of the job scheduled at scheduled_at, data.ErrJobRunClaimed when another
+	// instance already did.
+	StartJobRun(ctx context.Context, job_name, instance string, scheduled_at time.Time) (*data.JobRun, error)
+	FinishJobRun(ctx context.Context, run_id int, job_err error) error
+}
+
+// In process scheduler. Every instance of the server runs the scheduler. Each scheduled slot
+// of a job is run once, by the instance whose run claims it first, and a Postgres advisory
+// lock per job keeps the runs of a job from overlapping.
+type Scheduler struct {
+	db       *sql.DB
+	recorder RunRecorder
+	instance string
+	jobs     []*Job
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+
+	mu         sync.Mutex
+	heartbeats map[string]time.Time
+	running    map[string]time.Time
+}
+
+type JobHeartbeat struct {
+	LastHeartbeat *time.Time `json:"last_heartbeat"`
+	RunningSince  *time.Time `json:"running_since,omitempty"`
+}
+
+func NewScheduler(db *sql.DB, recorder RunRecorder) *Scheduler {
+	hostname, _ := os.Hostname()
+
+	return &Scheduler{
+		db:         db,
+		recorder:   recorder,
+		instance:   fmt.Sprintf("%s-%d", hostname, os.Getpid()),
+		heartbeats: make(map[string]time.Time),
+		running:    make(map[string]time.Time),
+	}
+}
+
+func (s *Scheduler) Register(name, spec string, run JobFunc) error {
+	schedule, err := ParseSchedule(spec)
+
+	if err != nil {
+		return err
+	}
+
+	s.jobs = append(s.jobs, &Job{Name: name, Schedule: schedule, Run: run})
+	return nil
+}
+
+func (s *Scheduler) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+
+	for _, job := range s.jobs {
+		s.wg.Add(1)
+		go s.loop(ctx, job)
+	}
+	slog.Info("Scheduler started", "jobs", len(s.jobs), "instance", s.instance)
+}
+
+// Stop scheduling new runs and wait for the running ones to finish.
+func (s *Scheduler) Stop() {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.wg.Wait()
+}
+
+func (s *Scheduler) loop(ctx context.Context, job *Job) {
+	defer s.wg.Done()
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		s.beat(job.Name)
+		next := job.Schedule.Next(time.Now())
+
+		if next.IsZero() {
+			slog.Warn("Job never matches its schedule, not scheduling it", "job", job.Name)
+			return
+		}
+
+		if !s.waitUntil(ctx, job.Name, next, heartbeat) {
+			return
+		}
+
+		s.setRunning(job.Name, true)
+		s.run(ctx, job, next)
+		s.setRunning(job.Name, false)
+	}
+}
+
+// Run the job in a trace of its own, holding the spans of the service calls and statements
+// of the run.
+func (s *Scheduler) run(ctx context.Context, job *Job, scheduled_at time.Time) {
+	ctx, span := tracer.Start(ctx, "job "+job.Name, trace.WithNewRoot(), trace.WithAttributes(attribute.String("job.name", job.Name)))
+	defer span.End()
+
+	err := s.runOnce(ctx, job, scheduled_at)
+
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		slog.ErrorContext(ctx, "Job failed", "job", job.Name, "error", err)
+	}
+}
+
+// Wait for the time while beating, false when the scheduler is stopped first.
+func (s *Scheduler) waitUntil(ctx context.Context, job_name string, at time.Time, heartbeat *time.Ticker) bool {
+	timer := time.NewTimer(time.Until(at))
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-heartbeat.C:
+			s.beat(job_name)
+		case <-timer.C:
+			return true
+		}
+	}
+}
+
+func (s *Scheduler) beat(job_name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.heartbeats[job_name] = time.Now()
+}
+
+func (s *Scheduler) setRunning(job_name string, running bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if running {
+		s.running[job_name] = time.Now()
+	} else {
+		delete(s.running, job_name)
+	}
+}
+
+// Health check that every job loop is alive: it is running the job or it has beaten recently.
+func (s *Scheduler) CheckHeartbeats(ctx context.Context) (any, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	details := make(map[string]JobHeartbeat, len(s.jobs))
+	stale := make([]string, 0)
+
+	for _, job := range s.jobs {
+		var heartbeat JobHeartbeat
+
+		last_heartbeat, ok := s.heartbeats[job.Name]
+
+		if ok {
+			heartbeat.LastHeartbeat = &last_heartbeat
+		}
+
+		if running_since, running := s.running[job.Name]; running {
+			heartbeat.RunningSince = &running_since
+		} else if !ok || now.Sub(last_heartbeat) > heartbeatTimeout {
+			stale = append(stale, job.Name)
+		}
+		details[job.Name] = heartbeat
+	}
+
+	if len(stale) > 0 {
+		return details, fmt.Errorf("no heartbeat within %s from %s", heartbeatTimeout, strings.Join(stale, ", "))
+	}
+	return details, nil
+}
+
+func advisoryLockKey(job_name string) int64 {
+	hash := fnv.New64a()
+	hash.Write([]byte("jobs:" + job_name))
+	return int64(hash.Sum64())
+}
+
+// Run the job for the slot unless another instance is running it or already ran the slot.
+func (s *Scheduler) runOnce(ctx context.Context, job *Job, scheduled_at time.Time) error {
+	// Advisory locks belong to a session, so lock and unlock on the same connection.
+	conn, err := s.db.Conn(ctx)
+
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	key := advisoryLockKey(job.Name)
+
+	var acquired bool
+
+	err = conn.QueryRowContext(ctx, `select pg_try_advisory_lock($1);`, key).Scan(&acquired)
+
+	if err != nil {
+		return err
+	}
+
+	if !acquired {
+		return nil
+	}
+
+	defer conn.ExecContext(context.Background(), `select pg_advisory_unlock($1);`, key)
+
+	// The lock is released after the run, an instance whose timer fired a little later must
+	// not run the slot again.
+	run, err := s.recorder.StartJobRun(ctx, job.Name, s.instance, scheduled_at)
+
+	if errors.Is(err, data.ErrJobRunClaimed) {
+		slog.DebugContext(ctx, "Job slot already run by another instance", "job", job.Name, "scheduled_at", scheduled_at)
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	job_err := runJob(ctx, job)
+
+	// The outcome is recorded even when the job was cancelled by Stop.
+	err = s.recorder.FinishJobRun(context.WithoutCancel(ctx), run.ID, job_err)
+
+	if err != nil {
+		return err
+	}
+	return job_err
+}
+
+// Run the job turning a panic into an error so the scheduler keeps going.
+func runJob(ctx context.Context, job *Job) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = errors.New(fmt.Sprintf("panic: %v", recovered))
+		}
+	}()
+	return job.Run(ctx)
+}
@@ -0,0 +1,131 @@
+// Package logging sets up the structured logger of the server. Every record logged with a
+// context carries the id of the request and the trace it belongs to, and the attributes
+// holding secrets are redacted.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/tracing"
+)
+
+const (
+	FormatJSON = "json"
+	FormatText = "text"
+)
+
+const redacted = "[REDACTED]"
+
+// Attributes whose key contains one of these are logged as [REDACTED].
+var sensitiveKeys = []string{"password", "secret", "token", "authorization", "cookie", "api_key", "dsn"}
+
+type Settings struct {
+	// debug, info, warn or error.
+	Level string
+	// json or text.
+	Format string
+}
+
+func ParseLevel(value string) (slog.Level, error) {
+	var level slog.Level
+
+	err := level.UnmarshalText([]byte(value))
+
+	if err != nil {
+		return level, fmt.Errorf("unknown log level %q", value)
+	}
+	return level, nil
+}
+
+// Make the logger of the settings the default one, which the log package writes to as well.
+func Setup(settings Settings) (*slog.Logger, error) {
+	logger, err := New(os.Stdout, settings)
+
+	if err != nil {
+		return nil, err
+	}
+
+	slog.SetDefault(logger)
+
+	return logger, nil
+}
+
+func New(out io.Writer, settings Settings) (*slog.Logger, error) {
+	level, err := ParseLevel(settings.Level)
+
+	if err != nil {
+		return nil, err
+	}
+
+	options := &slog.HandlerOptions{Level: level, ReplaceAttr: redact}
+
+	var handler slog.Handler
+
+	switch settings.Format {
+	case FormatJSON, "":
+		handler = slog.NewJSONHandler(out, options)
+	case FormatText:
+		handler = slog.NewTextHandler(out, options)
+	default:
+		return nil, fmt.Errorf("unknown log format %q", settings.Format)
+	}
+	return slog.New(contextHandler{handler}), nil
+}
+
+// Logger for the libraries logging through the log package, at the level.
+func StdLogger(level slog.Level) *log.Logger {
+	return slog.NewLogLogger(slog.Default().Handler(), level)
+}
+
+func redact(groups []string, attr slog.Attr) slog.Attr {
+	key := strings.ToLower(attr.Key)
+
+	for _, sensitive := range sensitiveKeys {
+		if strings.Contains(key, sensitive) {
+			return slog.String(attr.Key, redacted)
+		}
+	}
+	return attr
+}
+
+// Adds the request and trace ids of the context to the records.
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if request_id := RequestId(ctx); request_id != "" {
+		record.AddAttrs(slog.String("request_id", request_id))
+	}
+
+	if trace_id := tracing.TraceId(ctx); trace_id != "" {
+		record.AddAttrs(slog.String("trace_id", trace_id))
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
+
+type requestIdKey struct{}
+
+func WithRequestId(ctx context.Context, request_id string) context.Context {
+	return context.WithValue(ctx, requestIdKey{}, request_id)
+}
+
+// Id of the request ctx belongs to, empty outside of a request.
+func RequestId(ctx context.Context) string {
+	request_id, _ := ctx.Value(requestIdKey{}).(string)
+	return request_id
+}
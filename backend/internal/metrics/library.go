@@ -0,0 +1,59 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/data"
+)
+
+const libraryStatsTimeout = 5 * time.Second
+
+type StatsSource interface {
+	GetLibraryStats(ctx context.Context) (*data.LibraryStats, error)
+}
+
+// Gauges of the loans, fines and stock, computed when Prometheus scrapes them so they are
+// never stale.
+type libraryCollector struct {
+	source           StatsSource
+	activeLoans      *prometheus.Desc
+	overdueLoans     *prometheus.Desc
+	outstandingFines *prometheus.Desc
+	outOfStockBooks  *prometheus.Desc
+}
+
+func newLibraryCollector(source StatsSource) *libraryCollector {
+	return &libraryCollector{
+		source:           source,
+		activeLoans:      prometheus.NewDesc(namespace+"_active_loans", "Books lent and not returned yet.", nil, nil),
+		overdueLoans:     prometheus.NewDesc(namespace+"_overdue_loans", "Books lent and not returned by their due date.", nil, nil),
+		outstandingFines: prometheus.NewDesc(namespace+"_outstanding_fines", "Fines owed by the users, net of payments and waivers.", nil, nil),
+		outOfStockBooks:  prometheus.NewDesc(namespace+"_out_of_stock_books", "Books that are not archived and have no copy left to lend.", nil, nil),
+	}
+}
+
+func (l *libraryCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- l.activeLoans
+	ch <- l.overdueLoans
+	ch <- l.outstandingFines
+	ch <- l.outOfStockBooks
+}
+
+func (l *libraryCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), libraryStatsTimeout)
+	defer cancel()
+
+	stats, err := l.source.GetLibraryStats(ctx)
+
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(l.activeLoans, err)
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(l.activeLoans, prometheus.GaugeValue, float64(stats.ActiveLoans))
+	ch <- prometheus.MustNewConstMetric(l.overdueLoans, prometheus.GaugeValue, float64(stats.OverdueLoans))
+	ch <- prometheus.MustNewConstMetric(l.outstandingFines, prometheus.GaugeValue, stats.OutstandingFines)
+	ch <- prometheus.MustNewConstMetric(l.outOfStockBooks, prometheus.GaugeValue, float64(stats.OutOfStockBooks))
+}
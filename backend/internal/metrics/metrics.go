@@ -0,0 +1,99 @@
+// Package metrics exposes the Prometheus metrics of the server: the requests it serves, its
+// database pool and the state of the library.
+package metrics
+
+import (
+	"database/sql"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/logging"
+)
+
+const namespace = "library"
+
+// Route label of the requests no route matched, so probing random paths does not add series.
+const UnmatchedRoute = "unmatched"
+
+var knownMethods = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodPost:    true,
+	http.MethodPut:     true,
+	http.MethodPatch:   true,
+	http.MethodDelete:  true,
+	http.MethodOptions: true,
+}
+
+// Metrics of the server, served by Handler.
+type Registry struct {
+	registry *prometheus.Registry
+	requests *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+}
+
+func New() *Registry {
+	r := &Registry{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "HTTP requests served, by method, route and status.",
+		}, []string{"method", "route", "status"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Time taken to serve HTTP requests, by method, route and status.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+	}
+
+	r.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		r.requests,
+		r.duration,
+	)
+	return r
+}
+
+// Record a request served. route is the pattern the request matched, not its path, so the
+// series stay bounded by the routes.
+func (r *Registry) ObserveRequest(method, route string, status int, elapsed time.Duration) {
+	if !knownMethods[method] {
+		method = "OTHER"
+	}
+
+	if route == "" {
+		route = UnmatchedRoute
+	}
+
+	status_label := strconv.Itoa(status)
+
+	r.requests.WithLabelValues(method, route, status_label).Inc()
+	r.duration.WithLabelValues(method, route, status_label).Observe(elapsed.Seconds())
+}
+
+// Export the connection pool stats of db, e.g. the connections open, in use and waited for.
+func (r *Registry) RegisterDB(db *sql.DB, name string) {
+	r.registry.MustRegister(collectors.NewDBStatsCollector(db, name))
+}
+
+// Export the library stats of source, read on every scrape.
+func (r *Registry) RegisterLibraryStats(source StatsSource) {
+	r.registry.MustRegister(newLibraryCollector(source))
+}
+
+// Metrics in the Prometheus text format. A collector failing is logged and leaves its metrics
+// out of the scrape, the others are still served.
+func (r *Registry) Handler() http.Handler {
+	return promhttp.HandlerFor(r.registry, promhttp.HandlerOpts{
+		ErrorLog:      logging.StdLogger(slog.LevelError),
+		ErrorHandling: promhttp.ContinueOnError,
+	})
+}
@@ -0,0 +1,58 @@
+package notifications
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// Writes notifications as JSON lines to a file, or to stdout for local development.
+type LogNotifier struct {
+	mu     sync.Mutex
+	writer io.Writer
+}
+
+func NewLogNotifier(path string) (*LogNotifier, error) {
+	if path == "" {
+		return &LogNotifier{writer: os.Stdout}, nil
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+
+	if err != nil {
+		return nil, err
+	}
+	return &LogNotifier{writer: file}, nil
+}
+
+func (n *LogNotifier) Channel() string {
+	return ChannelLog
+}
+
+func (n *LogNotifier) Address(recipient Recipient) string {
+	return recipient.Email
+}
+
+func (n *LogNotifier) Send(ctx context.Context, message Message) error {
+	line, err := json.Marshal(map[string]any{
+		"time":    time.Now().Format(time.RFC3339),
+		"event":   message.Event,
+		"user_id": message.UserId,
+		"to":      message.To,
+		"subject": message.Subject,
+		"body":    message.Body,
+	})
+
+	if err != nil {
+		return err
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	_, err = n.writer.Write(append(line, '\n'))
+	return err
+}
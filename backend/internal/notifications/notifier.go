@@ -0,0 +1,39 @@
+package notifications
+
+import "context"
+
+const (
+	ChannelEmail   = "email"
+	ChannelWebhook = "webhook"
+	ChannelLog     = "log"
+)
+
+const (
+	EventLoanDueSoon      = "loan_due_soon"
+	EventLoanOverdue      = "loan_overdue"
+	EventHoldReady        = "hold_ready"
+	EventAccountActivated = "account_activated"
+)
+
+type Recipient struct {
+	UserId      int
+	Name        string
+	Email       string
+	PhoneNumber string
+}
+
+type Message struct {
+	Event   string
+	UserId  int
+	To      string
+	Subject string
+	Body    string
+}
+
+// A channel notifications are delivered over.
+type Notifier interface {
+	Channel() string
+	// Address of the recipient on this channel, empty when the recipient can not be reached on it.
+	Address(recipient Recipient) string
+	Send(ctx context.Context, message Message) error
+}
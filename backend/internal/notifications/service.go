@@ -0,0 +1,279 @@
+package notifications
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/data"
+)
+
+const (
+	defaultMaxAttempts = 5
+	retryBaseDelay     = time.Minute
+	retryMaxDelay      = time.Hour
+	sendTimeout        = 30 * time.Second
+	retryBatchSize     = 100
+)
+
+type DeliveryStore interface {
+	InsertDelivery(ctx context.Context, delivery data.NotificationDelivery) (*data.NotificationDelivery, error)
+	RecordAttempt(ctx context.Context, id int, status, last_error string, next_attempt_at *time.Time) error
+	ClaimDueDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*data.NotificationDelivery, error)
+}
+
+type PreferenceStore interface {
+	GetUserPreferences(ctx context.Context, user_id int) ([]*data.NotificationPreference, error)
+}
+
+type Config struct {
+	Notifiers []Notifier
+	// Channels used for a user who has not set a preference for them.
+	DefaultChannels []string
+	MaxAttempts     int
+}
+
+// Where notifications go. Email and webhook are only set up when their host and url are set,
+// the log channel always is.
+type Settings struct {
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+	SMTPFrom     string
+	WebhookURL   string
+	// Stdout when empty.
+	LogFile         string
+	DefaultChannels []string
+	MaxAttempts     int
+}
+
+// Builds the channels from the settings.
+func NewConfig(settings Settings) (Config, error) {
+	config := Config{
+		DefaultChannels: []string{ChannelEmail, ChannelLog},
+		MaxAttempts:     settings.MaxAttempts,
+	}
+
+	if settings.SMTPHost != "" {
+		port := settings.SMTPPort
+
+		if port == "" {
+			port = "25"
+		}
+
+		config.Notifiers = append(config.Notifiers, &SMTPNotifier{
+			Host:     settings.SMTPHost,
+			Port:     port,
+			Username: settings.SMTPUsername,
+			Password: settings.SMTPPassword,
+			From:     settings.SMTPFrom,
+		})
+	}
+
+	if settings.WebhookURL != "" {
+		config.Notifiers = append(config.Notifiers, NewWebhookNotifier(settings.WebhookURL))
+	}
+
+	log_notifier, err := NewLogNotifier(settings.LogFile)
+
+	if err != nil {
+		return config, err
+	}
+	config.Notifiers = append(config.Notifiers, log_notifier)
+
+	if len(settings.DefaultChannels) > 0 {
+		config.DefaultChannels = settings.DefaultChannels
+	}
+	return config, nil
+}
+
+// Renders notifications from the event templates and delivers them over the channels the
+// user has enabled. Every delivery is recorded, failed ones are retried with backoff.
+type Service struct {
+	deliveries      DeliveryStore
+	preferences     PreferenceStore
+	notifiers       map[string]Notifier
+	default_enabled map[string]bool
+	max_attempts    int
+	wg              sync.WaitGroup
+}
+
+func NewService(deliveries DeliveryStore, preferences PreferenceStore, config Config) *Service {
+	service := Service{
+		deliveries:      deliveries,
+		preferences:     preferences,
+		notifiers:       make(map[string]Notifier),
+		default_enabled: make(map[string]bool),
+		max_attempts:    config.MaxAttempts,
+	}
+
+	if service.max_attempts <= 0 {
+		service.max_attempts = defaultMaxAttempts
+	}
+
+	for _, notifier := range config.Notifiers {
+		service.notifiers[notifier.Channel()] = notifier
+	}
+
+	for _, channel := range config.DefaultChannels {
+		service.default_enabled[strings.TrimSpace(channel)] = true
+	}
+	return &service
+}
+
+func (s *Service) Channels() []string {
+	channels := make([]string, 0, len(s.notifiers))
+
+	for channel := range s.notifiers {
+		channels = append(channels, channel)
+	}
+	return channels
+}
+
+func (s *Service) enabledChannels(ctx context.Context, user_id int) ([]string, error) {
+	preferences, err := s.preferences.GetUserPreferences(ctx, user_id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	enabled := make(map[string]bool, len(s.default_enabled))
+
+	for channel := range s.default_enabled {
+		enabled[channel] = true
+	}
+
+	for _, preference := range preferences {
+		enabled[preference.Channel] = preference.Enabled
+	}
+
+	channels := make([]string, 0, len(enabled))
+
+	for channel, is_enabled := range enabled {
+		if _, ok := s.notifiers[channel]; ok && is_enabled {
+			channels = append(channels, channel)
+		}
+	}
+	return channels, nil
+}
+
+// Queue the notification of an event on every enabled channel and make a first attempt in the background.
+func (s *Service) Notify(ctx context.Context, event string, recipient Recipient, template_data any) error {
+	subject, body, err := render(event, template_data)
+
+	if err != nil {
+		return err
+	}
+
+	channels, err := s.enabledChannels(ctx, recipient.UserId)
+
+	if err != nil {
+		return err
+	}
+
+	for _, channel := range channels {
+		address := s.notifiers[channel].Address(recipient)
+
+		if address == "" {
+			continue
+		}
+
+		// The retries only pick the delivery up if the first attempt did not get to record its outcome.
+		next_attempt_at := time.Now().Add(sendTimeout * 2)
+
+		delivery, err := s.deliveries.InsertDelivery(ctx, data.NotificationDelivery{
+			UserId:        recipient.UserId,
+			Event:         event,
+			Channel:       channel,
+			Recipient:     address,
+			Subject:       subject,
+			Body:          body,
+			NextAttemptAt: &next_attempt_at,
+		})
+
+		if err != nil {
+			return err
+		}
+
+		// The attempt outlives the request that caused the notification, so it keeps the
+		// values of its context but not its cancellation.
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.attempt(context.WithoutCancel(ctx), delivery)
+		}()
+	}
+	return nil
+}
+
+// Delay before the next attempt, doubling from a minute up to an hour.
+func retryDelay(attempts int) time.Duration {
+	delay := retryBaseDelay << (attempts - 1)
+
+	if delay > retryMaxDelay || delay <= 0 {
+		return retryMaxDelay
+	}
+	return delay
+}
+
+func (s *Service) attempt(ctx context.Context, delivery *data.NotificationDelivery) {
+	notifier, ok := s.notifiers[delivery.Channel]
+
+	var err error
+
+	if !ok {
+		err = errors.New(fmt.Sprintf("Channel %s is not configured.", delivery.Channel))
+	} else {
+		send_ctx, cancel := context.WithTimeout(ctx, sendTimeout)
+		err = notifier.Send(send_ctx, Message{
+			Event:   delivery.Event,
+			UserId:  delivery.UserId,
+			To:      delivery.Recipient,
+			Subject: delivery.Subject,
+			Body:    delivery.Body,
+		})
+		cancel()
+	}
+
+	if err == nil {
+		err = s.deliveries.RecordAttempt(ctx, delivery.ID, data.DeliverySent, "", nil)
+	} else {
+		attempts := delivery.Attempts + 1
+		slog.WarnContext(ctx, "Notification failed", "delivery_id", delivery.ID, "channel", delivery.Channel, "attempt", attempts, "error", err)
+
+		if attempts >= s.max_attempts {
+			err = s.deliveries.RecordAttempt(ctx, delivery.ID, data.DeliveryFailed, err.Error(), nil)
+		} else {
+			next_attempt_at := time.Now().Add(retryDelay(attempts))
+			err = s.deliveries.RecordAttempt(ctx, delivery.ID, data.DeliveryPending, err.Error(), &next_attempt_at)
+		}
+	}
+
+	if err != nil {
+		slog.ErrorContext(ctx, "Could not record the attempt of notification", "delivery_id", delivery.ID, "error", err)
+	}
+}
+
+// Attempt the pending deliveries whose backoff has elapsed.
+func (s *Service) RetryDue(ctx context.Context) (int, error) {
+	deliveries, err := s.deliveries.ClaimDueDeliveries(ctx, time.Now(), sendTimeout*2, retryBatchSize)
+
+	if err != nil {
+		return 0, err
+	}
+
+	for _, delivery := range deliveries {
+		s.attempt(ctx, delivery)
+	}
+	return len(deliveries), nil
+}
+
+// Wait for the background first attempts to finish.
+func (s *Service) Wait() {
+	s.wg.Wait()
+}
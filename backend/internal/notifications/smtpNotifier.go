@@ -0,0 +1,128 @@
+package notifications
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// Delivers notifications as plain text emails. Without a username no authentication is
+// attempted, which is what local SMTP stand-ins like MailHog expect.
+type SMTPNotifier struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+func (n *SMTPNotifier) Channel() string {
+	return ChannelEmail
+}
+
+func (n *SMTPNotifier) Address(recipient Recipient) string {
+	return recipient.Email
+}
+
+// A line break in a header value would start a header of its own, the addresses with one
+// are refused and the subject is folded onto one line.
+func checkHeaderAddress(address string) error {
+	if strings.ContainsAny(address, "\r\n") {
+		return fmt.Errorf("email address %q has a line break", address)
+	}
+	return nil
+}
+
+func encodeSubject(subject string) string {
+	subject = strings.Join(strings.Fields(subject), " ")
+	return mime.QEncoding.Encode("UTF-8", subject)
+}
+
+func (n *SMTPNotifier) Send(ctx context.Context, message Message) error {
+	for _, address := range []string{n.From, message.To} {
+		if err := checkHeaderAddress(address); err != nil {
+			return err
+		}
+	}
+
+	dialer := net.Dialer{Timeout: 10 * time.Second}
+
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(n.Host, n.Port))
+
+	if err != nil {
+		return err
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, n.Host)
+
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		err = client.StartTLS(&tls.Config{ServerName: n.Host})
+
+		if err != nil {
+			return err
+		}
+	}
+
+	if n.Username != "" {
+		err = client.Auth(smtp.PlainAuth("", n.Username, n.Password, n.Host))
+
+		if err != nil {
+			return err
+		}
+	}
+
+	err = client.Mail(n.From)
+
+	if err != nil {
+		return err
+	}
+
+	err = client.Rcpt(message.To)
+
+	if err != nil {
+		return err
+	}
+
+	writer, err := client.Data()
+
+	if err != nil {
+		return err
+	}
+
+	headers := []string{
+		"From: " + n.From,
+		"To: " + message.To,
+		"Subject: " + encodeSubject(message.Subject),
+		"Date: " + time.Now().Format(time.RFC1123Z),
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=UTF-8",
+	}
+
+	_, err = fmt.Fprintf(writer, "%s\r\n\r\n%s\r\n", strings.Join(headers, "\r\n"), message.Body)
+
+	if err != nil {
+		return err
+	}
+
+	err = writer.Close()
+
+	if err != nil {
+		return err
+	}
+	return client.Quit()
+}
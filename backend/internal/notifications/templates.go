@@ -0,0 +1,45 @@
+package notifications
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// Every event has a template file defining a "subject" and a "body" template.
+//
+//go:embed templates/*.tmpl
+var templateFiles embed.FS
+
+var eventTemplates = map[string]*template.Template{}
+
+func init() {
+	for _, event := range []string{EventLoanDueSoon, EventLoanOverdue, EventHoldReady, EventAccountActivated} {
+		eventTemplates[event] = template.Must(template.ParseFS(templateFiles, "templates/"+event+".tmpl"))
+	}
+}
+
+func render(event string, template_data any) (string, string, error) {
+	event_template, ok := eventTemplates[event]
+
+	if !ok {
+		return "", "", errors.New(fmt.Sprintf("No template for event %s.", event))
+	}
+
+	var subject, body strings.Builder
+
+	err := event_template.ExecuteTemplate(&subject, "subject", template_data)
+
+	if err != nil {
+		return "", "", err
+	}
+
+	err = event_template.ExecuteTemplate(&body, "body", template_data)
+
+	if err != nil {
+		return "", "", err
+	}
+	return strings.TrimSpace(subject.String()), strings.TrimSpace(body.String()), nil
+}
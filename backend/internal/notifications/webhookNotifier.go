@@ -0,0 +1,68 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Posts notifications as JSON to a URL, e.g. an SMS gateway reaching the member's phone.
+type WebhookNotifier struct {
+	URL    string
+	Client *http.Client
+}
+
+func NewWebhookNotifier(url string) *WebhookNotifier {
+	return &WebhookNotifier{
+		URL:    url,
+		Client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (n *WebhookNotifier) Channel() string {
+	return ChannelWebhook
+}
+
+func (n *WebhookNotifier) Address(recipient Recipient) string {
+	if recipient.PhoneNumber != "" {
+		return recipient.PhoneNumber
+	}
+	return strconv.Itoa(recipient.UserId)
+}
+
+func (n *WebhookNotifier) Send(ctx context.Context, message Message) error {
+	payload, err := json.Marshal(map[string]any{
+		"event":   message.Event,
+		"user_id": message.UserId,
+		"to":      message.To,
+		"subject": message.Subject,
+		"body":    message.Body,
+	})
+
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, n.URL, bytes.NewReader(payload))
+
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	response, err := n.Client.Do(request)
+
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status %d", response.StatusCode)
+	}
+	return nil
+}
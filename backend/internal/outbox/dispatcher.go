@@ -0,0 +1,207 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/data"
+)
+
+const (
+	defaultPollInterval = 2 * time.Second
+	publishTimeout      = 30 * time.Second
+	// Kept small, the lease of a batch covers publishing all of it and holds the events of an
+	// instance that died until it runs out.
+	claimBatchSize = 10
+	retryBaseDelay = 5 * time.Second
+	retryMaxDelay  = 30 * time.Minute
+)
+
+type EventStore interface {
+	ClaimOutboxEvents(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*data.OutboxEvent, error)
+	MarkOutboxEventPublished(ctx context.Context, id int64) error
+	RecordOutboxEventFailure(ctx context.Context, id int64, last_error string, next_attempt_at time.Time) error
+}
+
+type SinkConfig struct {
+	// Sinks to publish to: stdout, webhook or nats.
+	Names      []string
+	WebhookURL string
+	NATSURL    string
+	// Prepended to the event type to make the NATS subject.
+	NATSSubjectPrefix string
+}
+
+// Builds the sinks listed in the config.
+func NewSinks(config SinkConfig) ([]Sink, error) {
+	sinks := make([]Sink, 0)
+
+	for _, name := range config.Names {
+		switch strings.TrimSpace(name) {
+		case "":
+		case "stdout":
+			sinks = append(sinks, NewStdoutSink())
+		case "webhook":
+			if config.WebhookURL == "" {
+				return nil, fmt.Errorf("a webhook url is required for the webhook sink")
+			}
+			sinks = append(sinks, NewWebhookSink(config.WebhookURL))
+		case "nats":
+			prefix := config.NATSSubjectPrefix
+
+			if prefix == "" {
+				prefix = "library."
+			}
+
+			sink, err := NewNATSSink(config.NATSURL, prefix)
+
+			if err != nil {
+				return nil, err
+			}
+			sinks = append(sinks, sink)
+		default:
+			return nil, fmt.Errorf("unknown outbox sink %q", name)
+		}
+	}
+	return sinks, nil
+}
+
+// Publishes the outbox events to every sink. An event is marked published only once all
+// the sinks accepted it, otherwise it is retried with backoff and the sinks that already
+// accepted it will see it again.
+type Dispatcher struct {
+	store    EventStore
+	sinks    []Sink
+	interval time.Duration
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+func NewDispatcher(store EventStore, sinks []Sink) *Dispatcher {
+	return &Dispatcher{
+		store:    store,
+		sinks:    sinks,
+		interval: defaultPollInterval,
+	}
+}
+
+func (d *Dispatcher) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	d.cancel = cancel
+
+	d.wg.Add(1)
+	go d.loop(ctx)
+	slog.Info("Outbox dispatcher started", "sinks", len(d.sinks))
+}
+
+// Stop polling and wait for the events being published.
+func (d *Dispatcher) Stop() {
+	if d.cancel != nil {
+		d.cancel()
+	}
+	d.wg.Wait()
+
+	for _, sink := range d.sinks {
+		if closer, ok := sink.(interface{ Close() error }); ok {
+			closer.Close()
+		}
+	}
+}
+
+func (d *Dispatcher) loop(ctx context.Context) {
+	defer d.wg.Done()
+
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		// Keep going while there is a backlog.
+		for {
+			published, err := d.DispatchOnce(ctx)
+
+			if err != nil {
+				slog.Error("Outbox dispatch failed", "error", err)
+			}
+
+			if err != nil || published < claimBatchSize || ctx.Err() != nil {
+				break
+			}
+		}
+	}
+}
+
+func retryDelay(attempts int) time.Duration {
+	delay := retryBaseDelay << (attempts - 1)
+
+	if delay > retryMaxDelay || delay <= 0 {
+		return retryMaxDelay
+	}
+	return delay
+}
+
+// Publish a batch of due events, returning how many were claimed.
+func (d *Dispatcher) DispatchOnce(ctx context.Context) (int, error) {
+	if len(d.sinks) == 0 {
+		return 0, nil
+	}
+
+	// The events are published one after the other, each to every sink, so the lease has to
+	// last for the whole batch or another instance claims the events still to be published.
+	lease := publishTimeout * time.Duration(claimBatchSize*len(d.sinks)+1)
+
+	events, err := d.store.ClaimOutboxEvents(ctx, time.Now(), lease, claimBatchSize)
+
+	if err != nil {
+		return 0, err
+	}
+
+	// An event the store failed to update is published again once its lease runs out, the
+	// rest of the batch is still published.
+	store_errors := make([]error, 0)
+
+	for _, event := range events {
+		publish_err := d.publish(ctx, event)
+
+		if publish_err == nil {
+			err = d.store.MarkOutboxEventPublished(ctx, event.ID)
+		} else {
+			slog.Warn("Outbox event failed", "event_id", event.ID, "event_type", event.EventType, "error", publish_err)
+			err = d.store.RecordOutboxEventFailure(ctx, event.ID, publish_err.Error(), time.Now().Add(retryDelay(event.Attempts+1)))
+		}
+
+		if err != nil {
+			store_errors = append(store_errors, fmt.Errorf("event %d: %w", event.ID, err))
+		}
+	}
+	return len(events), errors.Join(store_errors...)
+}
+
+func (d *Dispatcher) publish(ctx context.Context, event *data.OutboxEvent) error {
+	failures := make([]string, 0)
+
+	for _, sink := range d.sinks {
+		publish_ctx, cancel := context.WithTimeout(ctx, publishTimeout)
+		err := sink.Publish(publish_ctx, event)
+		cancel()
+
+		if err != nil {
+			failures = append(failures, sink.Name()+": "+err.Error())
+		}
+	}
+
+	if len(failures) > 0 {
+		return fmt.Errorf("%s", strings.Join(failures, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,160 @@
+package outbox
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/data"
+)
+
+// Publishes events to a NATS compatible server over its text protocol, on the subject
+// <prefix><event type>, e.g. library.loan.created. Every publish is followed by a PING so
+// that the PONG confirms the server has processed it.
+type NATSSink struct {
+	address        string
+	subject_prefix string
+
+	mu     sync.Mutex
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+func NewNATSSink(server_url, subject_prefix string) (*NATSSink, error) {
+	parsed_url, err := url.Parse(server_url)
+
+	if err != nil || parsed_url.Host == "" {
+		return nil, fmt.Errorf("invalid NATS url %q", server_url)
+	}
+
+	address := parsed_url.Host
+
+	if parsed_url.Port() == "" {
+		address = net.JoinHostPort(parsed_url.Hostname(), "4222")
+	}
+
+	return &NATSSink{address: address, subject_prefix: subject_prefix}, nil
+}
+
+func (s *NATSSink) Name() string {
+	return "nats"
+}
+
+func (s *NATSSink) connect(ctx context.Context) error {
+	dialer := net.Dialer{Timeout: 5 * time.Second}
+
+	conn, err := dialer.DialContext(ctx, "tcp", s.address)
+
+	if err != nil {
+		return err
+	}
+
+	reader := bufio.NewReader(conn)
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	// The server greets with INFO before anything else.
+	line, err := reader.ReadString('\n')
+
+	if err != nil {
+		conn.Close()
+		return err
+	}
+
+	if !strings.HasPrefix(line, "INFO") {
+		conn.Close()
+		return errors.New(fmt.Sprintf("Unexpected greeting from NATS: %q", strings.TrimSpace(line)))
+	}
+
+	_, err = conn.Write([]byte("CONNECT {\"verbose\":false,\"pedantic\":false,\"name\":\"library-outbox\"}\r\n"))
+
+	if err != nil {
+		conn.Close()
+		return err
+	}
+
+	s.conn, s.reader = conn, reader
+	return nil
+}
+
+func (s *NATSSink) Publish(ctx context.Context, event *data.OutboxEvent) error {
+	payload, err := MarshalEvent(event)
+
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conn == nil {
+		err = s.connect(ctx)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	err = s.publish(ctx, s.subject_prefix+event.EventType, payload)
+
+	if err != nil {
+		// Start over with a fresh connection on the next publish.
+		s.conn.Close()
+		s.conn, s.reader = nil, nil
+	}
+	return err
+}
+
+func (s *NATSSink) publish(ctx context.Context, subject string, payload []byte) error {
+	deadline, ok := ctx.Deadline()
+
+	if !ok {
+		deadline = time.Now().Add(10 * time.Second)
+	}
+	s.conn.SetDeadline(deadline)
+
+	_, err := fmt.Fprintf(s.conn, "PUB %s %d\r\n%s\r\nPING\r\n", subject, len(payload), payload)
+
+	if err != nil {
+		return err
+	}
+
+	for {
+		line, err := s.reader.ReadString('\n')
+
+		if err != nil {
+			return err
+		}
+
+		switch line = strings.TrimSpace(line); {
+		case line == "PONG":
+			return nil
+		case line == "PING":
+			_, err = s.conn.Write([]byte("PONG\r\n"))
+
+			if err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, "-ERR"):
+			return errors.New(fmt.Sprintf("NATS error: %s", line))
+		}
+	}
+}
+
+func (s *NATSSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conn == nil {
+		return nil
+	}
+
+	err := s.conn.Close()
+	s.conn, s.reader = nil, nil
+	return err
+}
@@ -0,0 +1,38 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/data"
+)
+
+// Destination the outbox events are published to. Delivery is at least once, so a sink
+// may see an event again and consumers should deduplicate on the event id.
+type Sink interface {
+	Name() string
+	Publish(ctx context.Context, event *data.OutboxEvent) error
+}
+
+// What the sinks publish, leaving out the outbox bookkeeping.
+type envelope struct {
+	ID            int64           `json:"id"`
+	EventType     string          `json:"event_type"`
+	AggregateType string          `json:"aggregate_type"`
+	AggregateId   int             `json:"aggregate_id"`
+	OccurredAt    time.Time       `json:"occurred_at"`
+	Payload       json.RawMessage `json:"payload"`
+}
+
+// Encode an event the way the sinks publish it.
+func MarshalEvent(event *data.OutboxEvent) ([]byte, error) {
+	return json.Marshal(envelope{
+		ID:            event.ID,
+		EventType:     event.EventType,
+		AggregateType: event.AggregateType,
+		AggregateId:   event.AggregateId,
+		OccurredAt:    event.CreatedAt,
+		Payload:       event.Payload,
+	})
+}
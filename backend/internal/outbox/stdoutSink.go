@@ -0,0 +1,38 @@
+package outbox
+
+import (
+	"context"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/data"
+)
+
+// Writes the events as JSON lines, handy for local development.
+type StdoutSink struct {
+	mu     sync.Mutex
+	writer io.Writer
+}
+
+func NewStdoutSink() *StdoutSink {
+	return &StdoutSink{writer: os.Stdout}
+}
+
+func (s *StdoutSink) Name() string {
+	return "stdout"
+}
+
+func (s *StdoutSink) Publish(ctx context.Context, event *data.OutboxEvent) error {
+	line, err := MarshalEvent(event)
+
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, err = s.writer.Write(append(line, '\n'))
+	return err
+}
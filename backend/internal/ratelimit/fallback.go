@@ -0,0 +1,55 @@
+package ratelimit
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// Counts in the process while the store it falls back from fails, so the limits still hold
+// per instance when the shared store is down.
+type FallbackStore struct {
+	store    Store
+	fallback *MemoryStore
+}
+
+func NewFallbackStore(store Store) *FallbackStore {
+	return &FallbackStore{store: store, fallback: NewMemoryStore()}
+}
+
+func (s *FallbackStore) Hit(ctx context.Context, key string, window time.Duration) (int, time.Duration, error) {
+	count, retry_after, err := s.store.Hit(ctx, key, window)
+
+	if err != nil {
+		slog.WarnContext(ctx, "The rate limit store failed, counting in the process", "key", key, "error", err)
+		return s.fallback.Hit(ctx, key, window)
+	}
+	return count, retry_after, nil
+}
+
+func (s *FallbackStore) TTL(ctx context.Context, key string) (time.Duration, error) {
+	ttl, err := s.store.TTL(ctx, key)
+
+	if err != nil {
+		slog.WarnContext(ctx, "The rate limit store failed, counting in the process", "key", key, "error", err)
+		return s.fallback.TTL(ctx, key)
+	}
+	return ttl, nil
+}
+
+func (s *FallbackStore) Delete(ctx context.Context, keys ...string) error {
+	s.fallback.Delete(ctx, keys...)
+	return s.store.Delete(ctx, keys...)
+}
+
+// Check the store answers, when it can tell.
+func (s *FallbackStore) Ping(ctx context.Context) error {
+	if pinger, ok := s.store.(interface{ Ping(context.Context) error }); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+func (s *FallbackStore) Close() error {
+	return s.store.Close()
+}
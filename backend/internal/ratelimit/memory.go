@@ -0,0 +1,89 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Sweeps of the expired windows start once the store holds this many.
+const minSweepSize = 1024
+
+type window struct {
+	count   int
+	expires time.Time
+}
+
+// Counts kept in the process. Every instance of the server counts on its own, so the limits
+// apply per instance.
+type MemoryStore struct {
+	mu        sync.Mutex
+	windows   map[string]*window
+	nextSweep int
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{windows: make(map[string]*window), nextSweep: minSweepSize}
+}
+
+func (s *MemoryStore) Hit(ctx context.Context, key string, length time.Duration) (int, time.Duration, error) {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current, ok := s.windows[key]
+
+	if !ok || !now.Before(current.expires) {
+		s.sweep(now)
+
+		current = &window{expires: now.Add(length)}
+		s.windows[key] = current
+	}
+	current.count++
+
+	return current.count, current.expires.Sub(now), nil
+}
+
+func (s *MemoryStore) TTL(ctx context.Context, key string) (time.Duration, error) {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current, ok := s.windows[key]
+
+	if !ok || !now.Before(current.expires) {
+		return 0, nil
+	}
+	return current.expires.Sub(now), nil
+}
+
+func (s *MemoryStore) Delete(ctx context.Context, keys ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, key := range keys {
+		delete(s.windows, key)
+	}
+	return nil
+}
+
+func (s *MemoryStore) Close() error {
+	return nil
+}
+
+// Drop the expired windows once the store has doubled since the last sweep, so the sweeps
+// cost a constant time per hit.
+func (s *MemoryStore) sweep(now time.Time) {
+	if len(s.windows) < s.nextSweep {
+		return
+	}
+
+	for key, current := range s.windows {
+		if !now.Before(current.expires) {
+			delete(s.windows, key)
+		}
+	}
+	s.nextSweep = max(2*len(s.windows), minSweepSize)
+}
@@ -0,0 +1,161 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// Move the window of the key past its end, as if its length had passed.
+func expire(s *MemoryStore, key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.windows[key].expires = time.Now().Add(-time.Millisecond)
+}
+
+func TestMemoryStoreHit(t *testing.T) {
+	tests := []struct {
+		name string
+		// Hits before the checked one, and whether the window ends before it.
+		hits      int
+		expired   bool
+		wantCount int
+	}{
+		{"counts the first hit", 0, false, 1},
+		{"counts hits in the window", 4, false, 5},
+		{"starts over once the window ends", 4, true, 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			store := NewMemoryStore()
+
+			for range test.hits {
+				_, _, err := store.Hit(ctx, "key", time.Minute)
+
+				if err != nil {
+					t.Fatalf("Hit() error = %v", err)
+				}
+			}
+
+			if test.expired {
+				expire(store, "key")
+			}
+
+			count, retry_after, err := store.Hit(ctx, "key", time.Minute)
+
+			if err != nil {
+				t.Fatalf("Hit() error = %v", err)
+			}
+
+			if count != test.wantCount {
+				t.Errorf("Hit() count = %d, want %d", count, test.wantCount)
+			}
+
+			if retry_after <= 0 || retry_after > time.Minute {
+				t.Errorf("Hit() time left = %v, want within the window", retry_after)
+			}
+		})
+	}
+}
+
+func TestMemoryStoreWindowsByKey(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+
+	store.Hit(ctx, "a", time.Minute)
+	store.Hit(ctx, "a", time.Minute)
+
+	count, _, _ := store.Hit(ctx, "b", time.Minute)
+
+	if count != 1 {
+		t.Errorf("Hit() count of another key = %d, want 1", count)
+	}
+}
+
+func TestMemoryStoreKeepsTheFirstWindow(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+
+	store.Hit(ctx, "key", time.Minute)
+
+	// A longer window on a later hit does not extend the running one.
+	_, retry_after, _ := store.Hit(ctx, "key", time.Hour)
+
+	if retry_after > time.Minute {
+		t.Errorf("Hit() time left = %v, want the first window of a minute", retry_after)
+	}
+}
+
+func TestMemoryStoreTTL(t *testing.T) {
+	tests := []struct {
+		name    string
+		hit     bool
+		expired bool
+		deleted bool
+		want    bool
+	}{
+		{"none for a missing key", false, false, false, false},
+		{"time left of a running window", true, false, false, true},
+		{"none once the window ends", true, true, false, false},
+		{"none once deleted", true, false, true, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			store := NewMemoryStore()
+
+			if test.hit {
+				store.Hit(ctx, "key", time.Minute)
+			}
+
+			if test.expired {
+				expire(store, "key")
+			}
+
+			if test.deleted {
+				err := store.Delete(ctx, "key", "missing")
+
+				if err != nil {
+					t.Fatalf("Delete() error = %v", err)
+				}
+			}
+
+			ttl, err := store.TTL(ctx, "key")
+
+			if err != nil {
+				t.Fatalf("TTL() error = %v", err)
+			}
+
+			if (ttl > 0) != test.want || ttl > time.Minute {
+				t.Errorf("TTL() = %v, want time left %v", ttl, test.want)
+			}
+		})
+	}
+}
+
+func TestMemoryStoreSweep(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+
+	for i := range minSweepSize {
+		store.Hit(ctx, fmt.Sprintf("expired:%d", i), time.Minute)
+		expire(store, fmt.Sprintf("expired:%d", i))
+	}
+	store.Hit(ctx, "running", time.Minute)
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	if len(store.windows) != 1 {
+		t.Errorf("store holds %d windows after the sweep, want the running one", len(store.windows))
+	}
+
+	if store.nextSweep != minSweepSize {
+		t.Errorf("next sweep at %d windows, want %d", store.nextSweep, minSweepSize)
+	}
+}
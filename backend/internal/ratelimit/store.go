@@ -0,0 +1,61 @@
+// Package ratelimit counts hits by key in fixed windows, in the process or in a Redis
+// compatible server so the instances of the server share the counts.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const (
+	StoreMemory = "memory"
+	StoreRedis  = "redis"
+)
+
+// What happens to the hits when the store fails.
+const (
+	// Let them through unlimited.
+	OnErrorAllow = "allow"
+	// Reject them.
+	OnErrorDeny = "deny"
+	// Count them in the process until the store is back.
+	OnErrorMemory = "memory"
+)
+
+type Store interface {
+	// Count a hit on the key. The count starts over once window has passed since the first
+	// hit. Returns the hits so far and the time left of the window.
+	Hit(ctx context.Context, key string, window time.Duration) (int, time.Duration, error)
+	// Time left of the key's window, 0 when none is running.
+	TTL(ctx context.Context, key string) (time.Duration, error)
+	Delete(ctx context.Context, keys ...string) error
+	Close() error
+}
+
+type Settings struct {
+	// memory or redis.
+	Store string
+	// host:port of the Redis compatible server.
+	RedisAddr     string
+	RedisPassword string
+	RedisDB       int
+	// allow, deny or memory, for the redis store.
+	OnError string
+}
+
+func New(settings Settings) (Store, error) {
+	switch settings.Store {
+	case StoreMemory, "":
+		return NewMemoryStore(), nil
+	case StoreRedis:
+		store := NewRedisStore(settings.RedisAddr, settings.RedisPassword, settings.RedisDB)
+
+		if settings.OnError == OnErrorMemory {
+			return NewFallbackStore(store), nil
+		}
+		return store, nil
+	default:
+		return nil, fmt.Errorf("unknown rate limit store %q", settings.Store)
+	}
+}
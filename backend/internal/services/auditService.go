@@ -0,0 +1,131 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/data"
+)
+
+const redactedValue = "[redacted]"
+
+// Keys whose values never make it into the audit log.
+var redactedKeys = []string{"password", "secret", "token"}
+
+func isRedactedKey(key string) bool {
+	key = strings.ToLower(key)
+
+	for _, redacted := range redactedKeys {
+		if strings.Contains(key, redacted) {
+			return true
+		}
+	}
+	return false
+}
+
+func redact(value any) any {
+	switch typed := value.(type) {
+	case map[string]any:
+		for key, field := range typed {
+			if isRedactedKey(key) {
+				typed[key] = redactedValue
+			} else {
+				typed[key] = redact(field)
+			}
+		}
+	case []any:
+		for i, item := range typed {
+			typed[i] = redact(item)
+		}
+	}
+	return value
+}
+
+func decodeState(state json.RawMessage) (any, error) {
+	if len(state) == 0 {
+		return nil, nil
+	}
+
+	var decoded any
+
+	err := json.Unmarshal(state, &decoded)
+
+	if err != nil {
+		return nil, err
+	}
+	return redact(decoded), nil
+}
+
+// Field by field changes between two states as {"field": {"from": ..., "to": ...}}. A creation
+// or deletion lists every field, states that are not objects are compared as a whole under "value".
+func diffStates(before, after any) map[string]any {
+	before_fields, before_is_object := before.(map[string]any)
+	after_fields, after_is_object := after.(map[string]any)
+
+	if (before != nil && !before_is_object) || (after != nil && !after_is_object) {
+		if reflect.DeepEqual(before, after) {
+			return map[string]any{}
+		}
+		return map[string]any{"value": map[string]any{"from": before, "to": after}}
+	}
+
+	diff := make(map[string]any)
+
+	for key, from := range before_fields {
+		to, ok := after_fields[key]
+
+		if !ok || !reflect.DeepEqual(from, to) {
+			diff[key] = map[string]any{"from": from, "to": to}
+		}
+	}
+
+	for key, to := range after_fields {
+		if _, ok := before_fields[key]; !ok {
+			diff[key] = map[string]any{"from": nil, "to": to}
+		}
+	}
+	return diff
+}
+
+func (l *LibraryService) RecordAudit(ctx context.Context, entry data.AuditEntry) error {
+	ctx, span := startSpan(ctx, "RecordAudit")
+	defer span.End()
+
+	before, err := decodeState(entry.Before)
+
+	if err != nil {
+		return err
+	}
+
+	after, err := decodeState(entry.After)
+
+	if err != nil {
+		return err
+	}
+
+	if before != nil {
+		entry.Before, _ = json.Marshal(before)
+	}
+
+	if after != nil {
+		entry.After, _ = json.Marshal(after)
+	}
+
+	if before != nil || after != nil {
+		entry.Diff, err = json.Marshal(diffStates(before, after))
+
+		if err != nil {
+			return err
+		}
+	}
+	return l.model.AuditEntry.InsertEntry(ctx, entry)
+}
+
+func (l *LibraryService) GetAuditLog(ctx context.Context, filter data.AuditFilter) ([]*data.AuditEntry, error) {
+	ctx, span := startSpan(ctx, "GetAuditLog")
+	defer span.End()
+
+	return l.model.AuditEntry.QueryEntries(ctx, filter)
+}
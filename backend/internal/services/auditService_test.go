@@ -0,0 +1,176 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/data"
+)
+
+// The JSON decoded the way the audit states are.
+func decodeJSON(t *testing.T, value string) any {
+	t.Helper()
+
+	if value == "" {
+		return nil
+	}
+
+	var decoded any
+
+	err := json.Unmarshal([]byte(value), &decoded)
+
+	if err != nil {
+		t.Fatalf("invalid JSON %q: %v", value, err)
+	}
+	return decoded
+}
+
+func TestIsRedactedKey(t *testing.T) {
+	tests := []struct {
+		key  string
+		want bool
+	}{
+		{"password", true},
+		{"Password", true},
+		{"new_password", true},
+		{"secret", true},
+		{"WebhookSecret", true},
+		{"token", true},
+		{"refresh_token", true},
+		{"TOKEN_ID", true},
+		{"email", false},
+		{"name", false},
+		{"pass", false},
+		{"", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.key, func(t *testing.T) {
+			if got := isRedactedKey(test.key); got != test.want {
+				t.Errorf("isRedactedKey(%q) = %v, want %v", test.key, got, test.want)
+			}
+		})
+	}
+}
+
+func TestRedact(t *testing.T) {
+	tests := []struct {
+		name  string
+		state string
+		want  string
+	}{
+		{"keeps plain fields", `{"id": 1, "name": "Book"}`, `{"id": 1, "name": "Book"}`},
+		{"redacts a secret field", `{"id": 1, "secret": "s3cr3t"}`, `{"id": 1, "secret": "[redacted]"}`},
+		{"redacts whatever the value", `{"password": {"hash": "x"}, "token": null}`, `{"password": "[redacted]", "token": "[redacted]"}`},
+		{"redacts nested objects", `{"user": {"name": "a", "Password": "p"}}`, `{"user": {"name": "a", "Password": "[redacted]"}}`},
+		{"redacts objects in lists", `{"items": [{"api_token": "t"}, 1, "token"]}`, `{"items": [{"api_token": "[redacted]"}, 1, "token"]}`},
+		{"redacts lists of objects", `[{"secret": "s"}, {"id": 2}]`, `[{"secret": "[redacted]"}, {"id": 2}]`},
+		{"keeps scalars", `"password"`, `"password"`},
+		{"keeps no state", ``, ``},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := decodeState(json.RawMessage(test.state))
+
+			if err != nil {
+				t.Fatalf("decodeState() error = %v", err)
+			}
+
+			if want := decodeJSON(t, test.want); !reflect.DeepEqual(got, want) {
+				t.Errorf("decodeState(%s) = %v, want %v", test.state, got, want)
+			}
+		})
+	}
+}
+
+func TestDecodeStateRejectsInvalidJSON(t *testing.T) {
+	_, err := decodeState(json.RawMessage(`{"id": `))
+
+	if err == nil {
+		t.Error("decodeState() of invalid JSON succeeded")
+	}
+}
+
+func TestDiffStates(t *testing.T) {
+	tests := []struct {
+		name   string
+		before string
+		after  string
+		want   string
+	}{
+		{"no change", `{"id": 1, "name": "a"}`, `{"id": 1, "name": "a"}`, `{}`},
+		{"changed field", `{"id": 1, "name": "a"}`, `{"id": 1, "name": "b"}`, `{"name": {"from": "a", "to": "b"}}`},
+		{"added field", `{"id": 1}`, `{"id": 1, "name": "b"}`, `{"name": {"from": null, "to": "b"}}`},
+		{"removed field", `{"id": 1, "name": "a"}`, `{"id": 1}`, `{"name": {"from": "a", "to": null}}`},
+		{"nested change", `{"tags": ["a"], "meta": {"x": 1}}`, `{"tags": ["a"], "meta": {"x": 2}}`, `{"meta": {"from": {"x": 1}, "to": {"x": 2}}}`},
+		{"creation", ``, `{"id": 1, "name": "b"}`, `{"id": {"from": null, "to": 1}, "name": {"from": null, "to": "b"}}`},
+		{"deletion", `{"id": 1}`, ``, `{"id": {"from": 1, "to": null}}`},
+		{"same scalars", `5`, `5`, `{}`},
+		{"changed scalars", `5`, `6`, `{"value": {"from": 5, "to": 6}}`},
+		{"object to list", `{"id": 1}`, `[1]`, `{"value": {"from": {"id": 1}, "to": [1]}}`},
+		{"no states", ``, ``, `{}`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := diffStates(decodeJSON(t, test.before), decodeJSON(t, test.after))
+
+			// Compared as JSON, the way the diff is stored.
+			encoded, err := json.Marshal(got)
+
+			if err != nil {
+				t.Fatalf("could not encode the diff: %v", err)
+			}
+
+			if got, want := decodeJSON(t, string(encoded)), decodeJSON(t, test.want); !reflect.DeepEqual(got, want) {
+				t.Errorf("diffStates(%s, %s) = %s, want %s", test.before, test.after, encoded, test.want)
+			}
+		})
+	}
+}
+
+func TestRecordAuditRedactsStates(t *testing.T) {
+	ctx := context.Background()
+	service, model := newTestService(t)
+
+	err := service.RecordAudit(ctx, data.AuditEntry{
+		Action:     "PUT /api/admin/webhooks/:webhook_id",
+		EntityType: "webhook_subscription",
+		EntityId:   "1",
+		Before:     json.RawMessage(`{"url": "https://a.example", "secret": "old"}`),
+		After:      json.RawMessage(`{"url": "https://b.example", "secret": "new"}`),
+	})
+
+	if err != nil {
+		t.Fatalf("RecordAudit() error = %v", err)
+	}
+
+	entries, err := model.AuditEntry.QueryEntries(ctx, data.AuditFilter{Limit: 10})
+
+	if err != nil {
+		t.Fatalf("QueryEntries() error = %v", err)
+	}
+
+	if len(entries) != 1 {
+		t.Fatalf("audit log has %d entries, want 1", len(entries))
+	}
+
+	tests := []struct {
+		name  string
+		state json.RawMessage
+		want  string
+	}{
+		{"before", entries[0].Before, `{"url": "https://a.example", "secret": "[redacted]"}`},
+		{"after", entries[0].After, `{"url": "https://b.example", "secret": "[redacted]"}`},
+		{"diff", entries[0].Diff, `{"url": {"from": "https://a.example", "to": "https://b.example"}}`},
+	}
+
+	for _, test := range tests {
+		if got, want := decodeJSON(t, string(test.state)), decodeJSON(t, test.want); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s = %s, want %s", test.name, test.state, test.want)
+		}
+	}
+}
@@ -0,0 +1,193 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/data"
+)
+
+// Upper bound on the days walked looking for open days, so a calendar with every day
+// closed fails instead of looping forever.
+const maxCalendarWalkDays = 10 * 366
+
+type libraryCalendar struct {
+	closed_weekdays map[time.Weekday]bool
+	closures        map[string]bool
+	recurring       map[string]bool
+}
+
+func newLibraryCalendar(hours []*data.LibraryHours, closures []*data.LibraryClosure) *libraryCalendar {
+	calendar := libraryCalendar{
+		closed_weekdays: make(map[time.Weekday]bool),
+		closures:        make(map[string]bool),
+		recurring:       make(map[string]bool),
+	}
+
+	for _, day := range hours {
+		if day.IsClosed {
+			calendar.closed_weekdays[time.Weekday(day.Weekday)] = true
+		}
+	}
+
+	for _, closure := range closures {
+		if closure.Recurring {
+			calendar.recurring[closure.ClosureDate.Format("01-02")] = true
+		} else {
+			calendar.closures[closure.ClosureDate.Format(time.DateOnly)] = true
+		}
+	}
+	return &calendar
+}
+
+func (c *libraryCalendar) isOpen(day time.Time) bool {
+	return !c.closed_weekdays[day.Weekday()] &&
+		!c.closures[day.Format(time.DateOnly)] &&
+		!c.recurring[day.Format("01-02")]
+}
+
+// Move forward by the given number of open days, closed days are not counted.
+func (c *libraryCalendar) addOpenDays(from time.Time, days int) (time.Time, error) {
+	day := from
+
+	for walked := 0; days > 0; walked++ {
+		if walked > maxCalendarWalkDays {
+			return time.Time{}, data.Conflict("no_open_days", "The library calendar has no open days.")
+		}
+
+		day = day.AddDate(0, 0, 1)
+
+		if c.isOpen(day) {
+			days--
+		}
+	}
+	return day, nil
+}
+
+// Open days after the date of from up to and including the date of to.
+func (c *libraryCalendar) openDaysBetween(from, to time.Time) int {
+	open_days := 0
+	from_date := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, from.Location())
+	to_date := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, from.Location())
+
+	for day := from_date.AddDate(0, 0, 1); !day.After(to_date); day = day.AddDate(0, 0, 1) {
+		if c.isOpen(day) {
+			open_days++
+		}
+	}
+	return open_days
+}
+
+func (l *LibraryService) loadCalendar(ctx context.Context) (*libraryCalendar, error) {
+	hours, err := l.model.LibraryHours.GetHours(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	closures, err := l.model.LibraryClosure.GetClosures(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+	return newLibraryCalendar(hours, closures), nil
+}
+
+func (l *LibraryService) GetLibraryHours(ctx context.Context) ([]*data.LibraryHours, error) {
+	ctx, span := startSpan(ctx, "GetLibraryHours")
+	defer span.End()
+
+	return l.model.LibraryHours.GetHours(ctx)
+}
+
+func (l *LibraryService) GetLibraryDay(ctx context.Context, weekday int) (*data.LibraryHours, error) {
+	ctx, span := startSpan(ctx, "GetLibraryDay")
+	defer span.End()
+
+	hours, err := l.model.LibraryHours.GetHours(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, day := range hours {
+		if day.Weekday == weekday {
+			return day, nil
+		}
+	}
+	return nil, data.NotFound("weekday_not_found", "Weekday %d does not exist.", weekday)
+}
+
+func (l *LibraryService) UpdateLibraryHours(ctx context.Context, day data.LibraryHours) (*data.LibraryHours, error) {
+	ctx, span := startSpan(ctx, "UpdateLibraryHours")
+	defer span.End()
+
+	if day.Weekday < 0 || day.Weekday > 6 {
+		return nil, data.Invalid("invalid_weekday", "weekday must be between 0 (Sunday) and 6 (Saturday).")
+	}
+
+	if day.IsClosed {
+		day.OpensAt, day.ClosesAt = nil, nil
+		return l.model.LibraryHours.UpdateHours(ctx, day)
+	}
+
+	if day.OpensAt == nil || day.ClosesAt == nil {
+		return nil, data.Invalid("invalid_opening_hours", "opens_at and closes_at are mandatory for an open day.")
+	}
+
+	opens_at, err := time.Parse("15:04", *day.OpensAt)
+
+	if err != nil {
+		return nil, data.Invalid("invalid_opening_hours", "Invalid opens_at %q, expected HH:MM.", *day.OpensAt)
+	}
+
+	closes_at, err := time.Parse("15:04", *day.ClosesAt)
+
+	if err != nil {
+		return nil, data.Invalid("invalid_opening_hours", "Invalid closes_at %q, expected HH:MM.", *day.ClosesAt)
+	}
+
+	if !opens_at.Before(closes_at) {
+		return nil, data.Invalid("invalid_opening_hours", "opens_at must be before closes_at.")
+	}
+	return l.model.LibraryHours.UpdateHours(ctx, day)
+}
+
+func (l *LibraryService) GetLibraryClosures(ctx context.Context) ([]*data.LibraryClosure, error) {
+	ctx, span := startSpan(ctx, "GetLibraryClosures")
+	defer span.End()
+
+	return l.model.LibraryClosure.GetClosures(ctx)
+}
+
+func (l *LibraryService) GetLibraryClosure(ctx context.Context, id int) (*data.LibraryClosure, error) {
+	ctx, span := startSpan(ctx, "GetLibraryClosure")
+	defer span.End()
+
+	return l.model.LibraryClosure.GetClosureWithId(ctx, id)
+}
+
+func (l *LibraryService) InsertLibraryClosure(ctx context.Context, closure_date string, recurring bool, description string) (*data.LibraryClosure, error) {
+	ctx, span := startSpan(ctx, "InsertLibraryClosure")
+	defer span.End()
+
+	date, err := time.Parse(time.DateOnly, closure_date)
+
+	if err != nil {
+		return nil, data.Invalid("invalid_date", "Invalid date %q, expected YYYY-MM-DD.", closure_date)
+	}
+
+	closure := data.LibraryClosure{
+		ClosureDate: date,
+		Recurring:   recurring,
+		Description: description,
+	}
+	return l.model.LibraryClosure.InsertClosure(ctx, closure)
+}
+
+func (l *LibraryService) DeleteLibraryClosure(ctx context.Context, id int) error {
+	ctx, span := startSpan(ctx, "DeleteLibraryClosure")
+	defer span.End()
+
+	return l.model.LibraryClosure.DeleteClosure(ctx, id)
+}
@@ -0,0 +1,94 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/data"
+)
+
+// Closed on Sundays, on 2025-03-05 and every 25 December.
+func testCalendar() *libraryCalendar {
+	hours := []*data.LibraryHours{
+		{Weekday: int(time.Sunday), IsClosed: true},
+		{Weekday: int(time.Monday)},
+	}
+	closures := []*data.LibraryClosure{
+		{ClosureDate: date(2025, time.March, 5)},
+		{ClosureDate: date(2020, time.December, 25), Recurring: true},
+	}
+	return newLibraryCalendar(hours, closures)
+}
+
+func TestAddOpenDays(t *testing.T) {
+	tests := []struct {
+		name string
+		// 2025-03-03 is a Monday.
+		from time.Time
+		days int
+		want time.Time
+	}{
+		{"adds no days", date(2025, time.March, 3), 0, date(2025, time.March, 3)},
+		{"adds open days", date(2025, time.March, 6), 2, date(2025, time.March, 8)},
+		{"skips a closure", date(2025, time.March, 3), 2, date(2025, time.March, 6)},
+		{"skips a closed weekday", date(2025, time.March, 7), 2, date(2025, time.March, 10)},
+		{"skips a recurring closure", date(2026, time.December, 24), 1, date(2026, time.December, 26)},
+		{"starts after a closed day", date(2025, time.March, 9), 1, date(2025, time.March, 10)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := testCalendar().addOpenDays(test.from, test.days)
+
+			if err != nil {
+				t.Fatalf("addOpenDays() error = %v", err)
+			}
+
+			if !got.Equal(test.want) {
+				t.Errorf("addOpenDays(%v, %d) = %v, want %v", test.from, test.days, got, test.want)
+			}
+		})
+	}
+}
+
+func TestAddOpenDaysWithoutOpenDays(t *testing.T) {
+	hours := make([]*data.LibraryHours, 0, 7)
+
+	for weekday := time.Sunday; weekday <= time.Saturday; weekday++ {
+		hours = append(hours, &data.LibraryHours{Weekday: int(weekday), IsClosed: true})
+	}
+
+	_, err := newLibraryCalendar(hours, nil).addOpenDays(date(2025, time.March, 3), 1)
+
+	if errorCode(err) != "no_open_days" {
+		t.Errorf("addOpenDays() error = %v, want code no_open_days", err)
+	}
+}
+
+func TestOpenDaysBetween(t *testing.T) {
+	tests := []struct {
+		name string
+		from time.Time
+		to   time.Time
+		want int
+	}{
+		{"counts none on the same day", date(2025, time.March, 3), date(2025, time.March, 3).Add(5 * time.Hour), 0},
+		{"counts none backwards", date(2025, time.March, 4), date(2025, time.March, 3), 0},
+		{"counts the day after", date(2025, time.March, 3), date(2025, time.March, 4), 1},
+		{"counts by date not by hours", date(2025, time.March, 3).Add(12 * time.Hour), date(2025, time.March, 4).Add(-10 * time.Hour), 1},
+		{"skips a closure", date(2025, time.March, 4), date(2025, time.March, 6), 1},
+		{"skips a closed weekday", date(2025, time.March, 7), date(2025, time.March, 10), 2},
+		{"skips a recurring closure", date(2026, time.December, 24), date(2026, time.December, 26), 1},
+		{"counts weeks", date(2025, time.March, 9), date(2025, time.March, 23), 12},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := testCalendar().openDaysBetween(test.from, test.to)
+
+			if got != test.want {
+				t.Errorf("openDaysBetween(%v, %v) = %d, want %d", test.from, test.to, got, test.want)
+			}
+		})
+	}
+}
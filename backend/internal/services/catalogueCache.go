@@ -0,0 +1,149 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/cache"
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/data"
+)
+
+const (
+	catalogueKeyPrefix = "catalogue:"
+	// Generation of the cached searches, changed to drop them all at once.
+	searchGenerationKey = catalogueKeyPrefix + "books:generation"
+)
+
+// Change to the catalogue, published once it is committed.
+type catalogueEvent struct {
+	BookIds   []int
+	AuthorIds []int
+	// The change can show in the book searches.
+	Searches bool
+}
+
+// Cache of the catalogue reads. The entries are dropped on the catalogue events and expire
+// after ttl, which bounds how stale a read racing a change can be. The events only reach
+// the cache of the instance making the change, so the in-process cache is limited to a
+// single instance by the config. The reads fall back to the repositories when the cache fails.
+type catalogueCache struct {
+	cache cache.Cache
+	ttl   time.Duration
+}
+
+func bookKey(id int) string {
+	return catalogueKeyPrefix + "book:" + strconv.Itoa(id)
+}
+
+func authorKey(id int) string {
+	return catalogueKeyPrefix + "author:" + strconv.Itoa(id)
+}
+
+func (c *catalogueCache) enabled() bool {
+	return c != nil && c.cache != nil
+}
+
+// Read the value of the key from the cache, loading and caching it on a miss. Errors are not
+// cached. The misses are loaded from the primary, a replica lagging behind would put the
+// entries a change just dropped back in the cache for the whole ttl.
+func cachedRead[T any](ctx context.Context, c *catalogueCache, key string, load func(ctx context.Context) (T, error)) (T, error) {
+	if !c.enabled() {
+		return load(ctx)
+	}
+
+	var value T
+
+	encoded, found, err := c.cache.Get(ctx, key)
+
+	if err != nil {
+		slog.WarnContext(ctx, "Could not read the catalogue cache", "key", key, "error", err)
+	}
+
+	if found && json.Unmarshal(encoded, &value) == nil {
+		return value, nil
+	}
+
+	value, err = load(data.WithPrimary(ctx))
+
+	if err != nil {
+		return value, err
+	}
+
+	encoded, err = json.Marshal(value)
+
+	if err == nil {
+		err = c.cache.Set(ctx, key, encoded, c.ttl)
+	}
+
+	if err != nil {
+		slog.WarnContext(ctx, "Could not write the catalogue cache", "key", key, "error", err)
+	}
+	return value, nil
+}
+
+// Key of the search with the filter in the current generation of the searches.
+func (c *catalogueCache) searchKey(ctx context.Context, filter data.BookFilter) string {
+	generation, found, err := c.cache.Get(ctx, searchGenerationKey)
+
+	if err != nil {
+		slog.WarnContext(ctx, "Could not read the catalogue cache", "key", searchGenerationKey, "error", err)
+	}
+
+	if !found {
+		generation = []byte(strconv.FormatInt(time.Now().UnixNano(), 36))
+
+		if err = c.cache.Set(ctx, searchGenerationKey, generation, 0); err != nil {
+			slog.WarnContext(ctx, "Could not write the catalogue cache", "key", searchGenerationKey, "error", err)
+		}
+	}
+
+	encoded_filter, _ := json.Marshal(filter)
+	digest := sha256.Sum256(encoded_filter)
+
+	return fmt.Sprintf("%sbooks:%s:%s", catalogueKeyPrefix, generation, hex.EncodeToString(digest[:16]))
+}
+
+// Drop the entries the change makes stale. Without a generation the next search starts a
+// new one, leaving the searches cached in the old one to expire.
+func (c *catalogueCache) handle(ctx context.Context, event catalogueEvent) {
+	if !c.enabled() {
+		return
+	}
+
+	keys := make([]string, 0, len(event.BookIds)+len(event.AuthorIds)+1)
+
+	for _, id := range event.BookIds {
+		keys = append(keys, bookKey(id))
+	}
+
+	for _, id := range event.AuthorIds {
+		keys = append(keys, authorKey(id))
+	}
+
+	if event.Searches {
+		keys = append(keys, searchGenerationKey)
+	}
+
+	if len(keys) == 0 {
+		return
+	}
+
+	if err := c.cache.Delete(ctx, keys...); err != nil {
+		slog.ErrorContext(ctx, "Could not invalidate the catalogue cache", "keys", keys, "error", err)
+	}
+}
+
+// Publish the change to the catalogue to its subscribers, the cache for now. Inside a unit of
+// work it is published once the outermost unit commits, a read in between would put the
+// state before the change back in the cache.
+func (l *LibraryService) publishCatalogueEvent(ctx context.Context, event catalogueEvent) {
+	data.AfterCommit(ctx, func() {
+		l.catalogue.handle(ctx, event)
+	})
+}
@@ -0,0 +1,159 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/cache"
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/data"
+)
+
+func withCatalogueCache(config *Config) {
+	config.Cache = cache.NewLRU(100)
+	config.CatalogueTTL = time.Hour
+}
+
+// The book as read through the cache, by id and in the searches.
+func cachedBook(t *testing.T, service *LibraryService, book_id int) (*data.Book, *data.Book_with_name) {
+	t.Helper()
+	ctx := context.Background()
+
+	book, err := service.GetBook(ctx, book_id)
+
+	if err != nil {
+		t.Fatalf("could not read the book: %v", err)
+	}
+
+	books, err := service.GetBooks(ctx, data.BookFilter{})
+
+	if err != nil {
+		t.Fatalf("could not search the books: %v", err)
+	}
+
+	for _, found := range books {
+		if found.ID == book_id {
+			return book, found
+		}
+	}
+	t.Fatalf("the search did not find book %d", book_id)
+	return nil, nil
+}
+
+func TestCatalogueInvalidation(t *testing.T) {
+	tests := []struct {
+		name string
+		// Made before the book is cached.
+		setup func(t *testing.T, service *LibraryService, model data.Models, f lendingFixture) int
+		// Changes the book once it is cached.
+		change    func(t *testing.T, service *LibraryService, model data.Models, f lendingFixture, borrow_id int)
+		wantTitle string
+		wantCount int
+		// Title the searches find, a lost update leaves them to expire.
+		wantSearchTitle string
+	}{
+		{"drops the book updated", nil, func(t *testing.T, service *LibraryService, model data.Models, f lendingFixture, borrow_id int) {
+			_, err := service.UpdateBook(context.Background(), f.bookId, 1, data.BookUpdate{Title: stringPtr("The Left Hand of Darkness")})
+
+			if err != nil {
+				t.Fatalf("UpdateBook() error = %v", err)
+			}
+		}, "The Left Hand of Darkness", 2, "The Left Hand of Darkness"},
+		{"drops the book of a lost update", nil, func(t *testing.T, service *LibraryService, model data.Models, f lendingFixture, borrow_id int) {
+			// Changed past the service, as another instance would.
+			_, err := model.Book.UpdateBook(context.Background(), f.bookId, 1, data.BookUpdate{Title: stringPtr("The Lathe of Heaven")})
+
+			if err != nil {
+				t.Fatalf("could not update the book: %v", err)
+			}
+
+			_, err = service.UpdateBook(context.Background(), f.bookId, 1, data.BookUpdate{Title: stringPtr("The Left Hand of Darkness")})
+
+			if !errors.Is(err, data.ErrEditConflict) {
+				t.Fatalf("UpdateBook() error = %v, want %v", err, data.ErrEditConflict)
+			}
+		}, "The Lathe of Heaven", 2, "The Dispossessed"},
+		{"drops the book lent", nil, func(t *testing.T, service *LibraryService, model data.Models, f lendingFixture, borrow_id int) {
+			lendBook(t, service, f)
+		}, "The Dispossessed", 1, "The Dispossessed"},
+		{"drops the book returned", func(t *testing.T, service *LibraryService, model data.Models, f lendingFixture) int {
+			return lendBook(t, service, f).ID
+		}, func(t *testing.T, service *LibraryService, model data.Models, f lendingFixture, borrow_id int) {
+			_, err := service.ReturnBook(context.Background(), borrow_id, 0)
+
+			if err != nil {
+				t.Fatalf("ReturnBook() error = %v", err)
+			}
+		}, "The Dispossessed", 2, "The Dispossessed"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			service, model := newTestService(t, withCatalogueCache)
+			fixture := newLendingFixture(t, service, model, 2)
+			borrow_id := 0
+
+			if test.setup != nil {
+				borrow_id = test.setup(t, service, model, fixture)
+			}
+
+			cachedBook(t, service, fixture.bookId)
+			test.change(t, service, model, fixture, borrow_id)
+
+			book, found := cachedBook(t, service, fixture.bookId)
+
+			if book.Title != test.wantTitle || book.BookCount != test.wantCount {
+				t.Errorf("GetBook() = %q with %d copies, want %q with %d", book.Title, book.BookCount, test.wantTitle, test.wantCount)
+			}
+
+			if found.Title != test.wantSearchTitle || found.BookCount != test.wantCount {
+				t.Errorf("GetBooks() found %q with %d copies, want %q with %d", found.Title, found.BookCount, test.wantSearchTitle, test.wantCount)
+			}
+		})
+	}
+}
+
+func TestCatalogueEventsAfterCommit(t *testing.T) {
+	tests := []struct {
+		name string
+		// Error the unit of work fails with after the update.
+		err       error
+		wantTitle string
+	}{
+		{"publishes once the unit commits", nil, "The Left Hand of Darkness"},
+		{"drops the events of a unit rolled back", errors.New("book unavailable"), "The Dispossessed"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			service, model := newTestService(t, withCatalogueCache)
+			fixture := newLendingFixture(t, service, model, 2)
+
+			cachedBook(t, service, fixture.bookId)
+
+			err := model.Tx.Transact(context.Background(), sql.LevelDefault, func(ctx context.Context) error {
+				_, err := service.UpdateBook(ctx, fixture.bookId, 1, data.BookUpdate{Title: stringPtr("The Left Hand of Darkness")})
+
+				if err != nil {
+					return err
+				}
+
+				// A read before the commit is still served the cached book.
+				if book, _ := cachedBook(t, service, fixture.bookId); book.Title != "The Dispossessed" {
+					t.Errorf("GetBook() = %q before the commit, want the cached book", book.Title)
+				}
+				return test.err
+			})
+
+			if !errors.Is(err, test.err) {
+				t.Fatalf("Transact() error = %v, want %v", err, test.err)
+			}
+
+			if book, _ := cachedBook(t, service, fixture.bookId); book.Title != test.wantTitle {
+				t.Errorf("GetBook() = %q after the unit, want %q", book.Title, test.wantTitle)
+			}
+		})
+	}
+}
@@ -0,0 +1,7 @@
+package services
+
+import "github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/data"
+
+// Returned for an unknown email as well as a wrong password, so a login does not tell which
+// emails are registered.
+var ErrInvalidCredentials = data.Unauthorized("invalid_credentials", "Invalid email or password.")
@@ -0,0 +1,188 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/data"
+)
+
+func (l *LibraryService) addFineEntry(ctx context.Context, entry_type string, user_id int, borrow_id *int, amount float32, note string, recorded_by *int) (*data.FineEntry, error) {
+	if amount <= 0 {
+		return nil, data.Invalid("invalid_amount", "Amount must be greater than zero.")
+	}
+
+	entry := data.FineEntry{
+		UserId:     user_id,
+		BorrowId:   borrow_id,
+		EntryType:  entry_type,
+		Amount:     amount,
+		Note:       note,
+		RecordedBy: recorded_by,
+	}
+
+	inserted_entry, err := l.model.FineEntry.InsertFineEntry(ctx, entry)
+
+	if err != nil {
+		return nil, err
+	}
+	return inserted_entry, nil
+}
+
+// A fine can only be charged or waived against an item the member borrowed.
+func (l *LibraryService) checkBorrowOfUser(ctx context.Context, user_id int, borrow_id *int) error {
+	if borrow_id == nil {
+		return nil
+	}
+
+	item, err := l.model.BookBorrowList.GetBookBorrow(ctx, *borrow_id)
+
+	if err != nil {
+		return err
+	}
+
+	borrow_list, err := l.model.BookBorrowList.GetBookBorrowList(ctx, item.ListId)
+
+	if err != nil {
+		return err
+	}
+
+	if borrow_list.UserId != user_id {
+		return data.Invalid("borrow_of_other_user", "Borrowed item with id %d was not borrowed by user %d.", *borrow_id, user_id)
+	}
+	return nil
+}
+
+// Charge a fine to a member, optionally against a borrowed item.
+func (l *LibraryService) ChargeFine(ctx context.Context, user_id int, borrow_id *int, amount float32, note string, recorded_by *int) (*data.FineEntry, error) {
+	ctx, span := startSpan(ctx, "ChargeFine")
+	defer span.End()
+
+	err := l.checkBorrowOfUser(ctx, user_id, borrow_id)
+
+	if err != nil {
+		return nil, err
+	}
+	return l.addFineEntry(ctx, data.FineEntryCharge, user_id, borrow_id, amount, note, recorded_by)
+}
+
+// Record a payment made at the desk. Partial payments are allowed but not more than the balance.
+// Serializable so that concurrent payments can not take the member past the balance together.
+func (l *LibraryService) RecordFinePayment(ctx context.Context, user_id int, amount float32, note string, recorded_by int) (*data.FineEntry, error) {
+	ctx, span := startSpan(ctx, "RecordFinePayment")
+	defer span.End()
+
+	return transact(ctx, l.model.Tx, sql.LevelSerializable, func(ctx context.Context) (*data.FineEntry, error) {
+		balance, err := l.model.FineEntry.GetUserBalance(ctx, user_id)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if amount > balance {
+			return nil, data.Conflict("amount_exceeds_balance", "Payment of %.2f exceeds the outstanding balance of %.2f.", amount, balance)
+		}
+		return l.addFineEntry(ctx, data.FineEntryPayment, user_id, nil, amount, note, &recorded_by)
+	})
+}
+
+// Waive part or all of the outstanding balance of a member. Charges already paid are
+// reversed with a refund instead.
+func (l *LibraryService) WaiveFine(ctx context.Context, user_id int, borrow_id *int, amount float32, note string, recorded_by int) (*data.FineEntry, error) {
+	ctx, span := startSpan(ctx, "WaiveFine")
+	defer span.End()
+
+	return transact(ctx, l.model.Tx, sql.LevelSerializable, func(ctx context.Context) (*data.FineEntry, error) {
+		err := l.checkBorrowOfUser(ctx, user_id, borrow_id)
+
+		if err != nil {
+			return nil, err
+		}
+
+		balance, err := l.model.FineEntry.GetUserBalance(ctx, user_id)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if amount > balance {
+			return nil, data.Conflict("amount_exceeds_balance", "Waiver of %.2f exceeds the outstanding balance of %.2f, refund the charges already paid instead.", amount, balance)
+		}
+		return l.addFineEntry(ctx, data.FineEntryWaiver, user_id, borrow_id, amount, note, &recorded_by)
+	})
+}
+
+// Refund money previously paid by a member, reversing the charges it paid. The refund is
+// recorded with a waiver of the same amount, so the money given back is not owed again and
+// the balance stays as it was.
+func (l *LibraryService) RefundFine(ctx context.Context, user_id int, amount float32, note string, recorded_by int) (*data.FineEntry, error) {
+	ctx, span := startSpan(ctx, "RefundFine")
+	defer span.End()
+
+	return transact(ctx, l.model.Tx, sql.LevelSerializable, func(ctx context.Context) (*data.FineEntry, error) {
+		net_paid, err := l.model.FineEntry.GetUserNetPaid(ctx, user_id)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if amount > net_paid {
+			return nil, data.Conflict("amount_exceeds_paid", "Refund of %.2f exceeds the %.2f paid by the user.", amount, net_paid)
+		}
+
+		_, err = l.addFineEntry(ctx, data.FineEntryWaiver, user_id, nil, amount, fmt.Sprintf("Reversed for refund: %s", note), &recorded_by)
+
+		if err != nil {
+			return nil, err
+		}
+		return l.addFineEntry(ctx, data.FineEntryRefund, user_id, nil, amount, note, &recorded_by)
+	})
+}
+
+func (l *LibraryService) GetFineBalance(ctx context.Context, user_id int) (float32, error) {
+	ctx, span := startSpan(ctx, "GetFineBalance")
+	defer span.End()
+
+	return l.model.FineEntry.GetUserBalance(ctx, user_id)
+}
+
+// Statement of all the ledger entries of a member with the running balance.
+func (l *LibraryService) GetFineStatement(ctx context.Context, user_id int) (*data.FineStatement, error) {
+	ctx, span := startSpan(ctx, "GetFineStatement")
+	defer span.End()
+
+	entries, err := l.model.FineEntry.GetUserEntries(ctx, user_id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	statement := data.FineStatement{
+		UserId:  user_id,
+		Entries: make([]*data.FineStatementLine, 0, len(entries)),
+	}
+
+	for _, entry := range entries {
+		statement.Balance += entry.BalanceEffect()
+		statement.Entries = append(statement.Entries, &data.FineStatementLine{
+			FineEntry: *entry,
+			Balance:   statement.Balance,
+		})
+	}
+	return &statement, nil
+}
+
+// Members owing more than the configured threshold are blocked from new loans.
+func (l *LibraryService) checkFineBlock(ctx context.Context, user_id int) error {
+	balance, err := l.model.FineEntry.GetUserBalance(ctx, user_id)
+
+	if err != nil {
+		return err
+	}
+
+	if balance > l.config.FineBlockThreshold {
+		return data.Conflict("outstanding_fines", "User %d has an outstanding fine balance of %.2f and can not borrow books.", user_id, balance)
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/data"
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/data/memory"
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/ratelimit"
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/utils"
+)
+
+const testPassword = "correct horse"
+
+// Code of the domain error, empty for nil or any other error.
+func errorCode(err error) string {
+	domain_error, ok := data.AsDomainError(err)
+
+	if !ok {
+		return ""
+	}
+	return domain_error.Code
+}
+
+func float32Ptr(value float32) *float32 {
+	return &value
+}
+
+func stringPtr(value string) *string {
+	return &value
+}
+
+// The day at 10:30 UTC, the tests do not depend on the time of day.
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 10, 30, 0, 0, time.UTC)
+}
+
+// A service on in-memory models, with login limits counted in the process. configure
+// changes the config before the service is made.
+func newTestService(t *testing.T, configure ...func(config *Config)) (*LibraryService, data.Models) {
+	t.Helper()
+
+	config := Config{
+		Tokens:             utils.NewTokenManager("test secret", time.Hour),
+		FineBlockThreshold: 10,
+		HoldPickupDays:     3,
+		RateLimits:         ratelimit.NewMemoryStore(),
+		LoginLimits: LoginLimits{
+			RegisterIPLimit:    3,
+			RegisterIPWindow:   time.Hour,
+			IPLimit:            20,
+			IPWindow:           time.Minute,
+			AccountLimit:       10,
+			AccountWindow:      time.Minute,
+			LockoutThreshold:   3,
+			LockoutDuration:    time.Minute,
+			MaxLockoutDuration: time.Hour,
+		},
+	}
+
+	for _, change := range configure {
+		change(&config)
+	}
+
+	model := memory.NewModels()
+	return NewLibraryService(model, config), model
+}
+
+type lendingFixture struct {
+	userId int
+	bookId int
+}
+
+// A member, a book with copies in stock and a policy lending it for a week, fined a unit
+// per open day up to 2.
+func newLendingFixture(t *testing.T, service *LibraryService, model data.Models, copies int) lendingFixture {
+	t.Helper()
+	ctx := context.Background()
+
+	author, err := service.InsertAuthor(ctx, "Ursula K. Le Guin", "Author")
+
+	if err != nil {
+		t.Fatalf("could not create the author: %v", err)
+	}
+
+	book, err := service.InsertBook(ctx, "The Dispossessed", "fiction", "Harper", copies, 20, 1, author.ID)
+
+	if err != nil {
+		t.Fatalf("could not create the book: %v", err)
+	}
+
+	user, err := model.User.CreateUser(ctx, data.User{Name: "Member", Email: "member@example.com", IsActive: true})
+
+	if err != nil {
+		t.Fatalf("could not create the member: %v", err)
+	}
+
+	_, err = service.InsertBorrowPolicy(ctx, data.BorrowPolicy{LoanDays: 7, MaxRenewals: 1, MaxLoans: 2, MaxFine: float32Ptr(2)})
+
+	if err != nil {
+		t.Fatalf("could not create the policy: %v", err)
+	}
+	return lendingFixture{userId: user.ID, bookId: book.ID}
+}
+
+// Lend the book of the fixture to its member.
+func lendBook(t *testing.T, service *LibraryService, fixture lendingFixture) *data.BookBorrorw {
+	t.Helper()
+
+	borrow_list, err := service.LendBooks(context.Background(), fixture.userId, []int{fixture.bookId})
+
+	if err != nil {
+		t.Fatalf("could not lend the book: %v", err)
+	}
+	return borrow_list.BookList[0]
+}
+
+// Move the due date of the loan, past the checks of the service.
+func setDueDate(t *testing.T, model data.Models, borrow_id int, due_date time.Time) {
+	t.Helper()
+
+	_, err := model.BookBorrowList.RenewBookBorrow(context.Background(), borrow_id, due_date)
+
+	if err != nil {
+		t.Fatalf("could not move the due date: %v", err)
+	}
+}
+
+func bookCount(t *testing.T, model data.Models, book_id int) int {
+	t.Helper()
+
+	book, err := model.Book.GetBookWithId(context.Background(), book_id)
+
+	if err != nil {
+		t.Fatalf("could not read the book: %v", err)
+	}
+	return book.BookCount
+}
+
+// Register an active member with the test password.
+func registerMember(t *testing.T, service *LibraryService, email string) {
+	t.Helper()
+
+	_, err := service.RegisterUser(context.Background(), "Member", email, testPassword, "555", true, false, "192.0.2.1")
+
+	if err != nil {
+		t.Fatalf("could not register %s: %v", email, err)
+	}
+}
@@ -0,0 +1,90 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/data"
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/notifications"
+)
+
+func (l *LibraryService) PlaceHold(ctx context.Context, book_id, user_id int) (*data.BookHold, error) {
+	ctx, span := startSpan(ctx, "PlaceHold")
+	defer span.End()
+
+	_, err := l.model.User.GetUserWithId(ctx, user_id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = l.model.Book.GetBookWithId(ctx, book_id)
+
+	if err != nil {
+		return nil, err
+	}
+	return l.model.BookHold.InsertHold(ctx, book_id, user_id)
+}
+
+// Mark a waiting hold ready for pickup, it expires if not collected within the pickup days.
+func (l *LibraryService) MarkHoldReady(ctx context.Context, hold_id int) (*data.BookHold, error) {
+	ctx, span := startSpan(ctx, "MarkHoldReady")
+	defer span.End()
+
+	calendar, err := l.loadCalendar(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	expires_at, err := calendar.addOpenDays(now, l.config.HoldPickupDays)
+
+	if err != nil {
+		return nil, err
+	}
+	hold, err := l.model.BookHold.UpdateHoldStatus(ctx, hold_id, data.HoldWaiting, data.HoldReady, &now, &expires_at)
+
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := l.model.User.GetUserWithId(ctx, hold.UserId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	book, err := l.model.Book.GetBookWithId(ctx, hold.BookId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	l.notifyUser(ctx, notifications.EventHoldReady, recipientOf(user), map[string]any{
+		"Title":     book.Title,
+		"ExpiresAt": expires_at,
+	})
+	return hold, nil
+}
+
+func (l *LibraryService) CancelHold(ctx context.Context, hold_id int) (*data.BookHold, error) {
+	ctx, span := startSpan(ctx, "CancelHold")
+	defer span.End()
+
+	return l.model.BookHold.UpdateHoldStatus(ctx, hold_id, data.HoldWaiting, data.HoldCancelled, nil, nil)
+}
+
+func (l *LibraryService) GetHold(ctx context.Context, hold_id int) (*data.BookHold, error) {
+	ctx, span := startSpan(ctx, "GetHold")
+	defer span.End()
+
+	return l.model.BookHold.GetHoldWithId(ctx, hold_id)
+}
+
+func (l *LibraryService) GetUserHolds(ctx context.Context, user_id int) ([]*data.BookHold, error) {
+	ctx, span := startSpan(ctx, "GetUserHolds")
+	defer span.End()
+
+	return l.model.BookHold.GetUserHolds(ctx, user_id)
+}
@@ -0,0 +1,140 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/data"
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/notifications"
+)
+
+func (l *LibraryService) StartJobRun(ctx context.Context, job_name, instance string, scheduled_at time.Time) (*data.JobRun, error) {
+	ctx, span := startSpan(ctx, "StartJobRun")
+	defer span.End()
+
+	return l.model.JobRun.InsertJobRun(ctx, job_name, instance, scheduled_at)
+}
+
+func (l *LibraryService) FinishJobRun(ctx context.Context, run_id int, job_err error) error {
+	ctx, span := startSpan(ctx, "FinishJobRun")
+	defer span.End()
+
+	if job_err != nil {
+		return l.model.JobRun.FinishJobRun(ctx, run_id, data.JobRunFailed, job_err.Error())
+	}
+	return l.model.JobRun.FinishJobRun(ctx, run_id, data.JobRunSucceeded, "")
+}
+
+func (l *LibraryService) GetJobRuns(ctx context.Context, job_name string, limit int) ([]*data.JobRun, error) {
+	ctx, span := startSpan(ctx, "GetJobRuns")
+	defer span.End()
+
+	return l.model.JobRun.GetJobRuns(ctx, job_name, limit)
+}
+
+// Flag the loans past their due date as overdue and let the members know.
+func (l *LibraryService) MarkOverdueLoans(ctx context.Context) (int, error) {
+	ctx, span := startSpan(ctx, "MarkOverdueLoans")
+	defer span.End()
+
+	items, err := l.model.BookBorrowList.MarkOverdueLoans(ctx, time.Now())
+
+	if err != nil {
+		return 0, err
+	}
+
+	for _, item := range items {
+		err = l.notifyOverdue(ctx, item)
+
+		if err != nil {
+			slog.WarnContext(ctx, "Could not notify the overdue loan", "borrow_id", item.ID, "error", err)
+		}
+	}
+	return len(items), nil
+}
+
+func (l *LibraryService) notifyOverdue(ctx context.Context, item *data.BookBorrorw) error {
+	borrow_list, err := l.model.BookBorrowList.GetBookBorrowList(ctx, item.ListId)
+
+	if err != nil {
+		return err
+	}
+
+	user, err := l.model.User.GetUserWithId(ctx, borrow_list.UserId)
+
+	if err != nil {
+		return err
+	}
+
+	book, err := l.model.Book.GetBookWithId(ctx, item.BookId)
+
+	if err != nil {
+		return err
+	}
+
+	l.notifyUser(ctx, notifications.EventLoanOverdue, recipientOf(user), map[string]any{
+		"Title":      book.Title,
+		"DueDate":    item.DueDate,
+		"FinePerDay": item.FinePerDay,
+	})
+	return nil
+}
+
+// Remind members of the loans falling due within the reminder window.
+func (l *LibraryService) SendDueSoonReminders(ctx context.Context) (int, error) {
+	ctx, span := startSpan(ctx, "SendDueSoonReminders")
+	defer span.End()
+
+	now := time.Now()
+	loans, err := l.model.BookBorrowList.GetDueSoonLoans(ctx, now, now.Add(l.config.DueSoonReminderWindow))
+
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+
+	for _, loan := range loans {
+		recipient := notifications.Recipient{
+			UserId:      loan.UserId,
+			Name:        loan.Name,
+			Email:       loan.Email,
+			PhoneNumber: loan.PhoneNumber,
+		}
+
+		l.notifyUser(ctx, notifications.EventLoanDueSoon, recipient, map[string]any{
+			"Title":   loan.Title,
+			"DueDate": loan.DueDate,
+		})
+
+		err = l.model.BookBorrowList.MarkReminderSent(ctx, loan.BorrowId, time.Now())
+
+		if err != nil {
+			return sent, err
+		}
+		sent++
+	}
+	return sent, nil
+}
+
+// Expire the ready holds that were not collected in time.
+func (l *LibraryService) ExpireUncollectedHolds(ctx context.Context) (int, error) {
+	ctx, span := startSpan(ctx, "ExpireUncollectedHolds")
+	defer span.End()
+
+	holds, err := l.model.BookHold.ExpireHolds(ctx, time.Now())
+
+	if err != nil {
+		return 0, err
+	}
+	return len(holds), nil
+}
+
+// Delete revoked tokens that are past their expiry and can no longer be used anyway.
+func (l *LibraryService) PurgeExpiredTokens(ctx context.Context) (int64, error) {
+	ctx, span := startSpan(ctx, "PurgeExpiredTokens")
+	defer span.End()
+
+	return l.model.RevokedToken.PurgeExpiredTokens(ctx, time.Now())
+}
@@ -1,28 +1,75 @@
 package services
 
 import (
+	"context"
 	"database/sql"
 	"errors"
+	"fmt"
+	"log/slog"
 	"strconv"
 	"time"
 
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/cache"
 	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/data"
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/notifications"
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/ratelimit"
 	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/utils"
 )
 
+// What the service needs besides the repositories.
+type Config struct {
+	Notifications notifications.Config
+	Tokens        *utils.TokenManager
+	// Outstanding balance above which a member can not borrow new books.
+	FineBlockThreshold float32
+	// Open days a member has to collect a book once the hold is ready.
+	HoldPickupDays int
+	// How far ahead members are reminded of the loans falling due.
+	DueSoonReminderWindow time.Duration
+	// Counts of the login attempts, nil to not limit them.
+	RateLimits  ratelimit.Store
+	LoginLimits LoginLimits
+	// Cache of the catalogue reads, nil to read the repositories every time.
+	Cache        cache.Cache
+	CatalogueTTL time.Duration
+}
+
 type LibraryService struct {
-	model data.Models
+	model     data.Models
+	config    Config
+	notifier  *notifications.Service
+	catalogue *catalogueCache
 }
 
-func NewLibraryService(db *sql.DB) *LibraryService {
+// The service works with the repositories it is given, data.New for Postgres.
+func NewLibraryService(model data.Models, config Config) *LibraryService {
 	return &LibraryService{
-		model: data.New(db),
+		model:     model,
+		config:    config,
+		notifier:  notifications.NewService(model.NotificationDelivery, model.NotificationPreference, config.Notifications),
+		catalogue: &catalogueCache{cache: config.Cache, ttl: config.CatalogueTTL},
 	}
 }
 
-func (l *LibraryService) GetBook(id int) (*data.Book, error) {
+// Run fn as one unit of work and return its result.
+func transact[T any](ctx context.Context, tx data.UnitOfWork, isolation sql.IsolationLevel, fn func(ctx context.Context) (T, error)) (T, error) {
+	var result T
+
+	err := tx.Transact(ctx, isolation, func(ctx context.Context) error {
+		var err error
+		result, err = fn(ctx)
+		return err
+	})
+	return result, err
+}
+
+func (l *LibraryService) GetBook(ctx context.Context, id int) (*data.Book, error) {
+	ctx, span := startSpan(ctx, "GetBook")
+	defer span.End()
 
-	book, err := l.model.Book.GetBookWithId(id)
+	book, err := cachedRead(ctx, l.catalogue, bookKey(id), func(ctx context.Context) (*data.Book, error) {
+		return l.model.Book.GetBookWithId(ctx, id)
+	})
 
 	if err != nil {
 		return nil, err
@@ -30,7 +77,58 @@ func (l *LibraryService) GetBook(id int) (*data.Book, error) {
 	return book, nil
 }
 
-func (l *LibraryService) InsertBook(title, category, publisher string, book_count int, price float32, fine_per_day float32, author_id int) (*data.Book, error) {
+// Read the book from the primary past the catalogue cache, for the state an update starts from.
+func (l *LibraryService) GetBookForUpdate(ctx context.Context, id int) (*data.Book, error) {
+	ctx, span := startSpan(ctx, "GetBookForUpdate")
+	defer span.End()
+
+	return l.model.Book.GetBookWithId(ctx, id)
+}
+
+func (l *LibraryService) GetUser(ctx context.Context, id int) (*data.User, error) {
+	ctx, span := startSpan(ctx, "GetUser")
+	defer span.End()
+
+	return l.model.User.GetUserWithId(ctx, id)
+}
+
+func (l *LibraryService) GetLoan(ctx context.Context, borrow_id int) (*data.BookBorrorw, error) {
+	ctx, span := startSpan(ctx, "GetLoan")
+	defer span.End()
+
+	return l.model.BookBorrowList.GetBookBorrow(ctx, borrow_id)
+}
+
+func (l *LibraryService) GetBookRevisions(ctx context.Context, book_id int) ([]*data.BookRevision, error) {
+	ctx, span := startSpan(ctx, "GetBookRevisions")
+	defer span.End()
+
+	_, err := l.model.Book.GetBookWithId(ctx, book_id)
+
+	if err != nil {
+		return nil, err
+	}
+	return l.model.BookRevision.GetRevisions(ctx, book_id)
+}
+
+func (l *LibraryService) GetBookRevision(ctx context.Context, book_id, revision int) (*data.BookRevision, error) {
+	ctx, span := startSpan(ctx, "GetBookRevision")
+	defer span.End()
+
+	return l.model.BookRevision.GetRevision(ctx, book_id, revision)
+}
+
+func (l *LibraryService) GetBookAsOf(ctx context.Context, book_id int, at time.Time) (*data.BookRevision, error) {
+	ctx, span := startSpan(ctx, "GetBookAsOf")
+	defer span.End()
+
+	return l.model.BookRevision.GetRevisionAsOf(ctx, book_id, at)
+}
+
+func (l *LibraryService) InsertBook(ctx context.Context, title, category, publisher string, book_count int, price float32, fine_per_day float32, author_id int) (*data.Book, error) {
+	ctx, span := startSpan(ctx, "InsertBook")
+	defer span.End()
+
 	book_to_insert := data.Book{
 		Title:      title,
 		Category:   category,
@@ -40,17 +138,29 @@ func (l *LibraryService) InsertBook(title, category, publisher string, book_coun
 		FinePerDay: fine_per_day,
 		AuthorId:   author_id,
 	}
-	book, err := l.model.Book.InsertBook(book_to_insert)
+	// Serializable so the category and the author can not go away between the checks and the insert.
+	book, err := transact(ctx, l.model.Tx, sql.LevelSerializable, func(ctx context.Context) (*data.Book, error) {
+		return l.model.Book.InsertBook(ctx, book_to_insert)
+	})
 
 	if err != nil {
 		return nil, err
 	}
+	l.publishCatalogueEvent(ctx, catalogueEvent{Searches: true})
+
 	return book, nil
 }
 
-func (l *LibraryService) UpdateBook(book_id int, input_json map[string]any) (*data.Book, error) {
+func (l *LibraryService) UpdateBook(ctx context.Context, book_id int, version int, update data.BookUpdate) (*data.Book, error) {
+	ctx, span := startSpan(ctx, "UpdateBook")
+	defer span.End()
 
-	book, err := l.model.Book.UpdateBook(book_id, input_json)
+	book, err := l.model.Book.UpdateBook(ctx, book_id, version, update)
+
+	// A lost update means the cached book may be stale as well.
+	if err == nil || errors.Is(err, data.ErrEditConflict) {
+		l.publishCatalogueEvent(ctx, catalogueEvent{BookIds: []int{book_id}, Searches: err == nil})
+	}
 
 	if err != nil {
 		return nil, err
@@ -58,8 +168,19 @@ func (l *LibraryService) UpdateBook(book_id int, input_json map[string]any) (*da
 	return book, nil
 }
 
-func (l *LibraryService) RegisterUser(name, email, password, phone_number string, is_active, is_admin bool) (*data.User, error) {
-	err := utils.ValidatePassword(password)
+// Register the user from the client ip, within the registration limits. A taken email is
+// registered without error and without a user, so the caller answers as for a new one.
+func (l *LibraryService) RegisterUser(ctx context.Context, name, email, password, phone_number string, is_active, is_admin bool, client_ip string) (*data.User, error) {
+	ctx, span := startSpan(ctx, "RegisterUser")
+	defer span.End()
+
+	err := l.checkRegisterLimits(ctx, client_ip)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = utils.ValidatePassword(password)
 
 	if err != nil {
 		return nil, err
@@ -82,7 +203,13 @@ func (l *LibraryService) RegisterUser(name, email, password, phone_number string
 		UpdatedAt:   time.Now(),
 	}
 
-	user, err := l.model.User.CreateUser(userInput)
+	user, err := l.model.User.CreateUser(ctx, userInput)
+
+	// ErrEmailTaken, or the unique index on the email catching a concurrent registration.
+	if data.IsKind(err, data.KindConflict) {
+		slog.InfoContext(ctx, "Registration with a taken email", "account", accountKey(email), "client_ip", client_ip)
+		return nil, nil
+	}
 
 	if err != nil {
 		return nil, err
@@ -91,42 +218,98 @@ func (l *LibraryService) RegisterUser(name, email, password, phone_number string
 	return user, nil
 }
 
-func (l *LibraryService) LoginUser(email, password string) (string, error) {
+// Log the user in from the client ip, within the login limits.
+func (l *LibraryService) LoginUser(ctx context.Context, email, password, client_ip string) (string, error) {
+	ctx, span := startSpan(ctx, "LoginUser")
+	defer span.End()
+
+	account := accountKey(email)
+
+	err := l.checkLoginLimits(ctx, account, client_ip)
+
+	if err != nil {
+		return "", err
+	}
+
 	userInput := data.User{
 		Email: email,
 	}
 
-	user, err := l.model.User.GetUserWithEmail(userInput)
+	user, err := l.model.User.GetUserWithEmail(ctx, userInput)
+
+	if data.IsKind(err, data.KindNotFound) {
+		utils.CheckPasswordHash(password, unknownUserHash())
+		l.recordLoginFailure(ctx, account, client_ip)
+		return "", ErrInvalidCredentials
+	}
 
 	if err != nil {
 		return "", err
 	}
 
 	if is_same := utils.CheckPasswordHash(password, user.Password); !is_same {
-		return "", errors.New("Invalid password")
+		l.recordLoginFailure(ctx, account, client_ip)
+		return "", ErrInvalidCredentials
 	}
+	l.clearLoginFailures(ctx, account)
+
+	token, err := l.config.Tokens.CreateToken(strconv.Itoa(user.ID), user.Email, user.IsAdmin)
 
 	if err != nil {
 		return "", err
 	}
 
-	token, err := utils.CreateToken(strconv.Itoa(user.ID), user.Email, user.IsAdmin)
+	return token, nil
+}
+
+// Revoke the token so it can not be used again before it expires.
+func (l *LibraryService) LogoutUser(ctx context.Context, token string) error {
+	ctx, span := startSpan(ctx, "LogoutUser")
+	defer span.End()
+
+	parsed_token, err := l.ParseToken(token)
 
 	if err != nil {
-		return "", err
+		return err
 	}
 
-	return token, nil
+	if parsed_token.TokenId == "" {
+		return data.Invalid("token_not_revocable", "Token can not be revoked, please login again.")
+	}
+
+	revoked_token := data.RevokedToken{
+		TokenId:   parsed_token.TokenId,
+		UserId:    parsed_token.UserId,
+		ExpiresAt: parsed_token.ExpiresAt,
+	}
+	return l.model.RevokedToken.InsertRevokedToken(ctx, revoked_token)
+}
+
+func (l *LibraryService) ParseToken(token string) (*utils.ParsedToken, error) {
+	return l.config.Tokens.ParseAndValidateToken(token)
+}
+
+func (l *LibraryService) IsTokenRevoked(ctx context.Context, token_id string) (bool, error) {
+	ctx, span := startSpan(ctx, "IsTokenRevoked")
+	defer span.End()
+
+	if token_id == "" {
+		return false, nil
+	}
+	return l.model.RevokedToken.IsTokenRevoked(ctx, token_id)
 }
 
-func (l *LibraryService) InsertAuthor(name, about string) (*data.Author, error) {
+func (l *LibraryService) InsertAuthor(ctx context.Context, name, about string) (*data.Author, error) {
+	ctx, span := startSpan(ctx, "InsertAuthor")
+	defer span.End()
+
 	authorInput := data.Author{
 		Name:  name,
 		About: about,
 	}
 
 	// Add the author.
-	addedAuthor, err := l.model.Author.InsertAuthor(authorInput)
+	addedAuthor, err := l.model.Author.InsertAuthor(ctx, authorInput)
 	if err != nil {
 		return nil, err
 	}
@@ -134,12 +317,17 @@ func (l *LibraryService) InsertAuthor(name, about string) (*data.Author, error)
 	return addedAuthor, nil
 }
 
-func (l *LibraryService) GetAuthor(id int, name string) ([]data.Author, error) {
+func (l *LibraryService) GetAuthor(ctx context.Context, id int, name string) ([]data.Author, error) {
+	ctx, span := startSpan(ctx, "GetAuthor")
+	defer span.End()
+
 	var output_authors []data.Author
 
 	// Get the author with id.
 	if id != 0 {
-		output_author, err := l.model.Author.GetAuthorWithId(id)
+		output_author, err := cachedRead(ctx, l.catalogue, authorKey(id), func(ctx context.Context) (data.Author, error) {
+			return l.model.Author.GetAuthorWithId(ctx, id)
+		})
 		if err != nil {
 			return nil, err
 		}
@@ -148,7 +336,7 @@ func (l *LibraryService) GetAuthor(id int, name string) ([]data.Author, error) {
 
 	} else {
 
-		output_authors, err := l.model.Author.GetAuthorWithDetails(name)
+		output_authors, err := l.model.Author.GetAuthorWithDetails(ctx, name)
 
 		if err != nil {
 			return nil, err
@@ -157,8 +345,77 @@ func (l *LibraryService) GetAuthor(id int, name string) ([]data.Author, error) {
 	}
 }
 
-func (l *LibraryService) GetBooks(input_json map[string]any) ([]*data.Book_with_name, error) {
-	book_list, err := l.model.Book.GetBook(input_json)
+// Read the author from the primary past the catalogue cache, for the state an update starts from.
+func (l *LibraryService) GetAuthorForUpdate(ctx context.Context, id int) (*data.Author, error) {
+	ctx, span := startSpan(ctx, "GetAuthorForUpdate")
+	defer span.End()
+
+	author, err := l.model.Author.GetAuthorWithId(ctx, id)
+
+	if err != nil {
+		return nil, err
+	}
+	return &author, nil
+}
+
+func (l *LibraryService) UpdateAuthor(ctx context.Context, author_id int, version int, name, about string) (*data.Author, error) {
+	ctx, span := startSpan(ctx, "UpdateAuthor")
+	defer span.End()
+
+	if name == "" || about == "" {
+		return nil, data.Invalid("missing_fields", "name and about is mandatory to update the author.")
+	}
+
+	author := data.Author{
+		Name:  name,
+		About: about,
+	}
+	updated_author, err := l.model.Author.UpdateAuthor(ctx, author_id, version, author)
+
+	// The searches match on the name of the author.
+	if err == nil || errors.Is(err, data.ErrEditConflict) {
+		l.publishCatalogueEvent(ctx, catalogueEvent{AuthorIds: []int{author_id}, Searches: err == nil})
+	}
+	return updated_author, err
+}
+
+func (l *LibraryService) UpdateUser(ctx context.Context, user_id int, version int, name, email, phone_number string) (*data.User, error) {
+	ctx, span := startSpan(ctx, "UpdateUser")
+	defer span.End()
+
+	if name == "" || email == "" || phone_number == "" {
+		return nil, data.Invalid("missing_fields", "name, email and phone_number is mandatory to update the user.")
+	}
+
+	existing_user, err := l.model.User.GetUserWithEmail(ctx, data.User{Email: email})
+
+	if err != nil && !data.IsKind(err, data.KindNotFound) {
+		return nil, err
+	}
+
+	if err == nil && existing_user.ID != user_id {
+		return nil, data.Conflict("email_taken", "User with email %s already exists.", email)
+	}
+
+	user := data.User{
+		Name:        name,
+		Email:       email,
+		PhoneNumber: phone_number,
+	}
+	return l.model.User.UpdateUser(ctx, user_id, version, user)
+}
+
+func (l *LibraryService) GetBooks(ctx context.Context, filter data.BookFilter) ([]*data.Book_with_name, error) {
+	ctx, span := startSpan(ctx, "GetBooks")
+	defer span.End()
+
+	if !l.catalogue.enabled() {
+		return l.model.Book.GetBook(ctx, filter)
+	}
+
+	book_list, err := cachedRead(ctx, l.catalogue, l.catalogue.searchKey(ctx, filter), func(ctx context.Context) ([]*data.Book_with_name, error) {
+		return l.model.Book.GetBook(ctx, filter)
+	})
 
 	if err != nil {
 		return nil, err
@@ -166,12 +423,260 @@ func (l *LibraryService) GetBooks(input_json map[string]any) ([]*data.Book_with_
 	return book_list, nil
 }
 
-func (l *LibraryService) LendBooks(input_json map[string]any) (*data.BookBorrowList, error) {
-	book_list, err := l.model.BookBorrowList.CreateBookBorrowList(input_json)
+// Lend the books to a member within the limits of the borrow policies. Serializable so that
+// concurrent loans can not take the member past the limits together.
+func (l *LibraryService) LendBooks(ctx context.Context, user_id int, book_ids []int) (*data.BookBorrowList, error) {
+	ctx, span := startSpan(ctx, "LendBooks")
+	defer span.End()
+
+	borrow_list, err := transact(ctx, l.model.Tx, sql.LevelSerializable, func(ctx context.Context) (*data.BookBorrowList, error) {
+		return l.lendBooks(ctx, user_id, book_ids)
+	})
 
 	if err != nil {
 		return nil, err
 	}
 
-	return book_list, nil
+	// The stock of the books lent went down.
+	l.publishCatalogueEvent(ctx, catalogueEvent{BookIds: book_ids, Searches: true})
+
+	return borrow_list, nil
+}
+
+func (l *LibraryService) lendBooks(ctx context.Context, user_id int, book_ids []int) (*data.BookBorrowList, error) {
+	if len(book_ids) == 0 {
+		return nil, data.Invalid("missing_fields", "book_ids is mandatory to lend books.")
+	}
+
+	err := l.checkFineBlock(ctx, user_id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := l.model.User.GetUserWithId(ctx, user_id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	policies, err := l.model.BorrowPolicy.GetPolicies(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	open_loans, err := l.model.BookBorrowList.GetOpenLoanCounts(ctx, user.ID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// The member wide limit comes from the rule that does not name a category.
+	member_policy, err := resolveBorrowPolicy(policies, user.MemberType, "")
+
+	if err != nil {
+		return nil, err
+	}
+
+	total_loans := len(book_ids)
+	for _, count := range open_loans {
+		total_loans += count
+	}
+
+	if total_loans > member_policy.MaxLoans {
+		return nil, data.Conflict("loan_limit_reached", "User %d can not have more than %d books on loan.", user.ID, member_policy.MaxLoans)
+	}
+
+	calendar, err := l.loadCalendar(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	borrow_list := data.BookBorrowList{UserId: user.ID}
+
+	for _, book_id := range book_ids {
+		book, err := l.model.Book.GetBookWithId(ctx, book_id)
+
+		if err != nil {
+			return nil, err
+		}
+
+		policy, err := resolveBorrowPolicy(policies, user.MemberType, book.Category)
+
+		if err != nil {
+			return nil, err
+		}
+
+		// Rules naming a category also cap the loans within that category.
+		open_loans[book.Category]++
+		if policy.Category != nil && open_loans[book.Category] > policy.MaxLoans {
+			return nil, data.Conflict("loan_limit_reached", "User %d can not have more than %d %s books on loan.", user.ID, policy.MaxLoans, book.Category)
+		}
+
+		due_date, err := calendar.addOpenDays(now, policy.LoanDays)
+
+		if err != nil {
+			return nil, err
+		}
+
+		// The loan keeps the book's rate at checkout, later changes to the book do not
+		// change the fine of loans already made.
+		revision, err := l.model.BookRevision.GetRevisionAsOf(ctx, book.ID, now)
+
+		if err != nil {
+			return nil, err
+		}
+
+		item := data.BookBorrorw{
+			BookId:       book.ID,
+			DueDate:      due_date,
+			FinePerDay:   revision.FinePerDay,
+			MaxFine:      policy.MaxFine,
+			BookRevision: &revision.Revision,
+		}
+
+		if policy.FinePerDay != nil {
+			item.FinePerDay = *policy.FinePerDay
+		}
+
+		if item.DueDate.After(borrow_list.DueDate) {
+			borrow_list.DueDate = item.DueDate
+		}
+		borrow_list.BookList = append(borrow_list.BookList, &item)
+	}
+
+	created_list, err := l.model.BookBorrowList.CreateBookBorrowList(ctx, borrow_list)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return created_list, nil
+}
+
+// Return a borrowed item and charge the overdue fine to the member's ledger, both or neither.
+func (l *LibraryService) ReturnBook(ctx context.Context, borrow_id int, recorded_by int) (*data.BookBorrorw, error) {
+	ctx, span := startSpan(ctx, "ReturnBook")
+	defer span.End()
+
+	item, err := transact(ctx, l.model.Tx, sql.LevelDefault, func(ctx context.Context) (*data.BookBorrorw, error) {
+		return l.returnBook(ctx, borrow_id, recorded_by)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+	l.publishCatalogueEvent(ctx, catalogueEvent{BookIds: []int{item.BookId}, Searches: true})
+
+	return item, nil
+}
+
+func (l *LibraryService) returnBook(ctx context.Context, borrow_id int, recorded_by int) (*data.BookBorrorw, error) {
+	calendar, err := l.loadCalendar(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	item, err := l.model.BookBorrowList.ReturnBookBorrow(ctx, borrow_id, time.Now())
+
+	if err != nil {
+		return nil, err
+	}
+
+	fine := overdueFine(item, *item.ReturnedAt, calendar)
+
+	if fine > 0 {
+		borrow_list, err := l.model.BookBorrowList.GetBookBorrowList(ctx, item.ListId)
+
+		if err != nil {
+			return nil, err
+		}
+
+		note := fmt.Sprintf("Overdue fine for book %d due on %s", item.BookId, item.DueDate.Format(time.DateOnly))
+		_, err = l.addFineEntry(ctx, data.FineEntryCharge, borrow_list.UserId, &item.ID, fine, note, &recorded_by)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+	return item, nil
+}
+
+// Renew a borrowed item for another loan period if the policy allows it. Serializable so
+// that concurrent renewals can not both pass the renewal limit.
+func (l *LibraryService) RenewBook(ctx context.Context, borrow_id int) (*data.BookBorrorw, error) {
+	ctx, span := startSpan(ctx, "RenewBook")
+	defer span.End()
+
+	return transact(ctx, l.model.Tx, sql.LevelSerializable, func(ctx context.Context) (*data.BookBorrorw, error) {
+		return l.renewBook(ctx, borrow_id)
+	})
+}
+
+func (l *LibraryService) renewBook(ctx context.Context, borrow_id int) (*data.BookBorrorw, error) {
+	item, err := l.model.BookBorrowList.GetBookBorrow(ctx, borrow_id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if item.Returned {
+		return nil, data.Conflict("loan_already_returned", "Borrowed item with id %d is already returned.", borrow_id)
+	}
+
+	if time.Now().After(item.DueDate) {
+		return nil, data.Conflict("loan_overdue", "Borrowed item with id %d is overdue and can not be renewed.", borrow_id)
+	}
+
+	borrow_list, err := l.model.BookBorrowList.GetBookBorrowList(ctx, item.ListId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := l.model.User.GetUserWithId(ctx, borrow_list.UserId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	book, err := l.model.Book.GetBookWithId(ctx, item.BookId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	policies, err := l.model.BorrowPolicy.GetPolicies(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	policy, err := resolveBorrowPolicy(policies, user.MemberType, book.Category)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if item.RenewCount >= policy.MaxRenewals {
+		return nil, data.Conflict("renewal_limit_reached", "Borrowed item with id %d can not be renewed more than %d times.", borrow_id, policy.MaxRenewals)
+	}
+
+	calendar, err := l.loadCalendar(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	due_date, err := calendar.addOpenDays(item.DueDate, policy.LoanDays)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return l.model.BookBorrowList.RenewBookBorrow(ctx, borrow_id, due_date)
 }
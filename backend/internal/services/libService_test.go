@@ -0,0 +1,332 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/data"
+)
+
+func TestLendBooks(t *testing.T) {
+	tests := []struct {
+		name     string
+		copies   int
+		books    func(f lendingFixture) []int
+		balance  float32
+		wantCode string
+	}{
+		{"lends a book in stock", 2, func(f lendingFixture) []int { return []int{f.bookId} }, 0, ""},
+		{"lends several copies", 2, func(f lendingFixture) []int { return []int{f.bookId, f.bookId} }, 0, ""},
+		{"rejects no books", 2, func(f lendingFixture) []int { return nil }, 0, "missing_fields"},
+		{"rejects more than the loan limit", 3, func(f lendingFixture) []int { return []int{f.bookId, f.bookId, f.bookId} }, 0, "loan_limit_reached"},
+		{"rejects a book out of stock", 1, func(f lendingFixture) []int { return []int{f.bookId, f.bookId} }, 0, "book_unavailable"},
+		{"rejects an unknown book", 1, func(f lendingFixture) []int { return []int{f.bookId + 1000} }, 0, "book_not_found"},
+		{"rejects a member owing fines", 1, func(f lendingFixture) []int { return []int{f.bookId} }, 11, "outstanding_fines"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			service, model := newTestService(t)
+			fixture := newLendingFixture(t, service, model, test.copies)
+
+			if test.balance > 0 {
+				_, err := service.ChargeFine(ctx, fixture.userId, nil, test.balance, "Lost card", nil)
+
+				if err != nil {
+					t.Fatalf("could not charge the fine: %v", err)
+				}
+			}
+
+			book_ids := test.books(fixture)
+			borrow_list, err := service.LendBooks(ctx, fixture.userId, book_ids)
+			book_count := bookCount(t, model, fixture.bookId)
+
+			if test.wantCode != "" {
+				if errorCode(err) != test.wantCode {
+					t.Fatalf("LendBooks() error = %v, want code %q", err, test.wantCode)
+				}
+
+				if book_count != test.copies {
+					t.Errorf("book count = %d after a failed loan, want %d", book_count, test.copies)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("LendBooks() error = %v", err)
+			}
+
+			if len(borrow_list.BookList) != len(book_ids) {
+				t.Fatalf("lent %d items, want %d", len(borrow_list.BookList), len(book_ids))
+			}
+
+			if book_count != test.copies-len(book_ids) {
+				t.Errorf("book count = %d, want %d", book_count, test.copies-len(book_ids))
+			}
+
+			for _, item := range borrow_list.BookList {
+				if !item.DueDate.After(time.Now().AddDate(0, 0, 7)) {
+					t.Errorf("due date %v is not 7 open days away", item.DueDate)
+				}
+
+				if item.FinePerDay != 1 || item.MaxFine == nil || *item.MaxFine != 2 || item.BookRevision == nil {
+					t.Errorf("item %+v does not keep the rate, max fine and revision at checkout", item)
+				}
+			}
+		})
+	}
+}
+
+func TestReturnBook(t *testing.T) {
+	tests := []struct {
+		name     string
+		overdue  bool
+		twice    bool
+		wantFine float32
+		wantCode string
+	}{
+		{"returns on time without a fine", false, false, 0, ""},
+		{"charges an overdue return up to the max fine", true, false, 2, ""},
+		{"rejects returning twice", false, true, 0, "loan_already_returned"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			service, model := newTestService(t)
+			fixture := newLendingFixture(t, service, model, 1)
+			lent := lendBook(t, service, fixture)
+
+			if test.overdue {
+				setDueDate(t, model, lent.ID, time.Now().AddDate(0, 0, -10))
+			}
+
+			item, err := service.ReturnBook(ctx, lent.ID, 0)
+
+			if test.twice {
+				if err != nil {
+					t.Fatalf("first ReturnBook() error = %v", err)
+				}
+				item, err = service.ReturnBook(ctx, lent.ID, 0)
+			}
+
+			if errorCode(err) != test.wantCode {
+				t.Fatalf("ReturnBook() error = %v, want code %q", err, test.wantCode)
+			}
+
+			if test.wantCode == "" && (!item.Returned || item.ReturnedAt == nil) {
+				t.Errorf("item %+v is not returned", item)
+			}
+
+			balance, err := service.GetFineBalance(ctx, fixture.userId)
+
+			if err != nil {
+				t.Fatalf("GetFineBalance() error = %v", err)
+			}
+
+			if balance != test.wantFine {
+				t.Errorf("fine balance = %v, want %v", balance, test.wantFine)
+			}
+
+			if book_count := bookCount(t, model, fixture.bookId); book_count != 1 {
+				t.Errorf("book count = %d, want the copy back", book_count)
+			}
+		})
+	}
+}
+
+func TestRenewBook(t *testing.T) {
+	tests := []struct {
+		name     string
+		prepare  func(t *testing.T, service *LibraryService, model data.Models, borrow_id int)
+		wantCode string
+	}{
+		{"renews a loan", func(t *testing.T, service *LibraryService, model data.Models, borrow_id int) {}, ""},
+		{"rejects past the renewal limit", func(t *testing.T, service *LibraryService, model data.Models, borrow_id int) {
+			_, err := service.RenewBook(context.Background(), borrow_id)
+
+			if err != nil {
+				t.Fatalf("first RenewBook() error = %v", err)
+			}
+		}, "renewal_limit_reached"},
+		{"rejects an overdue loan", func(t *testing.T, service *LibraryService, model data.Models, borrow_id int) {
+			setDueDate(t, model, borrow_id, time.Now().Add(-time.Hour))
+		}, "loan_overdue"},
+		{"rejects a returned loan", func(t *testing.T, service *LibraryService, model data.Models, borrow_id int) {
+			_, err := service.ReturnBook(context.Background(), borrow_id, 0)
+
+			if err != nil {
+				t.Fatalf("ReturnBook() error = %v", err)
+			}
+		}, "loan_already_returned"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			service, model := newTestService(t)
+			fixture := newLendingFixture(t, service, model, 1)
+			lent := lendBook(t, service, fixture)
+
+			test.prepare(t, service, model, lent.ID)
+
+			item, err := service.RenewBook(context.Background(), lent.ID)
+
+			if errorCode(err) != test.wantCode {
+				t.Fatalf("RenewBook() error = %v, want code %q", err, test.wantCode)
+			}
+
+			if test.wantCode != "" {
+				return
+			}
+
+			if !item.DueDate.After(lent.DueDate.AddDate(0, 0, 7)) || item.RenewCount != 1 {
+				t.Errorf("renewed item %+v, want 7 more open days and one renewal from %v", item, lent.DueDate)
+			}
+		})
+	}
+}
+
+func TestLoginUser(t *testing.T) {
+	tests := []struct {
+		name string
+		// Attempts before the checked one, all with a wrong password.
+		failures int
+		email    string
+		password string
+		wantErr  error
+		wantCode string
+	}{
+		{"logs in", 0, "member@example.com", testPassword, nil, ""},
+		{"rejects a wrong password", 0, "member@example.com", "wrong password", ErrInvalidCredentials, ""},
+		{"rejects an unknown email alike", 0, "nobody@example.com", testPassword, ErrInvalidCredentials, ""},
+		{"locks the account at the threshold", 3, "member@example.com", testPassword, nil, "account_locked"},
+		{"counts failures below the threshold only", 2, "member@example.com", testPassword, nil, ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			service, _ := newTestService(t)
+			registerMember(t, service, "member@example.com")
+
+			for range test.failures {
+				_, err := service.LoginUser(ctx, test.email, "wrong password", "192.0.2.1")
+
+				if !errors.Is(err, ErrInvalidCredentials) {
+					t.Fatalf("failed login error = %v, want invalid credentials", err)
+				}
+			}
+
+			token, err := service.LoginUser(ctx, test.email, test.password, "192.0.2.1")
+
+			switch {
+			case test.wantCode != "":
+				if errorCode(err) != test.wantCode {
+					t.Fatalf("LoginUser() error = %v, want code %q", err, test.wantCode)
+				}
+			case test.wantErr != nil:
+				if !errors.Is(err, test.wantErr) {
+					t.Fatalf("LoginUser() error = %v, want %v", err, test.wantErr)
+				}
+			default:
+				if err != nil {
+					t.Fatalf("LoginUser() error = %v", err)
+				}
+
+				parsed_token, err := service.ParseToken(token)
+
+				if err != nil {
+					t.Fatalf("ParseToken() error = %v", err)
+				}
+
+				if parsed_token.Email != "member@example.com" || parsed_token.IsAdmin {
+					t.Errorf("token of %q (admin %v), want the member", parsed_token.Email, parsed_token.IsAdmin)
+				}
+			}
+		})
+	}
+}
+
+func TestLogoutUser(t *testing.T) {
+	ctx := context.Background()
+	service, _ := newTestService(t)
+	registerMember(t, service, "member@example.com")
+
+	token, err := service.LoginUser(ctx, "member@example.com", testPassword, "192.0.2.1")
+
+	if err != nil {
+		t.Fatalf("LoginUser() error = %v", err)
+	}
+
+	parsed_token, err := service.ParseToken(token)
+
+	if err != nil {
+		t.Fatalf("ParseToken() error = %v", err)
+	}
+
+	for _, step := range []struct {
+		logout      bool
+		wantRevoked bool
+	}{
+		{false, false},
+		{true, true},
+	} {
+		if step.logout {
+			err = service.LogoutUser(ctx, token)
+
+			if err != nil {
+				t.Fatalf("LogoutUser() error = %v", err)
+			}
+		}
+
+		revoked, err := service.IsTokenRevoked(ctx, parsed_token.TokenId)
+
+		if err != nil {
+			t.Fatalf("IsTokenRevoked() error = %v", err)
+		}
+
+		if revoked != step.wantRevoked {
+			t.Errorf("token revoked = %v after logging out %v, want %v", revoked, step.logout, step.wantRevoked)
+		}
+	}
+}
+
+func TestRegisterUser(t *testing.T) {
+	tests := []struct {
+		name string
+		// Registrations from the same client before the checked one.
+		registered []string
+		email      string
+		password   string
+		wantUser   bool
+		wantCode   string
+	}{
+		{"registers a new email", nil, "member@example.com", testPassword, true, ""},
+		{"answers a taken email without a user", []string{"member@example.com"}, "member@example.com", testPassword, false, ""},
+		{"rejects a weak password", nil, "member@example.com", "short", false, "weak_password"},
+		{"limits the registrations of a client", []string{"a@example.com", "b@example.com", "c@example.com"}, "member@example.com", testPassword, false, "too_many_registrations"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			service, _ := newTestService(t)
+
+			for _, email := range test.registered {
+				registerMember(t, service, email)
+			}
+
+			user, err := service.RegisterUser(context.Background(), "Member", test.email, test.password, "555", false, false, "192.0.2.1")
+
+			if errorCode(err) != test.wantCode {
+				t.Fatalf("RegisterUser() error = %v, want code %q", err, test.wantCode)
+			}
+
+			if (user != nil) != test.wantUser {
+				t.Errorf("RegisterUser() user = %+v, want a user %v", user, test.wantUser)
+			}
+		})
+	}
+}
@@ -0,0 +1,184 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/data"
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/utils"
+)
+
+const (
+	// How long the lockouts of an account count towards the length of the next one.
+	lockoutMemory = 24 * time.Hour
+	// How long a client rejected because the store failed is asked to wait.
+	storeFailureRetryAfter = 30 * time.Second
+)
+
+// Limits of the login attempts and registrations. The attempts are counted by client ip and
+// by account, the failed ones lock the account once they reach the lockout threshold, for
+// twice as long each time up to the max lockout.
+type LoginLimits struct {
+	RegisterIPLimit    int
+	RegisterIPWindow   time.Duration
+	IPLimit            int
+	IPWindow           time.Duration
+	AccountLimit       int
+	AccountWindow      time.Duration
+	LockoutThreshold   int
+	LockoutDuration    time.Duration
+	MaxLockoutDuration time.Duration
+	// Let the attempts through when the store fails, rather than rejecting them.
+	FailOpen bool
+}
+
+// Hash compared against when the email is unknown, so the login takes as long as for a
+// wrong password and its timing does not tell which emails are registered.
+var unknownUserHash = sync.OnceValue(func() string {
+	hash, _ := utils.HashPassword("unknown user password")
+	return hash
+})
+
+// Accounts are keyed by a digest of the email, they are counted whether the email is
+// registered or not and the store never holds the emails.
+func accountKey(email string) string {
+	digest := sha256.Sum256([]byte(strings.ToLower(strings.TrimSpace(email))))
+	return hex.EncodeToString(digest[:16])
+}
+
+// Log the failure of the store, letting the attempt through when the limits fail open.
+func (l *LibraryService) limitStoreFailed(ctx context.Context, message, key string, err error) error {
+	slog.ErrorContext(ctx, message, "key", key, "error", err)
+
+	if l.config.LoginLimits.FailOpen {
+		return nil
+	}
+	return data.RateLimited("rate_limit_unavailable", storeFailureRetryAfter, "The attempt can not be checked right now, please try again later.")
+}
+
+// Count a hit on the key, rejecting it once its window holds more than limit hits.
+func (l *LibraryService) hitLimit(ctx context.Context, key string, limit int, window time.Duration, code, message string) error {
+	count, retry_after, err := l.config.RateLimits.Hit(ctx, key, window)
+
+	if err != nil {
+		return l.limitStoreFailed(ctx, "Could not count the attempt", key, err)
+	}
+
+	if count > limit {
+		return data.RateLimited(code, retry_after, message)
+	}
+	return nil
+}
+
+// Count the registration, rejecting it when the client is over its limit.
+func (l *LibraryService) checkRegisterLimits(ctx context.Context, client_ip string) error {
+	if l.config.RateLimits == nil {
+		return nil
+	}
+
+	limits := l.config.LoginLimits
+
+	return l.hitLimit(ctx, "register:ip:"+client_ip, limits.RegisterIPLimit, limits.RegisterIPWindow,
+		"too_many_registrations", "Too many registrations, please try again later.")
+}
+
+// Count the login attempt, rejecting it when the client or the account is over its limit or
+// the account is locked.
+func (l *LibraryService) checkLoginLimits(ctx context.Context, account, client_ip string) error {
+	store := l.config.RateLimits
+	limits := l.config.LoginLimits
+
+	if store == nil {
+		return nil
+	}
+
+	for _, limit := range []struct {
+		key    string
+		limit  int
+		window time.Duration
+	}{
+		{"login:ip:" + client_ip, limits.IPLimit, limits.IPWindow},
+		{"login:account:" + account, limits.AccountLimit, limits.AccountWindow},
+	} {
+		err := l.hitLimit(ctx, limit.key, limit.limit, limit.window, "too_many_login_attempts", "Too many login attempts, please try again later.")
+
+		if err != nil {
+			return err
+		}
+	}
+
+	locked_for, err := store.TTL(ctx, "login:lock:"+account)
+
+	if err != nil {
+		return l.limitStoreFailed(ctx, "Could not check the account lockout", "login:lock:"+account, err)
+	}
+
+	if locked_for > 0 {
+		return data.RateLimited("account_locked", locked_for, "Too many failed logins, please try again later.")
+	}
+	return nil
+}
+
+// Count the failed login, locking the account when the failures reach the threshold.
+func (l *LibraryService) recordLoginFailure(ctx context.Context, account, client_ip string) {
+	store := l.config.RateLimits
+	limits := l.config.LoginLimits
+
+	if store == nil {
+		return
+	}
+
+	failures, _, err := store.Hit(ctx, "login:failures:"+account, limits.AccountWindow)
+
+	if err != nil {
+		slog.ErrorContext(ctx, "Could not count the failed login", "account", account, "error", err)
+		return
+	}
+
+	if failures < limits.LockoutThreshold {
+		return
+	}
+
+	lockouts, _, err := store.Hit(ctx, "login:lockouts:"+account, lockoutMemory)
+
+	if err != nil {
+		slog.ErrorContext(ctx, "Could not count the account lockout", "account", account, "error", err)
+		return
+	}
+
+	duration := limits.LockoutDuration << min(lockouts-1, 16)
+
+	if duration > limits.MaxLockoutDuration || duration <= 0 {
+		duration = limits.MaxLockoutDuration
+	}
+
+	_, _, err = store.Hit(ctx, "login:lock:"+account, duration)
+
+	if err == nil {
+		err = store.Delete(ctx, "login:failures:"+account)
+	}
+
+	if err != nil {
+		slog.ErrorContext(ctx, "Could not lock the account", "account", account, "error", err)
+		return
+	}
+	slog.WarnContext(ctx, "Account locked after failed logins", "account", account, "client_ip", client_ip, "failures", failures, "lockout", duration)
+}
+
+// A successful login clears the failures and lockouts of the account.
+func (l *LibraryService) clearLoginFailures(ctx context.Context, account string) {
+	if l.config.RateLimits == nil {
+		return
+	}
+
+	err := l.config.RateLimits.Delete(ctx, "login:failures:"+account, "login:lockouts:"+account)
+
+	if err != nil {
+		slog.ErrorContext(ctx, "Could not clear the failed logins", "account", account, "error", err)
+	}
+}
@@ -0,0 +1,164 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/ratelimit"
+)
+
+// A store that is down.
+type failingStore struct{}
+
+var errStoreDown = errors.New("store down")
+
+func (failingStore) Hit(ctx context.Context, key string, window time.Duration) (int, time.Duration, error) {
+	return 0, 0, errStoreDown
+}
+
+func (failingStore) TTL(ctx context.Context, key string) (time.Duration, error) {
+	return 0, errStoreDown
+}
+
+func (failingStore) Delete(ctx context.Context, keys ...string) error {
+	return errStoreDown
+}
+
+func (failingStore) Close() error {
+	return nil
+}
+
+// Time left of the lock on the account.
+func lockedFor(t *testing.T, store ratelimit.Store, account string) time.Duration {
+	t.Helper()
+
+	locked_for, err := store.TTL(context.Background(), "login:lock:"+account)
+
+	if err != nil {
+		t.Fatalf("TTL() error = %v", err)
+	}
+	return locked_for
+}
+
+// End the lock on the account, as if its time had passed.
+func unlock(t *testing.T, store ratelimit.Store, account string) {
+	t.Helper()
+
+	err := store.Delete(context.Background(), "login:lock:"+account)
+
+	if err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+}
+
+func TestLockoutDoubles(t *testing.T) {
+	ctx := context.Background()
+	store := ratelimit.NewMemoryStore()
+	service, _ := newTestService(t, func(config *Config) {
+		config.RateLimits = store
+		config.LoginLimits.AccountWindow = time.Hour
+		config.LoginLimits.LockoutThreshold = 2
+		config.LoginLimits.MaxLockoutDuration = 5 * time.Minute
+	})
+	account := accountKey("member@example.com")
+
+	tests := []struct {
+		name string
+		want time.Duration
+	}{
+		{"locks for the lockout duration", time.Minute},
+		{"doubles the second lockout", 2 * time.Minute},
+		{"doubles again", 4 * time.Minute},
+		{"caps at the max lockout", 5 * time.Minute},
+		{"stays at the max lockout", 5 * time.Minute},
+	}
+
+	// Each case runs on the lockouts of the cases before it.
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			service.recordLoginFailure(ctx, account, "192.0.2.1")
+
+			if locked_for := lockedFor(t, store, account); locked_for > 0 {
+				t.Fatalf("locked for %v below the threshold", locked_for)
+			}
+
+			service.recordLoginFailure(ctx, account, "192.0.2.1")
+
+			if locked_for := lockedFor(t, store, account); locked_for > test.want || locked_for < test.want-time.Second {
+				t.Errorf("locked for %v, want %v", locked_for, test.want)
+			}
+
+			// The lock ends, the lockouts are still remembered.
+			unlock(t, store, account)
+		})
+	}
+}
+
+func TestSuccessfulLoginForgetsLockouts(t *testing.T) {
+	ctx := context.Background()
+	store := ratelimit.NewMemoryStore()
+	service, _ := newTestService(t, func(config *Config) {
+		config.RateLimits = store
+		config.LoginLimits.AccountWindow = time.Hour
+		config.LoginLimits.LockoutThreshold = 1
+	})
+	account := accountKey("member@example.com")
+
+	service.recordLoginFailure(ctx, account, "192.0.2.1")
+	unlock(t, store, account)
+	service.clearLoginFailures(ctx, account)
+	service.recordLoginFailure(ctx, account, "192.0.2.1")
+
+	if locked_for := lockedFor(t, store, account); locked_for > time.Minute {
+		t.Errorf("locked for %v after a successful login, want the first lockout again", locked_for)
+	}
+}
+
+func TestAccountKey(t *testing.T) {
+	key := accountKey("member@example.com")
+
+	for _, email := range []string{"Member@Example.com", "  member@example.com "} {
+		if got := accountKey(email); got != key {
+			t.Errorf("accountKey(%q) = %q, want the key of member@example.com", email, got)
+		}
+	}
+
+	if accountKey("other@example.com") == key {
+		t.Error("accountKey() is the same for another email")
+	}
+}
+
+func TestLoginLimitsOnStoreFailure(t *testing.T) {
+	tests := []struct {
+		name     string
+		failOpen bool
+		wantCode string
+	}{
+		{"rejects when failing closed", false, "rate_limit_unavailable"},
+		{"lets through when failing open", true, ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			service, _ := newTestService(t, func(config *Config) {
+				config.RateLimits = failingStore{}
+				config.LoginLimits.FailOpen = test.failOpen
+			})
+
+			err := service.checkLoginLimits(ctx, accountKey("member@example.com"), "192.0.2.1")
+
+			if errorCode(err) != test.wantCode {
+				t.Errorf("checkLoginLimits() error = %v, want code %q", err, test.wantCode)
+			}
+
+			err = service.checkRegisterLimits(ctx, "192.0.2.1")
+
+			if errorCode(err) != test.wantCode {
+				t.Errorf("checkRegisterLimits() error = %v, want code %q", err, test.wantCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/data"
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/notifications"
+)
+
+func recipientOf(user *data.User) notifications.Recipient {
+	return notifications.Recipient{
+		UserId:      user.ID,
+		Name:        user.Name,
+		Email:       user.Email,
+		PhoneNumber: user.PhoneNumber,
+	}
+}
+
+// Notify a member, a failure to queue the notification does not fail the action that caused it.
+func (l *LibraryService) notifyUser(ctx context.Context, event string, recipient notifications.Recipient, template_data map[string]any) {
+	template_data["Name"] = recipient.Name
+	template_data["Email"] = recipient.Email
+
+	err := l.notifier.Notify(ctx, event, recipient, template_data)
+
+	if err != nil {
+		slog.WarnContext(ctx, "Could not notify user", "user_id", recipient.UserId, "event", event, "error", err)
+	}
+}
+
+func (l *LibraryService) ActivateUser(ctx context.Context, user_id int) error {
+	ctx, span := startSpan(ctx, "ActivateUser")
+	defer span.End()
+
+	err := l.model.User.ActivateUser(ctx, user_id)
+
+	if err != nil {
+		return err
+	}
+
+	user, err := l.model.User.GetUserWithId(ctx, user_id)
+
+	if err != nil {
+		return err
+	}
+
+	l.notifyUser(ctx, notifications.EventAccountActivated, recipientOf(user), map[string]any{})
+	return nil
+}
+
+func (l *LibraryService) GetNotificationChannels() []string {
+	channels := l.notifier.Channels()
+	slices.Sort(channels)
+	return channels
+}
+
+func (l *LibraryService) GetNotificationPreferences(ctx context.Context, user_id int) ([]*data.NotificationPreference, error) {
+	ctx, span := startSpan(ctx, "GetNotificationPreferences")
+	defer span.End()
+
+	return l.model.NotificationPreference.GetUserPreferences(ctx, user_id)
+}
+
+func (l *LibraryService) UpdateNotificationPreference(ctx context.Context, user_id int, channel string, enabled bool) (*data.NotificationPreference, error) {
+	ctx, span := startSpan(ctx, "UpdateNotificationPreference")
+	defer span.End()
+
+	if !slices.Contains(l.notifier.Channels(), channel) {
+		return nil, data.Invalid("unknown_notification_channel", "Notification channel %s is not available.", channel)
+	}
+
+	_, err := l.model.User.GetUserWithId(ctx, user_id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	preference := data.NotificationPreference{
+		UserId:  user_id,
+		Channel: channel,
+		Enabled: enabled,
+	}
+	return l.model.NotificationPreference.UpsertPreference(ctx, preference)
+}
+
+func (l *LibraryService) GetUserNotifications(ctx context.Context, user_id int, limit int) ([]*data.NotificationDelivery, error) {
+	ctx, span := startSpan(ctx, "GetUserNotifications")
+	defer span.End()
+
+	return l.model.NotificationDelivery.GetUserDeliveries(ctx, user_id, limit)
+}
+
+// Retry the failed notifications whose backoff has elapsed.
+func (l *LibraryService) RetryNotifications(ctx context.Context) (int, error) {
+	ctx, span := startSpan(ctx, "RetryNotifications")
+	defer span.End()
+
+	return l.notifier.RetryDue(ctx)
+}
+
+// Wait for notifications still being sent in the background.
+func (l *LibraryService) WaitForNotifications() {
+	l.notifier.Wait()
+}
@@ -0,0 +1,29 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/data"
+)
+
+func (l *LibraryService) ClaimOutboxEvents(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*data.OutboxEvent, error) {
+	ctx, span := startSpan(ctx, "ClaimOutboxEvents")
+	defer span.End()
+
+	return l.model.OutboxEvent.ClaimEvents(ctx, now, lease, limit)
+}
+
+func (l *LibraryService) MarkOutboxEventPublished(ctx context.Context, id int64) error {
+	ctx, span := startSpan(ctx, "MarkOutboxEventPublished")
+	defer span.End()
+
+	return l.model.OutboxEvent.MarkPublished(ctx, id)
+}
+
+func (l *LibraryService) RecordOutboxEventFailure(ctx context.Context, id int64, last_error string, next_attempt_at time.Time) error {
+	ctx, span := startSpan(ctx, "RecordOutboxEventFailure")
+	defer span.End()
+
+	return l.model.OutboxEvent.RecordFailure(ctx, id, last_error, next_attempt_at)
+}
@@ -0,0 +1,122 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/data"
+)
+
+// Pick the most specific rule for a member type and book category. A rule naming the
+// member type beats one naming the category, and both beat the catch all rule.
+func resolveBorrowPolicy(policies []*data.BorrowPolicy, member_type, category string) (*data.BorrowPolicy, error) {
+	var resolved *data.BorrowPolicy
+	best_score := -1
+
+	for _, policy := range policies {
+		score := 0
+
+		if policy.MemberType != nil {
+			if *policy.MemberType != member_type {
+				continue
+			}
+			score += 2
+		}
+
+		if policy.Category != nil {
+			if *policy.Category != category {
+				continue
+			}
+			score += 1
+		}
+
+		if score > best_score {
+			resolved = policy
+			best_score = score
+		}
+	}
+
+	if resolved == nil {
+		return nil, data.Conflict("no_borrow_policy", "No borrow policy applies to member type %q and category %q.", member_type, category)
+	}
+	return resolved, nil
+}
+
+// Fine for an item returned at returned_at, charged only for the days the library was open at
+// the rate and up to the max fine fixed at checkout, so later edits to the book or the policy
+// do not change it.
+func overdueFine(item *data.BookBorrorw, returned_at time.Time, calendar *libraryCalendar) float32 {
+	if !returned_at.After(item.DueDate) {
+		return 0
+	}
+
+	fine := float32(calendar.openDaysBetween(item.DueDate, returned_at)) * item.FinePerDay
+
+	if item.MaxFine != nil && fine > *item.MaxFine {
+		fine = *item.MaxFine
+	}
+	return fine
+}
+
+func validateBorrowPolicy(policy data.BorrowPolicy) error {
+	if policy.LoanDays <= 0 || policy.MaxLoans <= 0 || policy.MaxRenewals < 0 {
+		return data.Invalid("invalid_borrow_policy", "loan_days and max_loans must be positive and max_renewals can not be negative.")
+	}
+
+	if (policy.FinePerDay != nil && *policy.FinePerDay < 0) || (policy.MaxFine != nil && *policy.MaxFine < 0) {
+		return data.Invalid("invalid_borrow_policy", "fine_per_day and max_fine can not be negative.")
+	}
+	return nil
+}
+
+func (l *LibraryService) GetBorrowPolicies(ctx context.Context) ([]*data.BorrowPolicy, error) {
+	ctx, span := startSpan(ctx, "GetBorrowPolicies")
+	defer span.End()
+
+	return l.model.BorrowPolicy.GetPolicies(ctx)
+}
+
+func (l *LibraryService) GetBorrowPolicy(ctx context.Context, id int) (*data.BorrowPolicy, error) {
+	ctx, span := startSpan(ctx, "GetBorrowPolicy")
+	defer span.End()
+
+	return l.model.BorrowPolicy.GetPolicyWithId(ctx, id)
+}
+
+func (l *LibraryService) InsertBorrowPolicy(ctx context.Context, policy data.BorrowPolicy) (*data.BorrowPolicy, error) {
+	ctx, span := startSpan(ctx, "InsertBorrowPolicy")
+	defer span.End()
+
+	err := validateBorrowPolicy(policy)
+
+	if err != nil {
+		return nil, err
+	}
+	return l.model.BorrowPolicy.InsertPolicy(ctx, policy)
+}
+
+func (l *LibraryService) UpdateBorrowPolicy(ctx context.Context, id int, policy data.BorrowPolicy) (*data.BorrowPolicy, error) {
+	ctx, span := startSpan(ctx, "UpdateBorrowPolicy")
+	defer span.End()
+
+	err := validateBorrowPolicy(policy)
+
+	if err != nil {
+		return nil, err
+	}
+	return l.model.BorrowPolicy.UpdatePolicy(ctx, id, policy)
+}
+
+func (l *LibraryService) DeleteBorrowPolicy(ctx context.Context, id int) error {
+	ctx, span := startSpan(ctx, "DeleteBorrowPolicy")
+	defer span.End()
+
+	return l.model.BorrowPolicy.DeletePolicy(ctx, id)
+}
+
+func (l *LibraryService) UpdateMemberType(ctx context.Context, user_id int, member_type string) error {
+	ctx, span := startSpan(ctx, "UpdateMemberType")
+	defer span.End()
+
+	return l.model.User.UpdateMemberType(ctx, user_id, member_type)
+}
@@ -0,0 +1,87 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/data"
+)
+
+func TestResolveBorrowPolicy(t *testing.T) {
+	policies := []*data.BorrowPolicy{
+		{ID: 1, LoanDays: 14},
+		{ID: 2, Category: stringPtr("reference"), LoanDays: 3},
+		{ID: 3, MemberType: stringPtr("premium"), LoanDays: 28},
+		{ID: 4, MemberType: stringPtr("premium"), Category: stringPtr("reference"), LoanDays: 7},
+		{ID: 5, MemberType: stringPtr("student"), Category: stringPtr("textbook"), LoanDays: 90},
+	}
+
+	tests := []struct {
+		name       string
+		policies   []*data.BorrowPolicy
+		memberType string
+		category   string
+		wantId     int
+		wantCode   string
+	}{
+		{"falls back on the default rule", policies, "standard", "fiction", 1, ""},
+		{"prefers the category rule", policies, "standard", "reference", 2, ""},
+		{"prefers the member rule over the category rule", policies, "premium", "fiction", 3, ""},
+		{"prefers the rule naming both", policies, "premium", "reference", 4, ""},
+		{"uses the member wide rule for no category", policies, "premium", "", 3, ""},
+		{"ignores rules of other members", policies, "standard", "textbook", 1, ""},
+		{"uses the only matching rule", policies[4:], "student", "textbook", 5, ""},
+		{"rejects when no rule applies", policies[1:], "standard", "fiction", 0, "no_borrow_policy"},
+		{"rejects without rules", nil, "standard", "fiction", 0, "no_borrow_policy"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			policy, err := resolveBorrowPolicy(test.policies, test.memberType, test.category)
+
+			if errorCode(err) != test.wantCode {
+				t.Fatalf("resolveBorrowPolicy() error = %v, want code %q", err, test.wantCode)
+			}
+
+			if test.wantCode == "" && policy.ID != test.wantId {
+				t.Errorf("resolveBorrowPolicy(%q, %q) = policy %d, want %d", test.memberType, test.category, policy.ID, test.wantId)
+			}
+		})
+	}
+}
+
+func TestOverdueFine(t *testing.T) {
+	// Due on Friday 2025-03-07, the library is closed on Sundays and on 2025-03-12.
+	calendar := newLibraryCalendar(
+		[]*data.LibraryHours{{Weekday: int(time.Sunday), IsClosed: true}},
+		[]*data.LibraryClosure{{ClosureDate: date(2025, time.March, 12)}},
+	)
+
+	tests := []struct {
+		name       string
+		finePerDay float32
+		maxFine    *float32
+		returnedAt time.Time
+		want       float32
+	}{
+		{"charges nothing on time", 1.5, nil, date(2025, time.March, 7).Add(-time.Hour), 0},
+		{"charges nothing later on the due day", 1.5, nil, date(2025, time.March, 7).Add(time.Hour), 0},
+		{"charges the open days", 1.5, nil, date(2025, time.March, 10), 3},
+		{"skips the closures", 1, nil, date(2025, time.March, 13), 4},
+		{"caps at the max fine", 1, float32Ptr(3), date(2025, time.March, 13), 3},
+		{"charges below the max fine", 1, float32Ptr(10), date(2025, time.March, 13), 4},
+		{"charges nothing without a rate", 0, float32Ptr(10), date(2025, time.March, 13), 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			item := &data.BookBorrorw{DueDate: date(2025, time.March, 7), FinePerDay: test.finePerDay, MaxFine: test.maxFine}
+
+			got := overdueFine(item, test.returnedAt, calendar)
+
+			if got != test.want {
+				t.Errorf("overdueFine(returned %v) = %v, want %v", test.returnedAt, got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,15 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/data"
+)
+
+func (l *LibraryService) GetLibraryStats(ctx context.Context) (*data.LibraryStats, error) {
+	ctx, span := startSpan(ctx, "GetLibraryStats")
+	defer span.End()
+
+	return l.model.Stats.GetLibraryStats(ctx, time.Now())
+}
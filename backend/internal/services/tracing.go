@@ -0,0 +1,20 @@
+package services
+
+import (
+	"context"
+
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer(tracing.TracerName)
+
+// Span of a service call within the trace of the request or job making it. The calls made
+// outside of any trace, like the polls of the outbox dispatcher, are not traced.
+func startSpan(ctx context.Context, name string) (context.Context, trace.Span) {
+	if !tracing.InTrace(ctx) {
+		return ctx, trace.SpanFromContext(ctx)
+	}
+	return tracer.Start(ctx, "LibraryService."+name)
+}
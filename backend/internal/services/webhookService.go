@@ -0,0 +1,213 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/data"
+)
+
+func newWebhookSecret() (string, error) {
+	secret := make([]byte, 32)
+
+	_, err := rand.Read(secret)
+
+	if err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(secret), nil
+}
+
+func validateWebhookSubscription(subscription data.WebhookSubscription) error {
+	if strings.TrimSpace(subscription.Name) == "" {
+		return data.Invalid("missing_fields", "Webhook name is required.")
+	}
+
+	parsed_url, err := url.Parse(subscription.URL)
+
+	if err != nil || (parsed_url.Scheme != "http" && parsed_url.Scheme != "https") || parsed_url.Host == "" {
+		return data.Invalid("invalid_webhook_url", "Webhook url %s must be an absolute http or https url.", subscription.URL)
+	}
+
+	for _, event_type := range subscription.EventTypes {
+		if event_type == "*" || slices.Contains(data.EventTypes, event_type) {
+			continue
+		}
+
+		if prefix, ok := strings.CutSuffix(event_type, ".*"); ok {
+			matched := slices.ContainsFunc(data.EventTypes, func(known string) bool {
+				return strings.HasPrefix(known, prefix+".")
+			})
+
+			if matched {
+				continue
+			}
+		}
+		return data.Invalid("unknown_event_type", "Unknown event type %s, expected one of %s or a pattern such as loan.*", event_type, strings.Join(data.EventTypes, ", "))
+	}
+	return nil
+}
+
+// The secret is only shown when the subscription is created or its secret rotated.
+func hideWebhookSecret(subscription *data.WebhookSubscription) *data.WebhookSubscription {
+	subscription.Secret = ""
+	return subscription
+}
+
+func (l *LibraryService) InsertWebhookSubscription(ctx context.Context, subscription data.WebhookSubscription) (*data.WebhookSubscription, error) {
+	ctx, span := startSpan(ctx, "InsertWebhookSubscription")
+	defer span.End()
+
+	err := validateWebhookSubscription(subscription)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if subscription.Secret == "" {
+		subscription.Secret, err = newWebhookSecret()
+
+		if err != nil {
+			return nil, err
+		}
+	}
+	return l.model.WebhookSubscription.InsertSubscription(ctx, subscription)
+}
+
+func (l *LibraryService) UpdateWebhookSubscription(ctx context.Context, subscription_id int, subscription data.WebhookSubscription) (*data.WebhookSubscription, error) {
+	ctx, span := startSpan(ctx, "UpdateWebhookSubscription")
+	defer span.End()
+
+	err := validateWebhookSubscription(subscription)
+
+	if err != nil {
+		return nil, err
+	}
+
+	updated_subscription, err := l.model.WebhookSubscription.UpdateSubscription(ctx, subscription_id, subscription)
+
+	if err != nil {
+		return nil, err
+	}
+	return hideWebhookSecret(updated_subscription), nil
+}
+
+func (l *LibraryService) RotateWebhookSecret(ctx context.Context, subscription_id int) (*data.WebhookSubscription, error) {
+	ctx, span := startSpan(ctx, "RotateWebhookSecret")
+	defer span.End()
+
+	secret, err := newWebhookSecret()
+
+	if err != nil {
+		return nil, err
+	}
+	return l.model.WebhookSubscription.UpdateSecret(ctx, subscription_id, secret)
+}
+
+func (l *LibraryService) DeleteWebhookSubscription(ctx context.Context, subscription_id int) error {
+	ctx, span := startSpan(ctx, "DeleteWebhookSubscription")
+	defer span.End()
+
+	return l.model.WebhookSubscription.DeleteSubscription(ctx, subscription_id)
+}
+
+func (l *LibraryService) GetWebhookSubscriptions(ctx context.Context) ([]*data.WebhookSubscription, error) {
+	ctx, span := startSpan(ctx, "GetWebhookSubscriptions")
+	defer span.End()
+
+	subscriptions, err := l.model.WebhookSubscription.GetSubscriptions(ctx, false)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, subscription := range subscriptions {
+		hideWebhookSecret(subscription)
+	}
+	return subscriptions, nil
+}
+
+// The subscription as the admin endpoints show it, without its secret.
+func (l *LibraryService) GetWebhookSubscriptionWithoutSecret(ctx context.Context, subscription_id int) (*data.WebhookSubscription, error) {
+	ctx, span := startSpan(ctx, "GetWebhookSubscriptionWithoutSecret")
+	defer span.End()
+
+	subscription, err := l.model.WebhookSubscription.GetSubscriptionWithId(ctx, subscription_id)
+
+	if err != nil {
+		return nil, err
+	}
+	return hideWebhookSecret(subscription), nil
+}
+
+func (l *LibraryService) GetWebhookDeliveries(ctx context.Context, subscription_id int, status string, limit int) ([]*data.WebhookDelivery, error) {
+	ctx, span := startSpan(ctx, "GetWebhookDeliveries")
+	defer span.End()
+
+	if status != "" && !slices.Contains([]string{data.WebhookDeliveryPending, data.WebhookDeliverySucceeded, data.WebhookDeliveryDead}, status) {
+		return nil, data.Invalid("unknown_delivery_status", "Unknown delivery status %s.", status)
+	}
+
+	_, err := l.model.WebhookSubscription.GetSubscriptionWithId(ctx, subscription_id)
+
+	if err != nil {
+		return nil, err
+	}
+	return l.model.WebhookDelivery.GetSubscriptionDeliveries(ctx, subscription_id, status, limit)
+}
+
+func (l *LibraryService) GetWebhookDelivery(ctx context.Context, delivery_id int64) (*data.WebhookDelivery, error) {
+	ctx, span := startSpan(ctx, "GetWebhookDelivery")
+	defer span.End()
+
+	return l.model.WebhookDelivery.GetDeliveryWithId(ctx, delivery_id)
+}
+
+func (l *LibraryService) ReplayWebhookDelivery(ctx context.Context, delivery_id int64) (*data.WebhookDelivery, error) {
+	ctx, span := startSpan(ctx, "ReplayWebhookDelivery")
+	defer span.End()
+
+	return l.model.WebhookDelivery.ReplayDelivery(ctx, delivery_id)
+}
+
+// Used by the webhook sink and deliverer.
+
+func (l *LibraryService) GetActiveWebhookSubscriptions(ctx context.Context) ([]*data.WebhookSubscription, error) {
+	ctx, span := startSpan(ctx, "GetActiveWebhookSubscriptions")
+	defer span.End()
+
+	return l.model.WebhookSubscription.GetSubscriptions(ctx, true)
+}
+
+func (l *LibraryService) GetWebhookSubscription(ctx context.Context, id int) (*data.WebhookSubscription, error) {
+	ctx, span := startSpan(ctx, "GetWebhookSubscription")
+	defer span.End()
+
+	return l.model.WebhookSubscription.GetSubscriptionWithId(ctx, id)
+}
+
+func (l *LibraryService) QueueWebhookDelivery(ctx context.Context, subscription_id int, event_id int64, event_type string, payload []byte) error {
+	ctx, span := startSpan(ctx, "QueueWebhookDelivery")
+	defer span.End()
+
+	return l.model.WebhookDelivery.InsertDelivery(ctx, subscription_id, event_id, event_type, payload)
+}
+
+func (l *LibraryService) ClaimWebhookDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*data.WebhookDelivery, error) {
+	ctx, span := startSpan(ctx, "ClaimWebhookDeliveries")
+	defer span.End()
+
+	return l.model.WebhookDelivery.ClaimDueDeliveries(ctx, now, lease, limit)
+}
+
+func (l *LibraryService) RecordWebhookAttempt(ctx context.Context, id int64, status string, response_status *int, last_error string, next_attempt_at *time.Time) error {
+	ctx, span := startSpan(ctx, "RecordWebhookAttempt")
+	defer span.End()
+
+	return l.model.WebhookDelivery.RecordAttempt(ctx, id, status, response_status, last_error, next_attempt_at)
+}
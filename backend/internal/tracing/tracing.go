@@ -0,0 +1,103 @@
+// Package tracing sets up OpenTelemetry: the tracer provider exporting the spans of the
+// requests, services and SQL statements, and the W3C trace context propagation.
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+	ExporterOTLP   = "otlp"
+)
+
+// Name of the tracers of the server's own spans.
+const TracerName = "github.com/vinaycchndra/Libray_Managment_Go/backend"
+
+type Settings struct {
+	// none, stdout or otlp.
+	Exporter string
+	// host:port of the OTLP/HTTP collector. When empty the exporter reads the standard
+	// OTEL_EXPORTER_OTLP_* variables, defaulting to localhost:4318.
+	OTLPEndpoint string
+	OTLPInsecure bool
+	ServiceName  string
+	// Share of the traces started here that are recorded, from 0 to 1. Traces continued
+	// from a client follow the client's sampling decision.
+	SampleRatio float64
+}
+
+// Install the tracer provider and the propagator globally. The trace context is propagated
+// even when no exporter is set, so the traces of the callers are not broken. The returned
+// func flushes the spans not exported yet and must be called on shutdown.
+func Setup(ctx context.Context, settings Settings) (func(ctx context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	var exporter sdktrace.SpanExporter
+	var err error
+
+	switch settings.Exporter {
+	case ExporterNone, "":
+		return func(ctx context.Context) error { return nil }, nil
+	case ExporterStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithPrettyPrint())
+	case ExporterOTLP:
+		options := make([]otlptracehttp.Option, 0)
+
+		if settings.OTLPEndpoint != "" {
+			options = append(options, otlptracehttp.WithEndpoint(settings.OTLPEndpoint))
+		}
+
+		if settings.OTLPInsecure {
+			options = append(options, otlptracehttp.WithInsecure())
+		}
+		exporter, err = otlptracehttp.New(ctx, options...)
+	default:
+		return nil, fmt.Errorf("unknown trace exporter %q", settings.Exporter)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("creating the %s trace exporter: %w", settings.Exporter, err)
+	}
+
+	service, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(settings.ServiceName)))
+
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(service),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(settings.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
+
+// Id of the trace ctx belongs to, empty outside of a trace.
+func TraceId(ctx context.Context) string {
+	span_context := trace.SpanContextFromContext(ctx)
+
+	if !span_context.HasTraceID() {
+		return ""
+	}
+	return span_context.TraceID().String()
+}
+
+// Whether ctx belongs to a trace, which spans started with it are added to.
+func InTrace(ctx context.Context) bool {
+	return trace.SpanContextFromContext(ctx).IsValid()
+}
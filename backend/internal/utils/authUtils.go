@@ -1,42 +1,67 @@
 package utils
 
 import (
-	"errors"
-	"os"
+	"crypto/rand"
+	"encoding/hex"
 	"strconv"
 	"time"
 
 	"github.com/golang-jwt/jwt"
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/data"
 	"golang.org/x/crypto/bcrypt"
 )
 
-var jwtSecret = []byte(os.Getenv("JWT_SECRET"))
-
 type ParsedToken struct {
-	UserId  int
-	Email   string
-	IsAdmin bool
+	UserId    int
+	Email     string
+	IsAdmin   bool
+	TokenId   string
+	ExpiresAt time.Time
+}
+
+// Random id for a token so that it can be revoked before it expires.
+func newTokenId() (string, error) {
+	bytes := make([]byte, 16)
+
+	_, err := rand.Read(bytes)
+
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(bytes), nil
+}
+
+// Signs and verifies the access tokens with the secret.
+type TokenManager struct {
+	secret []byte
+	expiry time.Duration
 }
 
-func CreateToken(user_id, email string, is_admin bool) (string, error) {
+func NewTokenManager(secret string, expiry time.Duration) *TokenManager {
+	return &TokenManager{secret: []byte(secret), expiry: expiry}
+}
+
+func (t *TokenManager) CreateToken(user_id, email string, is_admin bool) (string, error) {
 	now := time.Now()
-	token_duration, err := strconv.Atoi(os.Getenv("TOKEN_EXPIRY_DURATION"))
+
+	token_id, err := newTokenId()
 
 	if err != nil {
 		return "", err
 	}
 
 	claims := jwt.MapClaims{
+		"token_id":   token_id,
 		"user_id":    user_id,
 		"email":      email,
 		"is_admin":   is_admin,
 		"created_at": now.Unix(),
-		"expires_at": now.Add(time.Duration(token_duration) * time.Second).Unix(),
+		"expires_at": now.Add(t.expiry).Unix(),
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 
-	tokenString, err := token.SignedString(jwtSecret)
+	tokenString, err := token.SignedString(t.secret)
 
 	if err != nil {
 		return "", err
@@ -46,20 +71,20 @@ func CreateToken(user_id, email string, is_admin bool) (string, error) {
 }
 
 // Parsing and validating the token
-func ParseAndValidateToken(tokenString string) (*ParsedToken, error) {
+func (t *TokenManager) ParseAndValidateToken(tokenString string) (*ParsedToken, error) {
 	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, jwt.ErrSignatureInvalid
 		}
-		return jwtSecret, nil
+		return t.secret, nil
 	})
 
 	// Parse and validate claim
 	if err != nil {
 		if err == jwt.ErrSignatureInvalid {
-			return nil, errors.New("Invalid token signature")
+			return nil, data.Unauthorized("invalid_token_signature", "Invalid token signature")
 		} else {
-			return nil, errors.New("Invalid token")
+			return nil, data.Unauthorized("invalid_token", "Invalid token")
 		}
 	}
 
@@ -67,25 +92,32 @@ func ParseAndValidateToken(tokenString string) (*ParsedToken, error) {
 	claims, ok := token.Claims.(jwt.MapClaims)
 
 	if !ok || !token.Valid {
-		return nil, errors.New("Invalid token claims")
+		return nil, data.Unauthorized("invalid_token_claims", "Invalid token claims")
 	}
 
 	// Checking token expiration
 	expiry_time, _ := claims["expires_at"].(float64)
 
 	if int64(expiry_time) < time.Now().Unix() {
-		return nil, errors.New("Token expired")
+		return nil, data.Unauthorized("token_expired", "Token expired")
 	}
 
-	user_id, err := strconv.Atoi(claims["user_id"].(string))
+	user_id_claim, _ := claims["user_id"].(string)
+	user_id, err := strconv.Atoi(user_id_claim)
 
 	if err != nil {
-		return nil, err
+		return nil, data.Unauthorized("invalid_token_claims", "Invalid token claims")
 	}
+	token_id, _ := claims["token_id"].(string)
+	email, _ := claims["email"].(string)
+	is_admin, _ := claims["is_admin"].(bool)
+
 	parsed_token := ParsedToken{
-		UserId:  user_id,
-		Email:   claims["email"].(string),
-		IsAdmin: claims["is_admin"].(bool),
+		UserId:    user_id,
+		Email:     email,
+		IsAdmin:   is_admin,
+		TokenId:   token_id,
+		ExpiresAt: time.Unix(int64(expiry_time), 0),
 	}
 	return &parsed_token, nil
 }
@@ -108,7 +140,7 @@ func CheckPasswordHash(password, hash string) bool {
 // Validate the password
 func ValidatePassword(password string) error {
 	if len(password) < 8 {
-		return errors.New("Password length can not be less than 8!")
+		return data.Invalid("weak_password", "Password length can not be less than 8!")
 	}
 	return nil
 }
@@ -0,0 +1,197 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/data"
+)
+
+const (
+	defaultPollInterval = 5 * time.Second
+	defaultMaxAttempts  = 8
+	deliveryTimeout     = 10 * time.Second
+	// Kept small, the lease of a batch covers sending all of it and holds the deliveries of an
+	// instance that died until it runs out.
+	claimBatchSize   = 10
+	retryBaseDelay   = 30 * time.Second
+	retryMaxDelay    = 6 * time.Hour
+	maxErrorBodySize = 512
+)
+
+type DeliveryStore interface {
+	ClaimWebhookDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*data.WebhookDelivery, error)
+	GetWebhookSubscription(ctx context.Context, id int) (*data.WebhookSubscription, error)
+	RecordWebhookAttempt(ctx context.Context, id int64, status string, response_status *int, last_error string, next_attempt_at *time.Time) error
+}
+
+// Sends the queued webhook deliveries. A failed delivery is retried with exponential backoff
+// and after the max attempts it is dead-lettered, from where it can be replayed.
+type Deliverer struct {
+	store       DeliveryStore
+	client      *http.Client
+	interval    time.Duration
+	maxAttempts int
+	cancel      context.CancelFunc
+	wg          sync.WaitGroup
+}
+
+func NewDeliverer(store DeliveryStore, max_attempts int) *Deliverer {
+	if max_attempts <= 0 {
+		max_attempts = defaultMaxAttempts
+	}
+
+	return &Deliverer{
+		store:       store,
+		client:      &http.Client{Timeout: deliveryTimeout},
+		interval:    defaultPollInterval,
+		maxAttempts: max_attempts,
+	}
+}
+
+func (d *Deliverer) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	d.cancel = cancel
+
+	d.wg.Add(1)
+	go d.loop(ctx)
+	slog.Info("Webhook deliverer started", "max_attempts", d.maxAttempts)
+}
+
+// Stop polling and wait for the deliveries being sent.
+func (d *Deliverer) Stop() {
+	if d.cancel != nil {
+		d.cancel()
+	}
+	d.wg.Wait()
+}
+
+func (d *Deliverer) loop(ctx context.Context) {
+	defer d.wg.Done()
+
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		for {
+			claimed, err := d.DeliverOnce(ctx)
+
+			if err != nil {
+				slog.Error("Webhook delivery failed", "error", err)
+			}
+
+			if err != nil || claimed < claimBatchSize || ctx.Err() != nil {
+				break
+			}
+		}
+	}
+}
+
+func retryDelay(attempts int) time.Duration {
+	delay := retryBaseDelay << (attempts - 1)
+
+	if delay > retryMaxDelay || delay <= 0 {
+		return retryMaxDelay
+	}
+	return delay
+}
+
+// Send a batch of due deliveries, returning how many were claimed.
+func (d *Deliverer) DeliverOnce(ctx context.Context) (int, error) {
+	// The deliveries are sent one after the other, so the lease has to last for the whole
+	// batch or another instance claims and posts the deliveries still to be sent.
+	lease := deliveryTimeout * time.Duration(claimBatchSize+1)
+
+	deliveries, err := d.store.ClaimWebhookDeliveries(ctx, time.Now(), lease, claimBatchSize)
+
+	if err != nil {
+		return 0, err
+	}
+
+	subscriptions := make(map[int]*data.WebhookSubscription)
+
+	// A delivery the store failed to read or update is sent again once its lease runs out,
+	// the rest of the batch is still sent.
+	store_errors := make([]error, 0)
+
+	for _, delivery := range deliveries {
+		subscription, ok := subscriptions[delivery.SubscriptionId]
+
+		if !ok {
+			subscription, err = d.store.GetWebhookSubscription(ctx, delivery.SubscriptionId)
+
+			if err != nil {
+				store_errors = append(store_errors, fmt.Errorf("delivery %d: %w", delivery.ID, err))
+				continue
+			}
+			subscriptions[delivery.SubscriptionId] = subscription
+		}
+
+		response_status, send_err := d.send(ctx, subscription, delivery)
+
+		if send_err == nil {
+			err = d.store.RecordWebhookAttempt(ctx, delivery.ID, data.WebhookDeliverySucceeded, response_status, "", nil)
+		} else if delivery.Attempts+1 >= d.maxAttempts {
+			slog.Warn("Webhook delivery dead", "delivery_id", delivery.ID, "url", subscription.URL, "attempts", delivery.Attempts+1, "error", send_err)
+			err = d.store.RecordWebhookAttempt(ctx, delivery.ID, data.WebhookDeliveryDead, response_status, send_err.Error(), nil)
+		} else {
+			next_attempt_at := time.Now().Add(retryDelay(delivery.Attempts + 1))
+			err = d.store.RecordWebhookAttempt(ctx, delivery.ID, data.WebhookDeliveryPending, response_status, send_err.Error(), &next_attempt_at)
+		}
+
+		if err != nil {
+			store_errors = append(store_errors, fmt.Errorf("delivery %d: %w", delivery.ID, err))
+		}
+	}
+	return len(deliveries), errors.Join(store_errors...)
+}
+
+// Post a delivery to the subscription URL, returning the response status when there was one.
+func (d *Deliverer) send(ctx context.Context, subscription *data.WebhookSubscription, delivery *data.WebhookDelivery) (*int, error) {
+	send_ctx, cancel := context.WithTimeout(ctx, deliveryTimeout)
+	defer cancel()
+
+	request, err := http.NewRequestWithContext(send_ctx, http.MethodPost, subscription.URL, bytes.NewReader(delivery.Payload))
+
+	if err != nil {
+		return nil, err
+	}
+
+	timestamp := time.Now().Unix()
+
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("User-Agent", "library-webhooks/1.0")
+	request.Header.Set(HeaderId, strconv.FormatInt(delivery.ID, 10))
+	request.Header.Set(HeaderEvent, delivery.EventType)
+	request.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	request.Header.Set(HeaderSignature, Sign(subscription.Secret, timestamp, delivery.Payload))
+
+	response, err := d.client.Do(request)
+
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	status := response.StatusCode
+
+	if status >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(response.Body, maxErrorBodySize))
+		return &status, fmt.Errorf("webhook responded with status %d: %s", status, bytes.TrimSpace(body))
+	}
+	return &status, nil
+}
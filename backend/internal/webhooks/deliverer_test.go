@@ -0,0 +1,176 @@
+package webhooks
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/data"
+)
+
+type attempt struct {
+	id             int64
+	status         string
+	responseStatus *int
+	lastError      string
+	nextAttemptAt  *time.Time
+}
+
+// A DeliveryStore handing out the deliveries given once and recording the attempts.
+type fakeDeliveryStore struct {
+	deliveries   []*data.WebhookDelivery
+	subscription *data.WebhookSubscription
+	attempts     []attempt
+}
+
+func (s *fakeDeliveryStore) ClaimWebhookDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*data.WebhookDelivery, error) {
+	claimed := s.deliveries
+	s.deliveries = nil
+	return claimed, nil
+}
+
+func (s *fakeDeliveryStore) GetWebhookSubscription(ctx context.Context, id int) (*data.WebhookSubscription, error) {
+	return s.subscription, nil
+}
+
+func (s *fakeDeliveryStore) RecordWebhookAttempt(ctx context.Context, id int64, status string, response_status *int, last_error string, next_attempt_at *time.Time) error {
+	s.attempts = append(s.attempts, attempt{id, status, response_status, last_error, next_attempt_at})
+	return nil
+}
+
+func TestRetryDelay(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{3, 2 * time.Minute},
+		{8, 64 * time.Minute},
+		{10, 256 * time.Minute},
+		{11, 6 * time.Hour},
+		{40, 6 * time.Hour},
+		{100, 6 * time.Hour},
+	}
+
+	for _, test := range tests {
+		t.Run(strconv.Itoa(test.attempts), func(t *testing.T) {
+			if got := retryDelay(test.attempts); got != test.want {
+				t.Errorf("retryDelay(%d) = %v, want %v", test.attempts, got, test.want)
+			}
+		})
+	}
+}
+
+func TestDeliverOnce(t *testing.T) {
+	tests := []struct {
+		name string
+		// Status the subscriber answers with.
+		responseStatus int
+		// Attempts the delivery already had.
+		attempts      int
+		wantStatus    string
+		wantError     bool
+		wantNextDelay time.Duration
+	}{
+		{"records a delivery", http.StatusNoContent, 0, data.WebhookDeliverySucceeded, false, 0},
+		{"retries a failed delivery", http.StatusInternalServerError, 0, data.WebhookDeliveryPending, true, 30 * time.Second},
+		{"backs off the later attempts", http.StatusBadGateway, 3, data.WebhookDeliveryPending, true, 4 * time.Minute},
+		{"dead-letters the last attempt", http.StatusInternalServerError, 7, data.WebhookDeliveryDead, true, 0},
+		{"records a last attempt that succeeds", http.StatusOK, 7, data.WebhookDeliverySucceeded, false, 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var request *http.Request
+			var body []byte
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				request = r
+				body, _ = io.ReadAll(r.Body)
+				w.WriteHeader(test.responseStatus)
+			}))
+			defer server.Close()
+
+			payload := []byte(`{"book_id":7}`)
+			store := &fakeDeliveryStore{
+				deliveries:   []*data.WebhookDelivery{{ID: 42, SubscriptionId: 3, EventType: "book.lent", Payload: payload, Attempts: test.attempts}},
+				subscription: &data.WebhookSubscription{ID: 3, URL: server.URL, Secret: "secret", Active: true},
+			}
+
+			deliverer := NewDeliverer(store, 8)
+			before := time.Now()
+
+			claimed, err := deliverer.DeliverOnce(context.Background())
+
+			if err != nil {
+				t.Fatalf("DeliverOnce() error = %v", err)
+			}
+
+			if claimed != 1 || len(store.attempts) != 1 {
+				t.Fatalf("DeliverOnce() claimed %d and recorded %d attempts, want 1 and 1", claimed, len(store.attempts))
+			}
+
+			recorded := store.attempts[0]
+
+			if recorded.id != 42 || recorded.status != test.wantStatus {
+				t.Errorf("recorded delivery %d as %q, want 42 as %q", recorded.id, recorded.status, test.wantStatus)
+			}
+
+			if recorded.responseStatus == nil || *recorded.responseStatus != test.responseStatus {
+				t.Errorf("recorded the response status %v, want %d", recorded.responseStatus, test.responseStatus)
+			}
+
+			if (recorded.lastError != "") != test.wantError {
+				t.Errorf("recorded the error %q, want an error %v", recorded.lastError, test.wantError)
+			}
+
+			if test.wantNextDelay == 0 {
+				if recorded.nextAttemptAt != nil {
+					t.Errorf("scheduled another attempt at %v, want none", recorded.nextAttemptAt)
+				}
+			} else if recorded.nextAttemptAt == nil || recorded.nextAttemptAt.Before(before.Add(test.wantNextDelay)) || recorded.nextAttemptAt.After(time.Now().Add(test.wantNextDelay)) {
+				t.Errorf("scheduled the next attempt at %v, want it %v from now", recorded.nextAttemptAt, test.wantNextDelay)
+			}
+
+			timestamp, err := strconv.ParseInt(request.Header.Get(HeaderTimestamp), 10, 64)
+
+			if err != nil {
+				t.Fatalf("invalid %s header: %v", HeaderTimestamp, err)
+			}
+
+			if !Verify("secret", timestamp, body, request.Header.Get(HeaderSignature), time.Minute) {
+				t.Errorf("the request is not signed with the subscription secret")
+			}
+
+			if request.Header.Get(HeaderId) != "42" || request.Header.Get(HeaderEvent) != "book.lent" {
+				t.Errorf("sent the headers %v, want the delivery id and event type", request.Header)
+			}
+		})
+	}
+}
+
+func TestDeliverOnceUnreachable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	// Nothing listens on the URL once the server is closed.
+	server.Close()
+
+	store := &fakeDeliveryStore{
+		deliveries:   []*data.WebhookDelivery{{ID: 1, SubscriptionId: 3, Payload: []byte(`{}`), Attempts: 1}},
+		subscription: &data.WebhookSubscription{ID: 3, URL: server.URL, Secret: "secret", Active: true},
+	}
+
+	_, err := NewDeliverer(store, 2).DeliverOnce(context.Background())
+
+	if err != nil {
+		t.Fatalf("DeliverOnce() error = %v", err)
+	}
+
+	if len(store.attempts) != 1 || store.attempts[0].status != data.WebhookDeliveryDead || store.attempts[0].responseStatus != nil {
+		t.Errorf("recorded %+v, want the delivery dead without a response status", store.attempts)
+	}
+}
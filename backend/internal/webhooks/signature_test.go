@@ -0,0 +1,61 @@
+package webhooks
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+	"time"
+)
+
+func TestSign(t *testing.T) {
+	body := []byte(`{"event":"book.lent"}`)
+
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write([]byte("1700000000." + string(body)))
+	want := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+
+	if got := Sign("secret", 1700000000, body); got != want {
+		t.Errorf("Sign() = %q, want the HMAC of the timestamp and body %q", got, want)
+	}
+
+	if Sign("secret", 1700000001, body) == want {
+		t.Error("Sign() does not depend on the timestamp")
+	}
+
+	if Sign("other secret", 1700000000, body) == want {
+		t.Error("Sign() does not depend on the secret")
+	}
+}
+
+func TestVerify(t *testing.T) {
+	body := []byte(`{"event":"book.lent"}`)
+	now := time.Now().Unix()
+
+	tests := []struct {
+		name      string
+		timestamp int64
+		body      []byte
+		signature string
+		tolerance time.Duration
+		want      bool
+	}{
+		{"accepts a fresh signature", now, body, Sign("secret", now, body), 5 * time.Minute, true},
+		{"accepts a timestamp inside the tolerance", now - 60, body, Sign("secret", now-60, body), 5 * time.Minute, true},
+		{"rejects an old timestamp", now - 600, body, Sign("secret", now-600, body), 5 * time.Minute, false},
+		{"rejects a timestamp in the future", now + 600, body, Sign("secret", now+600, body), 5 * time.Minute, false},
+		{"accepts any timestamp without a tolerance", now - 600, body, Sign("secret", now-600, body), 0, true},
+		{"rejects another body", now, []byte(`{"event":"book.returned"}`), Sign("secret", now, body), 5 * time.Minute, false},
+		{"rejects another secret", now, body, Sign("other secret", now, body), 5 * time.Minute, false},
+		{"rejects the signature of another timestamp", now, body, Sign("secret", now-1, body), 5 * time.Minute, false},
+		{"rejects a signature without the prefix", now, body, Sign("secret", now, body)[len("sha256="):], 5 * time.Minute, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := Verify("secret", test.timestamp, test.body, test.signature, test.tolerance); got != test.want {
+				t.Errorf("Verify() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
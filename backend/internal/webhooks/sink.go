@@ -0,0 +1,56 @@
+package webhooks
+
+import (
+	"context"
+
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/data"
+	"github.com/vinaycchndra/Libray_Managment_Go/backend/backend/internal/outbox"
+)
+
+type SubscriptionStore interface {
+	GetActiveWebhookSubscriptions(ctx context.Context) ([]*data.WebhookSubscription, error)
+	QueueWebhookDelivery(ctx context.Context, subscription_id int, event_id int64, event_type string, payload []byte) error
+}
+
+// Outbox sink queueing a delivery for every active subscription interested in the event.
+// The deliveries are sent by the Deliverer, so a slow partner does not hold up the outbox.
+type SubscriptionSink struct {
+	store SubscriptionStore
+}
+
+func NewSubscriptionSink(store SubscriptionStore) *SubscriptionSink {
+	return &SubscriptionSink{store: store}
+}
+
+func (s *SubscriptionSink) Name() string {
+	return "webhook-subscriptions"
+}
+
+func (s *SubscriptionSink) Publish(ctx context.Context, event *data.OutboxEvent) error {
+	subscriptions, err := s.store.GetActiveWebhookSubscriptions(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	body, err := outbox.MarshalEvent(event)
+
+	if err != nil {
+		return err
+	}
+
+	// Queueing is idempotent per subscription and event, so a republished event is not
+	// delivered twice.
+	for _, subscription := range subscriptions {
+		if !subscription.Matches(event.EventType) {
+			continue
+		}
+
+		err = s.store.QueueWebhookDelivery(ctx, subscription.ID, event.ID, event.EventType, body)
+
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}